@@ -0,0 +1,141 @@
+package battleye
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Admin represents an RCON session connected to the BattlEye server.
+type Admin struct {
+	// ID is the admin number assigned by the server.
+	ID int `json:"id"`
+
+	// IP is the address the session is connected from.
+	IP net.IP `json:"ip"`
+
+	// Port is the port the session is connected from.
+	Port int `json:"port"`
+
+	// Self is true if the session belongs to the Client which requested the list.
+	Self bool `json:"self"`
+}
+
+// Addr returns the address of the session in ip:port form.
+func (a Admin) Addr() string {
+	return net.JoinHostPort(a.IP.String(), strconv.Itoa(a.Port))
+}
+
+// Admins returns the RCON sessions currently connected to the BattlEye server.
+// The session belonging to c is marked as Self.
+func (c *Client) Admins() ([]Admin, error) {
+	resp, err := c.Exec("admins")
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := parseAdmins(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	c.markSelf(admins)
+	c.resetSessions(admins)
+
+	return admins, nil
+}
+
+// parseAdmins parses the response of the admins command.
+//
+// The response is in the form:
+//
+//	Connected RCon admins:
+//	[#] [IP Address]:[Port]
+//	-----------------------------
+//	0 127.0.0.1:2304
+func parseAdmins(resp string) ([]Admin, error) {
+	var admins []Admin
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || isTableHeader(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, ErrUnexpectedResponse
+		}
+
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, ErrUnexpectedResponse
+		}
+
+		ip, port, err := splitAddr(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		admins = append(admins, Admin{ID: id, IP: ip, Port: port})
+	}
+
+	return admins, nil
+}
+
+// markSelf marks the admin session belonging to c as Self.
+func (c *Client) markSelf(admins []Admin) {
+	if addr, ok := c.conn.LocalAddr().(*net.UDPAddr); ok {
+		markSelf(admins, addr)
+	}
+}
+
+// markSelf marks the admin session matching local as Self.
+// If no session matches local exactly, which is the case if the Client is behind NAT, the only
+// session with the same port is marked instead.
+func markSelf(admins []Admin, local *net.UDPAddr) {
+	match := -1
+	for i, a := range admins {
+		if a.Port != local.Port {
+			continue
+		}
+		if a.IP.Equal(local.IP) {
+			admins[i].Self = true
+			return
+		}
+		if match == -1 {
+			match = i
+		} else {
+			// More than one candidate, don't guess.
+			match = -2
+		}
+	}
+
+	if match >= 0 {
+		admins[match].Self = true
+	}
+}
+
+// isTableHeader returns true if line is part of the header the server prepends to list responses.
+func isTableHeader(line string) bool {
+	return strings.HasSuffix(line, ":") || strings.HasPrefix(line, "[#]") || strings.HasPrefix(line, "---")
+}
+
+// splitAddr splits an ip:port address as returned by the server.
+func splitAddr(addr string) (net.IP, int, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, ErrUnexpectedResponse
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, ErrUnexpectedResponse
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return nil, 0, ErrUnexpectedResponse
+	}
+
+	return ip, port, nil
+}
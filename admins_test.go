@@ -0,0 +1,130 @@
+package battleye
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdmins(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		resp   string
+		exp    []Admin
+		expErr error
+	}{
+		{
+			name: "No admins",
+			resp: "Connected RCon admins:\n[#] [IP Address]:[Port]\n-----------------------------\n",
+		},
+		{
+			name: "Multiple admins",
+			resp: "Connected RCon admins:\n[#] [IP Address]:[Port]\n-----------------------------\n0 127.0.0.1:2304\n1 10.0.0.2:51234",
+			exp: []Admin{
+				{ID: 0, IP: net.ParseIP("127.0.0.1"), Port: 2304},
+				{ID: 1, IP: net.ParseIP("10.0.0.2"), Port: 51234},
+			},
+		},
+		{
+			name:   "Invalid address",
+			resp:   "Connected RCon admins:\n0 127.0.0.1",
+			expErr: ErrUnexpectedResponse,
+		},
+		{
+			name:   "Invalid admin number",
+			resp:   "Connected RCon admins:\nx 127.0.0.1:2304",
+			expErr: ErrUnexpectedResponse,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			admins, err := parseAdmins(tc.resp)
+			if tc.expErr != nil {
+				assert.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, admins)
+		})
+	}
+}
+
+func TestMarkSelf(t *testing.T) {
+	t.Parallel()
+
+	local := &net.UDPAddr{IP: net.ParseIP("192.168.1.10"), Port: 51234}
+
+	testcases := []struct {
+		name    string
+		admins  []Admin
+		expSelf int
+	}{
+		{
+			name: "Exact match",
+			admins: []Admin{
+				{ID: 0, IP: net.ParseIP("1.2.3.4"), Port: 51234},
+				{ID: 1, IP: net.ParseIP("192.168.1.10"), Port: 51234},
+			},
+			expSelf: 1,
+		},
+		{
+			name: "Port match behind NAT",
+			admins: []Admin{
+				{ID: 0, IP: net.ParseIP("1.2.3.4"), Port: 2304},
+				{ID: 1, IP: net.ParseIP("5.6.7.8"), Port: 51234},
+			},
+			expSelf: 1,
+		},
+		{
+			name: "Ambiguous port match",
+			admins: []Admin{
+				{ID: 0, IP: net.ParseIP("1.2.3.4"), Port: 51234},
+				{ID: 1, IP: net.ParseIP("5.6.7.8"), Port: 51234},
+			},
+			expSelf: -1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			markSelf(tc.admins, local)
+			for i, a := range tc.admins {
+				assert.Equal(t, i == tc.expSelf, a.Self, fmt.Sprintf("admin #%v", a.ID))
+			}
+		})
+	}
+}
+
+func TestClientAdmins(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("admins", fmt.Sprintf("Connected RCon admins:\n[#] [IP Address]:[Port]\n-----------------------------\n0 10.0.0.2:2304\n1 %v", c.conn.LocalAddr()))
+
+	admins, err := c.Admins()
+	if !assert.NoError(t, err) || !assert.Len(t, admins, 2) {
+		return
+	}
+	assert.False(t, admins[0].Self)
+	assert.True(t, admins[1].Self)
+	assert.Equal(t, c.conn.LocalAddr().String(), admins[1].Addr())
+}
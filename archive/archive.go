@@ -0,0 +1,623 @@
+// Package archive archives the server messages of BattlEye RCON Clients to daily files, e.g. to
+// retain chat logs for moderation disputes.
+//
+// An Archive writes each message on a line of the file of the day it was received, named
+// <prefix>-<yyyy-mm-dd>.log, or .jsonl for JSON lines. Files of previous days can be compressed
+// with gzip and removed after a retention period:
+//
+//	a, err := archive.NewArchive("/var/log/battleye",
+//		archive.Prefix("prod1"),
+//		archive.LineFormat(archive.JSON),
+//		archive.Compress(true),
+//		archive.MaxAge(90*24*time.Hour),
+//	)
+//	if err != nil {
+//		// Handle error.
+//	}
+//	defer a.Close()
+//
+//	for {
+//		c, err := battleye.NewClient(addr, pwd)
+//		if err != nil {
+//			// Handle error and retry.
+//		}
+//		if err := a.Attach(c); err != nil {
+//			// Handle error.
+//		}
+//		watch(c) // Closes c when the connection is lost.
+//	}
+//
+// Attach registers a handler with the OnMessage of a Client, which waits for the handler instead
+// of dropping messages, so every message received by a Client is written with the time it was
+// received, including those received just before it's closed and replaced by a new one. Messages
+// the server sends while no Client is logged in are not archived. The Archive outlives the
+// Clients and keeps appending to the file of the day, also when the process is restarted.
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+)
+
+const (
+	// defaultPrefix is the default prefix of the file names.
+	defaultPrefix = "battleye"
+
+	// defaultSyncInterval is the default interval of SyncPeriodic.
+	defaultSyncInterval = time.Second
+
+	// dayLayout is the layout of the day in the file names.
+	dayLayout = "2006-01-02"
+
+	// plainTimeLayout is the layout of the time of Plain lines.
+	plainTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+	// gzipExt is the extension of compressed files.
+	gzipExt = ".gz"
+
+	// tempExt is the extension of files being compressed.
+	tempExt = ".tmp"
+)
+
+var (
+	// ErrNilOption is returned by NewArchive if an Option is nil.
+	ErrNilOption = errors.New("archive: nil option")
+
+	// ErrInvalidDir is returned by NewArchive if the directory is empty.
+	ErrInvalidDir = errors.New("archive: invalid directory")
+
+	// ErrInvalidPrefix is returned if Prefix Option is used with an empty prefix or one which
+	// contains a path separator.
+	ErrInvalidPrefix = errors.New("archive: invalid prefix")
+
+	// ErrInvalidFormat is returned if LineFormat Option is used with an unknown Format.
+	ErrInvalidFormat = errors.New("archive: invalid format")
+
+	// ErrInvalidSyncPolicy is returned if Sync Option is used with an unknown SyncPolicy or a
+	// non-positive interval.
+	ErrInvalidSyncPolicy = errors.New("archive: invalid sync policy")
+
+	// ErrInvalidRetention is returned if MaxAge or MaxFiles Option is used with a negative value.
+	ErrInvalidRetention = errors.New("archive: invalid retention")
+
+	// ErrNilLocation is returned if Location Option is used with a nil location.
+	ErrNilLocation = errors.New("archive: nil location")
+
+	// ErrClosed is returned if messages are written to a closed Archive.
+	ErrClosed = errors.New("archive: closed")
+)
+
+// Format is the format of the lines of the files.
+type Format int
+
+// Formats.
+const (
+	// Plain writes the time of the message in RFC 3339 format with milliseconds, a space and the
+	// message, in which new lines are escaped as \n.
+	Plain Format = iota
+
+	// JSON writes a Record as JSON.
+	JSON
+)
+
+// ext returns the extension of the files of f.
+func (f Format) ext() string {
+	if f == JSON {
+		return ".jsonl"
+	}
+	return ".log"
+}
+
+// SyncPolicy is when the messages written to the file of the day are flushed to disk with fsync.
+// Files are always flushed when they are closed.
+type SyncPolicy int
+
+// Sync policies.
+const (
+	// SyncPeriodic flushes the written messages at an interval, see Sync.
+	SyncPeriodic SyncPolicy = iota
+
+	// SyncAlways flushes each message before Write returns.
+	SyncAlways
+
+	// SyncNever leaves flushing the messages to the operating system.
+	SyncNever
+)
+
+// Record is a line of a JSON file.
+type Record struct {
+	// Time is the time the message was received.
+	Time time.Time `json:"time"`
+
+	// Server is the name of the server, see Server.
+	Server string `json:"server,omitempty"`
+
+	// Type is the type of the message as returned by event.TypeOf, e.g. chat.
+	Type string `json:"type"`
+
+	// Message is the server message.
+	Message string `json:"message"`
+}
+
+// Archive writes server messages to daily files.
+type Archive struct {
+	dir          string
+	prefix       string
+	format       Format
+	compress     bool
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+	maxAge       time.Duration
+	maxFiles     int
+	loc          *time.Location
+	server       string
+	onError      func(err error)
+
+	mu     sync.Mutex
+	f      *os.File
+	day    string
+	dirty  bool
+	closed bool
+
+	// pruneMu serialises compressing and removing files.
+	pruneMu sync.Mutex
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Option is an Archive configuration Option type.
+type Option func(a *Archive) error
+
+// Prefix sets the prefix of the file names, by default battleye.
+func Prefix(prefix string) Option {
+	return func(a *Archive) error {
+		if prefix == "" || strings.ContainsAny(prefix, `/\`) {
+			return ErrInvalidPrefix
+		}
+		a.prefix = prefix
+		return nil
+	}
+}
+
+// LineFormat sets the format of the lines, by default Plain.
+func LineFormat(f Format) Option {
+	return func(a *Archive) error {
+		if f != Plain && f != JSON {
+			return ErrInvalidFormat
+		}
+		a.format = f
+		return nil
+	}
+}
+
+// Compress sets whether the files of previous days are compressed with gzip.
+func Compress(compress bool) Option {
+	return func(a *Archive) error {
+		a.compress = compress
+		return nil
+	}
+}
+
+// Sync sets the policy of flushing the messages to disk, by default SyncPeriodic every second.
+// interval is the interval of SyncPeriodic and is ignored by the other policies.
+func Sync(policy SyncPolicy, interval time.Duration) Option {
+	return func(a *Archive) error {
+		switch {
+		case policy < SyncPeriodic || policy > SyncNever:
+			return ErrInvalidSyncPolicy
+		case policy == SyncPeriodic && interval <= 0:
+			return ErrInvalidSyncPolicy
+		}
+		a.syncPolicy, a.syncInterval = policy, interval
+		return nil
+	}
+}
+
+// MaxAge sets the age after which the files of previous days are removed, by the end of their day.
+// 0, the default, keeps files forever.
+func MaxAge(age time.Duration) Option {
+	return func(a *Archive) error {
+		if age < 0 {
+			return ErrInvalidRetention
+		}
+		a.maxAge = age
+		return nil
+	}
+}
+
+// MaxFiles sets the maximum number of files kept, including the file of the day, after which the
+// oldest are removed. 0, the default, keeps every file.
+func MaxFiles(n int) Option {
+	return func(a *Archive) error {
+		if n < 0 {
+			return ErrInvalidRetention
+		}
+		a.maxFiles = n
+		return nil
+	}
+}
+
+// Location sets the time zone of the days the files are rotated at, by default UTC.
+func Location(loc *time.Location) Option {
+	return func(a *Archive) error {
+		if loc == nil {
+			return ErrNilLocation
+		}
+		a.loc = loc
+		return nil
+	}
+}
+
+// Server sets the server name of the JSON Records.
+func Server(name string) Option {
+	return func(a *Archive) error {
+		a.server = name
+		return nil
+	}
+}
+
+// ErrorHandler sets the function called with the errors which can't be returned, e.g. of
+// compressing files or of writing the messages of an attached Client.
+func ErrorHandler(f func(err error)) Option {
+	return func(a *Archive) error {
+		a.onError = f
+		return nil
+	}
+}
+
+// NewArchive returns a new Archive writing to files in dir, which is created if needed. Files of
+// previous days left uncompressed are compressed and expired files are removed.
+func NewArchive(dir string, options ...Option) (*Archive, error) {
+	if dir == "" {
+		return nil, ErrInvalidDir
+	}
+	a := &Archive{
+		dir:          dir,
+		prefix:       defaultPrefix,
+		syncInterval: defaultSyncInterval,
+		loc:          time.UTC,
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	a.day = time.Now().In(a.loc).Format(dayLayout)
+	if err := a.prune(); err != nil {
+		return nil, err
+	}
+
+	if a.syncPolicy == SyncPeriodic {
+		a.wg.Add(1)
+		go a.syncer()
+	}
+
+	return a, nil
+}
+
+// Close flushes and closes the file of the day and waits for the files being compressed.
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	err := a.closeFile()
+	a.mu.Unlock()
+
+	close(a.done)
+	a.wg.Wait()
+	return err
+}
+
+// Attach writes the messages of src, with the time they were received, until src or a is closed.
+// It returns ErrClosed if a is closed. Errors writing messages are reported to the ErrorHandler.
+func (a *Archive) Attach(src battleye.Source) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	id := src.OnMessage(a.receive)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		select {
+		case <-src.Done():
+			// The handler stays registered, so that the messages src handles while closing
+			// are written.
+		case <-a.done:
+			src.Unregister(id)
+		}
+	}()
+	return nil
+}
+
+// receive writes m, reporting errors to the ErrorHandler.
+func (a *Archive) receive(m battleye.Message) {
+	if err := a.write(m.Time, m.Event); err != nil && err != ErrClosed {
+		a.error(err)
+	}
+}
+
+// Write writes msg, received at t, to the file of the day of t. Messages received before the day
+// of the current file are written to it.
+func (a *Archive) Write(t time.Time, msg string) error {
+	return a.write(t, event.Parse(msg))
+}
+
+// write writes the message of e, received at t, to the file of the day of t.
+func (a *Archive) write(t time.Time, e event.Event) error {
+	t = t.In(a.loc)
+	line, err := a.line(t, e)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	day := t.Format(dayLayout)
+	if day < a.day {
+		day = a.day
+	}
+	if a.f == nil || day != a.day {
+		if err := a.rotate(day); err != nil {
+			return err
+		}
+	}
+	if _, err := a.f.Write(line); err != nil {
+		return err
+	}
+	if a.syncPolicy == SyncAlways {
+		return a.f.Sync()
+	}
+	a.dirty = true
+	return nil
+}
+
+// line returns the line of the message of e received at t.
+func (a *Archive) line(t time.Time, e event.Event) ([]byte, error) {
+	msg := e.Raw()
+	if a.format == JSON {
+		b, err := json.Marshal(Record{Time: t, Server: a.server, Type: event.TypeOf(e), Message: msg})
+		return append(b, '\n'), err
+	}
+	return []byte(t.Format(plainTimeLayout) + " " + strings.ReplaceAll(msg, "\n", `\n`) + "\n"), nil
+}
+
+// rotate closes the current file, if any, and opens the file of day for appending.
+func (a *Archive) rotate(day string) error {
+	prev := a.day
+	if a.f != nil {
+		if err := a.closeFile(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(a.path(day), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	a.f, a.day = f, day
+
+	if day != prev {
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			if err := a.prune(); err != nil {
+				a.error(err)
+			}
+		}()
+	}
+	return nil
+}
+
+// closeFile flushes and closes the current file.
+func (a *Archive) closeFile() error {
+	if a.f == nil {
+		return nil
+	}
+	err := a.f.Sync()
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	a.f, a.dirty = nil, false
+	return err
+}
+
+// path returns the path of the file of day.
+func (a *Archive) path(day string) string {
+	return filepath.Join(a.dir, a.prefix+"-"+day+a.format.ext())
+}
+
+// syncer is a goroutine which periodically flushes the written messages.
+func (a *Archive) syncer() {
+	defer a.wg.Done()
+
+	t := time.NewTicker(a.syncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-t.C:
+			a.mu.Lock()
+			if a.dirty && a.f != nil {
+				if err := a.f.Sync(); err != nil {
+					a.error(err)
+				}
+				a.dirty = false
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// error reports err to the ErrorHandler.
+func (a *Archive) error(err error) {
+	if a.onError != nil {
+		a.onError(err)
+	}
+}
+
+// file is a file of the archive.
+type file struct {
+	name string
+	day  string
+}
+
+// files returns the files of the archive, including those being compressed, sorted by day.
+func (a *Archive) files() ([]file, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []file
+	for _, e := range entries {
+		name := e.Name()
+		rest := strings.TrimPrefix(name, a.prefix+"-")
+		if rest == name || len(rest) < len(dayLayout) {
+			continue
+		}
+		day := rest[:len(dayLayout)]
+		if _, err := time.Parse(dayLayout, day); err != nil {
+			continue
+		}
+		switch strings.TrimSuffix(strings.TrimSuffix(rest[len(dayLayout):], tempExt), gzipExt) {
+		case Plain.ext(), JSON.ext():
+			files = append(files, file{name: name, day: day})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].day < files[j].day })
+	return files, nil
+}
+
+// prune compresses the files of previous days if Compress is set and removes the expired files.
+func (a *Archive) prune() error {
+	a.pruneMu.Lock()
+	defer a.pruneMu.Unlock()
+
+	a.mu.Lock()
+	current := a.day
+	a.mu.Unlock()
+
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+
+	// The file of the day counts towards MaxFiles even if nothing was written to it yet.
+	days := map[string]bool{current: true}
+	var live []file
+	for _, f := range files {
+		path := filepath.Join(a.dir, f.name)
+		switch {
+		case strings.HasSuffix(f.name, tempExt):
+			// Left over by an interrupted compression, which is redone.
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		case f.day < current && a.compress && !strings.HasSuffix(f.name, gzipExt):
+			if err := compressFile(path); err != nil {
+				return err
+			}
+			f.name += gzipExt
+		}
+		days[f.day] = true
+		live = append(live, f)
+	}
+
+	expired := ""
+	if a.maxAge > 0 {
+		// A day expires when its end is older than maxAge.
+		expired = time.Now().In(a.loc).Add(-a.maxAge).AddDate(0, 0, -1).Format(dayLayout)
+	}
+	var ordered []string
+	for day := range days {
+		ordered = append(ordered, day)
+	}
+	sort.Strings(ordered)
+	oldest := ""
+	if a.maxFiles > 0 && len(ordered) > a.maxFiles {
+		oldest = ordered[len(ordered)-a.maxFiles]
+	}
+
+	for _, f := range live {
+		if f.day >= current || (f.day > expired && f.day >= oldest) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(a.dir, f.name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile compresses the file at path to path.gz and removes it. If path.gz already exists
+// compressing it was completed, so only path is removed.
+func compressFile(path string) error {
+	dst := path + gzipExt
+	if _, err := os.Stat(dst); err == nil {
+		return os.Remove(path)
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close() // nolint: errcheck
+
+	tmp := dst + tempExt
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	zw.Name = filepath.Base(path)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp) // nolint: errcheck
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,303 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSource is a battleye.Source whose messages are sent by the test.
+type fakeSource struct {
+	done chan struct{}
+
+	mu       sync.Mutex
+	handlers map[battleye.HandlerID]func(battleye.Message)
+	nextID   battleye.HandlerID
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{done: make(chan struct{}), handlers: make(map[battleye.HandlerID]func(battleye.Message))}
+}
+
+func (s *fakeSource) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handlers[s.nextID] = f
+	return s.nextID
+}
+
+func (s *fakeSource) Unregister(id battleye.HandlerID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.handlers[id]
+	delete(s.handlers, id)
+	return ok
+}
+
+func (s *fakeSource) Done() <-chan struct{} {
+	return s.done
+}
+
+// registered returns the number of handlers registered with s.
+func (s *fakeSource) registered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.handlers)
+}
+
+// send calls the registered handlers with msg, received at t.
+func (s *fakeSource) send(t time.Time, msg string) {
+	s.mu.Lock()
+	handlers := make([]func(battleye.Message), 0, len(s.handlers))
+	for _, f := range s.handlers {
+		handlers = append(handlers, f)
+	}
+	s.mu.Unlock()
+
+	m := battleye.Message{Time: t, Event: event.Parse(msg)}
+	for _, f := range handlers {
+		f(m)
+	}
+}
+
+// day returns the day n days after today in UTC.
+func day(n int) string {
+	return time.Now().UTC().AddDate(0, 0, n).Format(dayLayout)
+}
+
+// readFile returns the content of the file name in dir, decompressed if it's gzipped.
+func readFile(t *testing.T, dir, name string) string {
+	f, err := os.Open(filepath.Join(dir, name))
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer f.Close() // nolint: errcheck
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, gzipExt) {
+		zr, err := gzip.NewReader(f)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		r = zr
+	}
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+// listFiles returns the names of the files in dir.
+func listFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// messages returns the messages of the Plain lines in s.
+func messages(s string) []string {
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if i := strings.IndexByte(line, ' '); i != -1 {
+			msgs = append(msgs, line[i+1:])
+		}
+	}
+	return msgs
+}
+
+func TestNewArchive(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		dir    string
+		opts   []Option
+		expErr error
+	}{
+		{name: "Empty dir", expErr: ErrInvalidDir},
+		{name: "Nil option", dir: t.TempDir(), opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Empty prefix", dir: t.TempDir(), opts: []Option{Prefix("")}, expErr: ErrInvalidPrefix},
+		{name: "Path prefix", dir: t.TempDir(), opts: []Option{Prefix("a/b")}, expErr: ErrInvalidPrefix},
+		{name: "Invalid format", dir: t.TempDir(), opts: []Option{LineFormat(Format(5))}, expErr: ErrInvalidFormat},
+		{name: "Invalid sync policy", dir: t.TempDir(), opts: []Option{Sync(SyncPolicy(5), 0)}, expErr: ErrInvalidSyncPolicy},
+		{name: "Invalid sync interval", dir: t.TempDir(), opts: []Option{Sync(SyncPeriodic, 0)}, expErr: ErrInvalidSyncPolicy},
+		{name: "Negative max age", dir: t.TempDir(), opts: []Option{MaxAge(-time.Hour)}, expErr: ErrInvalidRetention},
+		{name: "Negative max files", dir: t.TempDir(), opts: []Option{MaxFiles(-1)}, expErr: ErrInvalidRetention},
+		{name: "Nil location", dir: t.TempDir(), opts: []Option{Location(nil)}, expErr: ErrNilLocation},
+		{name: "Valid", dir: filepath.Join(t.TempDir(), "logs"), opts: []Option{
+			Prefix("prod1"),
+			LineFormat(JSON),
+			Compress(true),
+			Sync(SyncPeriodic, 10*time.Millisecond),
+			MaxAge(time.Hour),
+			MaxFiles(3),
+			Location(time.Local),
+			Server("prod1"),
+			ErrorHandler(func(error) {}),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := NewArchive(tc.dir, tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.NoError(t, a.Write(time.Now(), "(Global) Kerry: hello"))
+				assert.NoError(t, a.Close())
+				assert.NoError(t, a.Close())
+				assert.Equal(t, ErrClosed, a.Write(time.Now(), "(Global) Kerry: hello"))
+			}
+		})
+	}
+}
+
+func TestArchiveRotation(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, Compress(true), Sync(SyncNever, 0))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now := time.Now()
+	assert.NoError(t, a.Write(now, "(Global) Kerry: today"))
+	assert.NoError(t, a.Write(now, "(Global) Kerry: multi\nline"))
+	assert.NoError(t, a.Write(now.AddDate(0, 0, 1), "(Global) Kerry: tomorrow"))
+	assert.NoError(t, a.Write(now, "(Global) Kerry: late"))
+	assert.NoError(t, a.Close())
+
+	today, tomorrow := "battleye-"+day(0)+".log.gz", "battleye-"+day(1)+".log"
+	assert.Equal(t, []string{today, tomorrow}, listFiles(t, dir))
+	assert.Equal(t, []string{"(Global) Kerry: today", `(Global) Kerry: multi\nline`}, messages(readFile(t, dir, today)))
+	assert.Equal(t, []string{"(Global) Kerry: tomorrow", "(Global) Kerry: late"}, messages(readFile(t, dir, tomorrow)))
+	assert.True(t, strings.HasPrefix(readFile(t, dir, tomorrow), now.AddDate(0, 0, 1).UTC().Format(plainTimeLayout)))
+}
+
+func TestArchiveJSON(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, Prefix("prod1"), LineFormat(JSON), Server("prod1"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Now().UTC()
+	assert.NoError(t, a.Write(now, "Player #3 Kerry disconnected"))
+	assert.NoError(t, a.Close())
+
+	var r Record
+	assert.NoError(t, json.Unmarshal([]byte(readFile(t, dir, "prod1-"+day(0)+".jsonl")), &r))
+	assert.Equal(t, Record{Time: now, Server: "prod1", Type: "player_disconnected", Message: "Player #3 Kerry disconnected"}, r)
+}
+
+func TestArchiveRetention(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"battleye-" + day(-10) + ".log":         "old\n",
+		"battleye-" + day(-3) + ".log":          "recent\n",
+		"battleye-" + day(-1) + ".jsonl":        "yesterday\n",
+		"battleye-" + day(-1) + ".jsonl.gz.tmp": "partial",
+		"other-" + day(-10) + ".log":            "other prefix\n",
+		"notes.txt":                             "unrelated\n",
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	a, err := NewArchive(dir, Compress(true), MaxAge(5*24*time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{
+		"battleye-" + day(-3) + ".log.gz",
+		"battleye-" + day(-1) + ".jsonl.gz",
+		"notes.txt",
+		"other-" + day(-10) + ".log",
+	}, listFiles(t, dir))
+	assert.Equal(t, "yesterday\n", readFile(t, dir, "battleye-"+day(-1)+".jsonl.gz"))
+
+	// The file of the day counts towards MaxFiles.
+	a, err = NewArchive(dir, MaxFiles(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{
+		"battleye-" + day(-1) + ".jsonl.gz",
+		"notes.txt",
+		"other-" + day(-10) + ".log",
+	}, listFiles(t, dir))
+}
+
+func TestArchiveAttach(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, Sync(SyncAlways, 0), LineFormat(JSON), Server("prod1"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The messages of a Client which is closed are written before those of the Client replacing
+	// it, with the time they were received.
+	received := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+	for _, client := range [][]string{
+		{"(Global) Kerry: one", "(Global) Kerry: two"},
+		{"(Global) Kerry: three"},
+	} {
+		src := newFakeSource()
+		assert.NoError(t, a.Attach(src))
+		for _, msg := range client {
+			src.send(received, msg)
+		}
+		close(src.done)
+	}
+
+	// A closed Archive detaches from its sources.
+	src := newFakeSource()
+	assert.NoError(t, a.Attach(src))
+	src.send(received, "(Global) Kerry: four")
+	assert.NoError(t, a.Close())
+	assert.Zero(t, src.registered())
+	assert.Equal(t, ErrClosed, a.Attach(src))
+
+	// A restarted Archive appends to the file of the day.
+	a, err = NewArchive(dir, LineFormat(JSON), Server("prod1"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	src = newFakeSource()
+	assert.NoError(t, a.Attach(src))
+	src.send(received, "(Global) Kerry: five")
+	assert.NoError(t, a.Close())
+
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(readFile(t, dir, "battleye-"+day(0)+".jsonl")), "\n") {
+		var r Record
+		if assert.NoError(t, json.Unmarshal([]byte(line), &r)) {
+			records = append(records, r)
+		}
+	}
+	if assert.Len(t, records, 5) {
+		for i, msg := range []string{"one", "two", "three", "four", "five"} {
+			assert.Equal(t, "(Global) Kerry: "+msg, records[i].Message)
+			assert.Equal(t, "chat", records[i].Type)
+			assert.Equal(t, "prod1", records[i].Server)
+			assert.True(t, received.Equal(records[i].Time), records[i].Time)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package battleye
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PermanentBan is the MinutesLeft of bans which never expire.
+	PermanentBan = -1
+)
+
+var (
+	// banRegexp matches a line of the bans command response.
+	banRegexp = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(perm|-|\d+)\s*(.*)$`)
+
+	// guidRegexp matches a BattlEye GUID.
+	guidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// Ban represents an entry of the ban list of the BattlEye server.
+type Ban struct {
+	// ID is the ban number assigned by the server, used to remove the ban.
+	ID int `json:"id"`
+
+	// GUID is the banned BattlEye GUID or empty if the ban is by IP.
+	GUID string `json:"guid,omitempty"`
+
+	// IP is the banned address or nil if the ban is by GUID.
+	IP net.IP `json:"ip,omitempty"`
+
+	// MinutesLeft is the remaining duration of the ban, PermanentBan if it never expires or 0
+	// if it has expired.
+	MinutesLeft int `json:"minutes_left"`
+
+	// Reason is the reason given when the ban was added.
+	Reason string `json:"reason"`
+}
+
+// Permanent returns true if the ban never expires.
+func (b Ban) Permanent() bool {
+	return b.MinutesLeft == PermanentBan
+}
+
+// Bans returns the ban list of the BattlEye server.
+func (c *Client) Bans() ([]Ban, error) {
+	return c.BansContext(context.Background())
+}
+
+// BansContext returns the ban list of the BattlEye server, executing the command the same way as
+// ExecContext.
+func (c *Client) BansContext(ctx context.Context) ([]Ban, error) {
+	resp, err := c.ExecContext(ctx, "bans")
+	if err != nil {
+		return nil, err
+	}
+	return parseBans(resp)
+}
+
+// Ban bans the player identified by playerID for minutes, or forever if minutes is PermanentBan,
+// showing reason to the player, who is kicked from the server.
+// Control characters are removed from reason.
+func (c *Client) Ban(playerID, minutes int, reason string) error {
+	return c.BanContext(context.Background(), playerID, minutes, reason)
+}
+
+// BanContext bans the player identified by playerID the same way as Ban, executing the commands
+// the same way as ExecContext.
+func (c *Client) BanContext(ctx context.Context, playerID, minutes int, reason string) error {
+	if playerID < 0 {
+		return ErrInvalidPlayerID
+	}
+
+	cmd, err := banCommand("ban", strconv.Itoa(playerID), minutes, reason)
+	if err != nil {
+		return err
+	}
+
+	found, err := c.hasPlayer(ctx, playerID)
+	if err != nil {
+		return err
+	} else if !found {
+		return ErrPlayerNotFound
+	}
+
+	_, err = c.ExecCommandContext(ctx, cmd)
+	return err
+}
+
+// AddBan bans the BattlEye GUID guid for minutes, or forever if minutes is PermanentBan, whether
+// or not a player with this GUID is connected to the server.
+// Control characters are removed from reason.
+func (c *Client) AddBan(guid string, minutes int, reason string) error {
+	return c.AddBanContext(context.Background(), guid, minutes, reason)
+}
+
+// AddBanContext bans the BattlEye GUID guid the same way as AddBan, executing the command the
+// same way as ExecContext.
+func (c *Client) AddBanContext(ctx context.Context, guid string, minutes int, reason string) error {
+	if !guidRegexp.MatchString(guid) {
+		return ErrInvalidGUID
+	}
+
+	cmd, err := banCommand("addBan", guid, minutes, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ExecCommandContext(ctx, cmd)
+	return err
+}
+
+// banCommand returns the ban command verb of target for minutes with reason.
+// The server bans forever if the duration is 0.
+func banCommand(verb, target string, minutes int, reason string) (*Command, error) {
+	switch {
+	case minutes == PermanentBan:
+		minutes = 0
+	case minutes <= 0:
+		return nil, ErrInvalidBanDuration
+	}
+
+	reason = sanitize(reason)
+	if len(reason) > maxMessageLength {
+		return nil, ErrMessageTooLong
+	}
+
+	return NewCommand(verb).Word(target).Int(minutes).Text(reason), nil
+}
+
+// parseBans parses the response of the bans command.
+//
+// The response is in the form:
+//
+//	GUID Bans:
+//	[#] [GUID] [Minutes left] [Reason]
+//	----------------------------------------
+//	0  d41d8cd98f00b204e9800998ecf8427e perm Cheating
+//
+//	IP Bans:
+//	[#] [IP Address] [Minutes left] [Reason]
+//	----------------------------------------------
+//	1  192.168.1.2     1437 Spamming
+func parseBans(resp string) ([]Ban, error) {
+	var bans []Ban
+	byIP := false
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "GUID Bans:"):
+			byIP = false
+			continue
+		case strings.HasPrefix(line, "IP Bans:"):
+			byIP = true
+			continue
+		case line == "" || isTableHeader(line):
+			continue
+		}
+
+		m := banRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil, ErrUnexpectedResponse
+		}
+
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, ErrUnexpectedResponse
+		}
+
+		b := Ban{ID: id, Reason: m[4]}
+		if byIP {
+			if b.IP = net.ParseIP(m[2]); b.IP == nil {
+				return nil, ErrUnexpectedResponse
+			}
+		} else {
+			b.GUID = m[2]
+		}
+
+		switch m[3] {
+		case "perm":
+			b.MinutesLeft = PermanentBan
+		case "-":
+			b.MinutesLeft = 0
+		default:
+			if b.MinutesLeft, err = strconv.Atoi(m[3]); err != nil {
+				return nil, ErrUnexpectedResponse
+			}
+		}
+
+		bans = append(bans, b)
+	}
+
+	return bans, nil
+}
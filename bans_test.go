@@ -0,0 +1,135 @@
+package battleye
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBansResponse = `GUID Bans:
+[#] [GUID] [Minutes left] [Reason]
+----------------------------------------
+0  d41d8cd98f00b204e9800998ecf8427e perm Cheating
+1  0cc175b9c0f1b6a831c399e269772661 1437 Teamkilling (3rd warning)
+
+IP Bans:
+[#] [IP Address] [Minutes left] [Reason]
+----------------------------------------------
+2  192.168.1.2     -
+3  10.0.0.3        perm Spamming`
+
+func TestParseBans(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		resp   string
+		exp    []Ban
+		expErr error
+	}{
+		{
+			name: "No bans",
+			resp: "GUID Bans:\n[#] [GUID] [Minutes left] [Reason]\n----------------------------------------\n\nIP Bans:\n[#] [IP Address] [Minutes left] [Reason]\n----------------------------------------------",
+		},
+		{
+			name: "GUID and IP bans",
+			resp: testBansResponse,
+			exp: []Ban{
+				{ID: 0, GUID: "d41d8cd98f00b204e9800998ecf8427e", MinutesLeft: PermanentBan, Reason: "Cheating"},
+				{ID: 1, GUID: "0cc175b9c0f1b6a831c399e269772661", MinutesLeft: 1437, Reason: "Teamkilling (3rd warning)"},
+				{ID: 2, IP: net.ParseIP("192.168.1.2")},
+				{ID: 3, IP: net.ParseIP("10.0.0.3"), MinutesLeft: PermanentBan, Reason: "Spamming"},
+			},
+		},
+		{
+			name:   "Invalid line",
+			resp:   "GUID Bans:\n0 garbage",
+			expErr: ErrUnexpectedResponse,
+		},
+		{
+			name:   "Invalid IP",
+			resp:   "IP Bans:\n0 not-an-ip perm Reason",
+			expErr: ErrUnexpectedResponse,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			bans, err := parseBans(tc.resp)
+			if tc.expErr != nil {
+				assert.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, bans)
+		})
+	}
+}
+
+func TestClientBans(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("bans", testBansResponse)
+
+	bans, err := c.Bans()
+	if !assert.NoError(t, err) || !assert.Len(t, bans, 4) {
+		return
+	}
+	assert.True(t, bans[0].Permanent())
+	assert.False(t, bans[1].Permanent())
+	assert.Equal(t, "10.0.0.3", bans[3].IP.String())
+}
+
+func TestClientBan(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("players", testPlayersResponse)
+	const guid = "d41d8cd98f00b204e9800998ecf8427e"
+
+	assert.Equal(t, ErrInvalidPlayerID, c.Ban(-1, 10, ""))
+	assert.Equal(t, ErrInvalidBanDuration, c.Ban(0, 0, ""))
+	assert.Equal(t, ErrInvalidBanDuration, c.Ban(0, -2, ""))
+	assert.Equal(t, ErrMessageTooLong, c.Ban(0, 10, strings.Repeat("x", maxMessageLength+1)))
+	assert.Equal(t, ErrPlayerNotFound, c.Ban(5, 10, ""))
+	assert.NoError(t, c.Ban(0, 60, "Team\nkilling"))
+	assert.NoError(t, c.Ban(1, PermanentBan, ""))
+
+	assert.Equal(t, ErrInvalidGUID, c.AddBan("d41d8cd9", 10, ""))
+	assert.Equal(t, ErrInvalidGUID, c.AddBan(guid+" 0", 10, ""))
+	assert.Equal(t, ErrInvalidBanDuration, c.AddBan(guid, 0, ""))
+	assert.NoError(t, c.AddBan(guid, PermanentBan, "Cheating"))
+
+	assert.Contains(t, s.Commands(), "ban 0 60 Team killing")
+	assert.Contains(t, s.Commands(), "ban 1 0")
+	assert.Contains(t, s.Commands(), "addBan "+guid+" 0 Cheating")
+}
@@ -1,10 +1,13 @@
 package battleye
 
 import (
+	"context"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/multiplay/go-battleye/event"
 )
 
 const (
@@ -33,6 +36,7 @@ var (
 
 // Client represents a BattlEye client.
 type Client struct {
+	addr       string
 	conn       net.Conn
 	ctr        uint64
 	timeout    time.Duration
@@ -40,10 +44,39 @@ type Client struct {
 	msgBufSize int
 	wg         sync.WaitGroup
 	fragments  map[byte]*fragmentedResponse
-	sendLock   sync.Mutex
 	lastLock   sync.Mutex
 	lastSend   time.Time
 
+	// sendLock serialises commands. It is a channel so that waiting for it can be cancelled.
+	sendLock chan struct{}
+
+	// sessions are the RCON sessions known to be connected to the server, by admin number.
+	sessions     map[int]Admin
+	sessionsLock sync.Mutex
+
+	// trustedNets are the networks RCON sessions are expected to connect from.
+	trustedNets []*net.IPNet
+
+	// onUntrustedAdmin is called when an RCON session connects from outside trustedNets.
+	onUntrustedAdmin func(Admin)
+
+	// parser parses server messages into events, using the player names of roster if set.
+	parser *event.Parser
+
+	// roster is the Roster of the Client, which attributes chat messages to players.
+	roster     *Roster
+	rosterLock sync.Mutex
+
+	// dispatchers receive every server message parsed into an event.
+	dispatchers     []*Dispatcher
+	dispatchersLock sync.Mutex
+
+	// source is the blocking Dispatcher through which the Client implements Source.
+	source *Dispatcher
+
+	// hooks are called on the activity of the Client.
+	hooks []*Hooks
+
 	// done signals goroutines to stop.
 	done *done
 
@@ -51,7 +84,7 @@ type Client struct {
 	login chan bool
 
 	// cmds is used for receiving command-type responses from the BattlEye server.
-	cmds chan string
+	cmds chan reply
 
 	// msgs is a buffered channel which is used for getting broadcast messages from the BattlEye server.
 	msgs chan string
@@ -79,14 +112,24 @@ func NewClient(addr string, pwd string, options ...Option) (*Client, error) {
 	}
 
 	c.done = newDone()
+	c.sendLock = make(chan struct{}, 1)
 	c.login = make(chan bool)
-	c.cmds = make(chan string)
+	c.cmds = make(chan reply)
 	c.msgs = make(chan string, c.msgBufSize)
 	c.errs = make(chan error)
 
 	c.fragments = make(map[byte]*fragmentedResponse)
+	c.sessions = make(map[int]Admin)
+	c.parser = &event.Parser{Names: c.playerNames}
+
+	var err error
+	if c.source, err = NewDispatcher(c, DispatchBuffer(c.msgBufSize), Blocking()); err != nil {
+		return nil, err
+	}
 
-	if err := c.connect(addr, pwd); err != nil {
+	err = c.connect(addr, pwd)
+	c.loginDone(err)
+	if err != nil {
 		c.Close() // nolint: errcheck
 		return nil, err
 	}
@@ -102,6 +145,16 @@ func (c *Client) Close() error {
 	c.done.Done()
 	c.wg.Wait()
 	close(c.msgs)
+
+	c.dispatchersLock.Lock()
+	for _, d := range c.dispatchers {
+		d.stop()
+	}
+	c.dispatchers = nil
+	c.dispatchersLock.Unlock()
+
+	c.closed()
+
 	return c.conn.Close()
 }
 
@@ -112,18 +165,62 @@ func (c *Client) Messages() <-chan string {
 	return c.msgs
 }
 
+// OnMessage registers f to be called with every server message, so that many consumers can
+// share c without draining Messages. Messages are never dropped: if handlers fall behind by more
+// than the MessageBuffer, c waits for them, so f must return quickly and must not execute
+// commands itself. Slow handlers should use a Dispatcher, which drops messages instead.
+func (c *Client) OnMessage(f func(Message)) HandlerID {
+	return c.source.OnMessage(f)
+}
+
+// Unregister removes the handler registered with OnMessage identified by id. It returns false if
+// no such handler is registered.
+func (c *Client) Unregister(id HandlerID) bool {
+	return c.source.Unregister(id)
+}
+
+// Done returns a channel which is closed when c is closed. Messages received before are handled
+// by the handlers registered with OnMessage before Close returns.
+func (c *Client) Done() <-chan struct{} {
+	return c.done.C()
+}
+
 // Exec executes the cmd on the BattlEye server and returns its response.
 // Executing is retried for 45 seconds after which the Client is considered to be disconnected
 // and ErrTimeout is returned.
 // A disconnected Client is unlikely to get any more responses from the BattlEye server, so
 // a new Client should be created.
+// cmd is sent as is, use ExecCommand to build commands which include user supplied arguments.
 func (c *Client) Exec(cmd string) (string, error) {
-	c.sendLock.Lock()
-	defer c.sendLock.Unlock()
+	return c.ExecContext(context.Background(), cmd)
+}
+
+// ExecContext executes the cmd on the BattlEye server the same way as Exec. If ctx is done before
+// the response is received ctx.Err() is returned and a late response is discarded.
+func (c *Client) ExecContext(ctx context.Context, cmd string) (string, error) {
+	ctx = c.commandStart(ctx, cmd)
+	info := CommandInfo{Context: ctx, Command: cmd}
+	start := time.Now()
+	resp, err := c.exec(ctx, cmd, &info)
+	info.Duration = time.Since(start)
+	info.Err = err
+	c.commandDone(info)
+	return resp, err
+}
+
+// exec executes cmd, retrying on timeout, and records the attempts in info.
+func (c *Client) exec(ctx context.Context, cmd string, info *CommandInfo) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case c.sendLock <- struct{}{}:
+	}
+	defer func() { <-c.sendLock }()
 
 	until := time.Now().Add(clientTimeout)
 	for time.Now().Before(until) {
-		resp, err := c.send(cmd)
+		info.Attempts++
+		resp, err := c.send(ctx, cmd, info)
 		if err != nil {
 			if err == ErrTimeout {
 				continue
@@ -137,13 +234,18 @@ func (c *Client) Exec(cmd string) (string, error) {
 	return "", ErrTimeout
 }
 
-func (c *Client) send(cmd string) (string, error) {
-	if err := c.write(newCommandPacket(cmd, c.seq())); err != nil {
+// send sends cmd once and waits for its response, recording its sequence number and the number of
+// packets of the response in info.
+func (c *Client) send(ctx context.Context, cmd string, info *CommandInfo) (string, error) {
+	seq := atomic.LoadUint64(&c.ctr)
+	info.Seq = byte(seq)
+	if err := c.write(newCommandPacket(cmd, byte(seq))); err != nil {
 		return "", err
 	}
 
+	sent := time.Now()
 	c.lastLock.Lock()
-	c.lastSend = time.Now()
+	c.lastSend = sent
 	c.lastLock.Unlock()
 
 	t := time.NewTimer(c.timeout)
@@ -151,15 +253,31 @@ func (c *Client) send(cmd string) (string, error) {
 	select {
 	case <-t.C:
 		return "", ErrTimeout
+	case <-ctx.Done():
+		c.abandon(seq)
+		return "", ctx.Err()
 	case err := <-c.errs:
+		c.abandon(seq)
 		return "", err
-	case resp := <-c.cmds:
-		return resp, nil
+	case r := <-c.cmds:
+		info.Fragments = r.parts
+		info.RoundTrip = time.Since(sent)
+		return r.msg, nil
+	}
+}
+
+// abandon skips the response to the command sent with sequence number counter seq, so that it is
+// dropped when it arrives rather than returned to the next command.
+func (c *Client) abandon(seq uint64) {
+	if !atomic.CompareAndSwapUint64(&c.ctr, seq, seq+1) {
+		// The response has been accepted by the receiver, which is waiting to hand it over.
+		<-c.cmds
 	}
 }
 
 // connect connects and authenticates Client to the BattlEye server.
 func (c *Client) connect(addr, pwd string) (err error) {
+	c.addr = addr
 	c.conn, err = net.Dial("udp", addr)
 	if err != nil {
 		return err
@@ -242,8 +360,11 @@ func (c *Client) receiver() {
 			r, err := c.read()
 			if err != nil {
 				// Do not error in case of timeout.
-				if err, ok := err.(net.Error); ok && err.Timeout() {
+				nerr, ok := err.(net.Error)
+				if ok && nerr.Timeout() {
 					continue
+				} else if !ok {
+					c.packetError(err)
 				}
 				c.errs <- err
 				continue
@@ -273,8 +394,7 @@ func (c *Client) handleCommandResponse(r *commandResponse) {
 
 	// response is not fragmented.
 	if !r.multi {
-		c.incr()
-		c.cmds <- r.msg
+		c.deliver(r.seq, reply{msg: r.msg, parts: 1})
 		return
 	}
 
@@ -289,18 +409,60 @@ func (c *Client) handleCommandResponse(r *commandResponse) {
 
 	// If the message is complete send it.
 	if fr.completed() {
-		c.incr()
-		c.cmds <- fr.message()
+		delete(c.fragments, r.seq)
+		c.deliver(r.seq, reply{msg: fr.message(), parts: int(r.multiSize)})
+	}
+}
+
+// reply is a complete response to a command.
+type reply struct {
+	msg   string
+	parts int
+}
+
+// deliver increments the sequence number counter and sends r, the response to the command sent
+// with seq, to the cmds channel unless the command has been abandoned in the meantime.
+func (c *Client) deliver(seq byte, r reply) {
+	n := atomic.LoadUint64(&c.ctr)
+	if byte(n) != seq || !atomic.CompareAndSwapUint64(&c.ctr, n, n+1) {
+		return
 	}
+	c.cmds <- r
 }
 
-// handleServerMessage forwards the message part of ServerMessages to the msgs channel and
-// sends back an acknowledge packet to the server.
+// playerNames returns the names of the players known to the Roster of c, or nil if it has none.
+func (c *Client) playerNames() []string {
+	c.rosterLock.Lock()
+	r := c.roster
+	c.rosterLock.Unlock()
+
+	if r == nil {
+		return nil
+	}
+	return r.names()
+}
+
+// handleServerMessage forwards the message part of ServerMessages to the dispatchers and the
+// msgs channel and sends back an acknowledge packet to the server.
 func (c *Client) handleServerMessage(r *serverMessage) {
+	m := Message{Time: time.Now(), Event: c.parser.Parse(r.msg)}
+	c.serverMessage(m)
+
+	if a, ok := m.Event.(*event.AdminLoggedIn); ok {
+		c.trackSession(Admin{ID: a.ID, IP: a.IP, Port: a.Port})
+	}
+
+	c.dispatchersLock.Lock()
+	for _, d := range c.dispatchers {
+		d.dispatch(m)
+	}
+	c.dispatchersLock.Unlock()
+
 	// If the channel is full, new messages will be dropped.
 	select {
 	case c.msgs <- r.msg:
 	default:
+		c.messageDropped(r.msg)
 	}
 	// Client has to acknowledge the server message by sending back its sequence number.
 	// No response is expected from the server.
@@ -327,11 +489,6 @@ func (c *Client) seq() byte {
 	return byte(atomic.LoadUint64(&c.ctr))
 }
 
-// incr increments the command sequence number counter.
-func (c *Client) incr() {
-	atomic.AddUint64(&c.ctr, 1)
-}
-
 // setDeadline updates the deadline on the connection based on the clients configured timeout.
 func (c *Client) setDeadline() error {
 	return c.conn.SetDeadline(time.Now().Add(c.timeout))
@@ -1,6 +1,8 @@
 package battleye
 
 import (
+	"net"
+	"strings"
 	"time"
 )
 
@@ -33,3 +35,35 @@ func MessageBuffer(size int) Option {
 		return nil
 	}
 }
+
+// TrustedAdminNetworks sets the networks, given as IP addresses or CIDRs, RCON sessions are
+// expected to connect from. See UntrustedAdminHandler.
+func TrustedAdminNetworks(networks ...string) Option {
+	return func(c *Client) error {
+		for _, n := range networks {
+			if !strings.Contains(n, "/") {
+				if ip := net.ParseIP(n); ip != nil && ip.To4() != nil {
+					n += "/32"
+				} else {
+					n += "/128"
+				}
+			}
+			_, ipnet, err := net.ParseCIDR(n)
+			if err != nil {
+				return ErrInvalidNetwork
+			}
+			c.trustedNets = append(c.trustedNets, ipnet)
+		}
+		return nil
+	}
+}
+
+// UntrustedAdminHandler sets a function which is called in a new goroutine when an RCON session
+// connected from outside the networks set by TrustedAdminNetworks is seen, either by a server
+// message or by Admins. It is called once per session.
+func UntrustedAdminHandler(f func(Admin)) Option {
+	return func(c *Client) error {
+		c.onUntrustedAdmin = f
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package battleye
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -105,6 +106,38 @@ func TestClient(t *testing.T) {
 				assert.Equal(t, "Response to: players", resp)
 			},
 		},
+		{
+			name:       "Response to a cancelled command is discarded",
+			clientOpts: []Option{Timeout(1 * time.Second)},
+			testfunc: func(t *testing.T, c *Client, s *server) {
+				s.SetResponseFunc("slow", func() string {
+					time.Sleep(200 * time.Millisecond)
+					return "late"
+				})
+
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				_, err := c.ExecContext(ctx, "slow")
+				assert.Equal(t, context.DeadlineExceeded, err)
+
+				resp, err := c.Exec("status")
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, "Response to: status", resp)
+			},
+		},
+		{
+			name:       "Done context is not sent",
+			clientOpts: []Option{Timeout(1 * time.Second)},
+			testfunc: func(t *testing.T, c *Client, s *server) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_, err := c.ExecContext(ctx, "status")
+				assert.Equal(t, context.Canceled, err)
+				assert.Empty(t, s.Commands())
+			},
+		},
 	}
 
 	for _, tc := range testcases {
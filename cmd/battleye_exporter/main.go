@@ -0,0 +1,238 @@
+// Command battleye_exporter exports Prometheus metrics of BattlEye RCON servers.
+//
+// Usage:
+//
+//	battleye_exporter [flags]
+//
+// The servers are read from the server profiles shared with berc, see package config. Every
+// server is exported unless -servers or -group is set. Each server is connected to with its own
+// Client, which is reconnected when the connection is lost, and its metrics are served at
+// /metrics, labelled with the server name.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/config"
+	"github.com/multiplay/go-battleye/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// maxReconnectDelay is the maximum delay between reconnection attempts.
+	maxReconnectDelay = 30 * time.Second
+
+	// shutdownTimeout is the maximum duration of waiting for scrapes to complete on exit.
+	shutdownTimeout = 5 * time.Second
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+// run runs the exporter until ctx is done and returns the exit code.
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("battleye_exporter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	listen := fs.String("listen", ":9586", "address to serve metrics on")
+	configPath := fs.String("config", config.DefaultPath(), "server profiles file")
+	names := fs.String("servers", "", "comma separated names of the servers to export, instead of all")
+	group := fs.String("group", "", "name of the group of servers to export, instead of all")
+	playersTimeout := fs.Duration("players-timeout", 5*time.Second, "maximum duration of polling the players when scraped")
+	check := fs.Duration("check", 30*time.Second, "interval of checking the connection to the servers")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "battleye_exporter: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	servers, err := selectServers(*configPath, *names, *group)
+	if err != nil {
+		fmt.Fprintln(stderr, "battleye_exporter:", err)
+		return exitError
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	var watchers []*watcher
+	for _, s := range servers {
+		m, err := metrics.NewCollector(s.Name, metrics.PlayersTimeout(*playersTimeout))
+		if err != nil {
+			fmt.Fprintln(stderr, "battleye_exporter:", err)
+			return exitError
+		}
+		reg.MustRegister(m)
+		watchers = append(watchers, &watcher{server: s, metrics: m, check: *check, stderr: stderr})
+	}
+
+	srv := &http.Server{Addr: *listen, Handler: newHandler(reg)}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, w := range watchers {
+		wg.Add(1)
+		go func(w *watcher) {
+			defer wg.Done()
+			w.run(ctx)
+		}(w)
+	}
+
+	code := exitOK
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		fmt.Fprintln(stderr, "battleye_exporter:", err)
+		code = exitError
+	}
+
+	cancel()
+	wg.Wait()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	srv.Shutdown(shutdownCtx) // nolint: errcheck
+	return code
+}
+
+// selectServers returns the server profiles named by names, a comma separated list, or in the
+// group called group, or every server if neither is set.
+func selectServers(path, names, group string) ([]*config.Server, error) {
+	if names != "" && group != "" {
+		return nil, errors.New("-servers and -group are mutually exclusive")
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if group != "" {
+		return cfg.Group(group)
+	}
+
+	list := cfg.Names()
+	if names != "" {
+		list = strings.Split(names, ",")
+	}
+	servers := make([]*config.Server, 0, len(list))
+	for _, name := range list {
+		s, err := cfg.Server(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("%v %q", err, name)
+		}
+		servers = append(servers, s)
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("no servers")
+	}
+	return servers, nil
+}
+
+// newHandler returns the HTTP handler serving the metrics gathered by reg.
+func newHandler(reg *prometheus.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `<html><head><title>BattlEye Exporter</title></head><body><a href="/metrics">Metrics</a></body></html>`)
+	})
+	return mux
+}
+
+// watcher keeps a Client of a server connected and reports its metrics.
+type watcher struct {
+	server  *config.Server
+	metrics *metrics.Collector
+	check   time.Duration
+	stderr  io.Writer
+}
+
+// run connects and reconnects to the server until ctx is done.
+func (w *watcher) run(ctx context.Context) {
+	delay := time.Second
+	for {
+		c, err := w.server.NewClient(battleye.AddHooks(w.metrics.Hooks()))
+		if err != nil {
+			fmt.Fprintf(w.stderr, "battleye_exporter: %v: %v, retrying in %v\n", w.server.Name, err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+			continue
+		}
+		delay = time.Second
+
+		w.metrics.SetClient(c)
+		err = w.watch(ctx, c)
+		w.metrics.SetClient(nil)
+		c.Close() // nolint: errcheck
+		if err == nil {
+			return
+		}
+		fmt.Fprintf(w.stderr, "battleye_exporter: %v: connection lost: %v, reconnecting\n", w.server.Name, err)
+	}
+}
+
+// watch discards the server messages of c, so that they are not counted as dropped, and checks
+// the connection until it's lost, in which case the error is returned, or until ctx is done.
+func (w *watcher) watch(ctx context.Context, c *battleye.Client) error {
+	lost := make(chan error, 1)
+	go func() {
+		t := time.NewTicker(w.check)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := c.ExecContext(ctx, ""); err != nil && ctx.Err() == nil {
+					lost <- err
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-lost:
+			return err
+		case <-c.Messages():
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/multiplay/go-battleye/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+servers:
+  prod1:
+    address: %v
+    password: secret
+    timeout: 1s
+  prod2:
+    address: 127.0.0.1:2302
+    password: secret
+groups:
+  eu: [prod2]
+`
+
+// writeConfig writes a configuration with prod1 at addr and returns its path.
+func writeConfig(t *testing.T, addr string) string {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	if !assert.NoError(t, os.WriteFile(path, []byte(strings.Replace(testConfig, "%v", addr, 1)), 0o600)) {
+		t.FailNow()
+	}
+	return path
+}
+
+// serveRCON serves the login and command packets of the BattlEye RCON protocol on pc, replying to
+// players with two players, until pc is closed.
+func serveRCON(pc net.PacketConn) {
+	send := func(addr net.Addr, payload []byte) {
+		payload = append([]byte{0xff}, payload...)
+		b := []byte{'B', 'E', 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(b[2:], crc32.ChecksumIEEE(payload))
+		pc.WriteTo(append(b, payload...), addr) // nolint: errcheck
+	}
+
+	b := make([]byte, 1500)
+	for {
+		n, addr, err := pc.ReadFrom(b)
+		if err != nil {
+			return
+		}
+		if n < 8 {
+			continue
+		}
+		switch b[7] {
+		case 0x00:
+			send(addr, []byte{0x00, 0x01})
+		case 0x01:
+			resp := ""
+			if string(b[9:n]) == "players" {
+				resp = "Players on server:\n" +
+					"[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n" +
+					"--------------------------------------------------\n" +
+					"0   192.168.1.2:2304      30   d41d8cd98f00b204e9800998ecf8427e(OK) John\n" +
+					"1   10.0.0.3:2316         50   0cc175b9c0f1b6a831c399e269772661(OK) Jane\n" +
+					"(2 players in total)"
+			}
+			send(addr, append([]byte{0x01, b[8]}, resp...))
+		}
+	}
+}
+
+func TestSelectServers(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "127.0.0.1:2301")
+	testcases := []struct {
+		name     string
+		names    string
+		group    string
+		exp      []string
+		expError string
+	}{
+		{name: "All", exp: []string{"prod1", "prod2"}},
+		{name: "By name", names: "prod2, prod1", exp: []string{"prod2", "prod1"}},
+		{name: "By group", group: "eu", exp: []string{"prod2"}},
+		{name: "Unknown server", names: "prod3", expError: `config: unknown server "prod3"`},
+		{name: "Unknown group", group: "us", expError: "config: unknown group"},
+		{name: "Both", names: "prod1", group: "eu", expError: "-servers and -group are mutually exclusive"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			servers, err := selectServers(path, tc.names, tc.group)
+			if tc.expError != "" {
+				assert.EqualError(t, err, tc.expError)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			var names []string
+			for _, s := range servers {
+				names = append(names, s.Name)
+			}
+			assert.Equal(t, tc.exp, names)
+		})
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+	assert.Equal(t, exitUsage, run(context.Background(), []string{"-unknown"}, &stderr))
+	assert.Equal(t, exitUsage, run(context.Background(), []string{"extra"}, &stderr))
+	assert.Equal(t, exitError, run(context.Background(), []string{"-config", filepath.Join(t.TempDir(), "none.yaml")}, &stderr))
+}
+
+func TestWatcher(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer pc.Close() // nolint: errcheck
+	go serveRCON(pc)
+
+	servers, err := selectServers(writeConfig(t, pc.LocalAddr().String()), "prod1", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	m, err := metrics.NewCollector("prod1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	w := &watcher{server: servers[0], metrics: m, check: 10 * time.Millisecond, stderr: io.Discard}
+	go func() {
+		defer close(done)
+		w.run(ctx)
+	}()
+
+	ts := httptest.NewServer(newHandler(reg))
+	defer ts.Close()
+	scrape := func() string {
+		resp, err := http.Get(ts.URL + "/metrics")
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer resp.Body.Close() // nolint: errcheck
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	assert.Eventually(t, func() bool {
+		body := scrape()
+		return strings.Contains(body, `battleye_up{server="prod1"} 1`) &&
+			strings.Contains(body, `battleye_players{server="prod1"} 2`) &&
+			strings.Contains(body, `battleye_players_average_ping_seconds{server="prod1"} 0.04`) &&
+			strings.Contains(body, `battleye_commands_total{result="ok",server="prod1",verb="keepalive"}`)
+	}, 5*time.Second, 50*time.Millisecond, scrape())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "watcher not stopped")
+	}
+	assert.Contains(t, scrape(), `battleye_up{server="prod1"} 0`)
+
+	resp, err := http.Get(ts.URL + "/other")
+	if assert.NoError(t, err) {
+		resp.Body.Close() // nolint: errcheck
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
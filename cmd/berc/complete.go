@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// commands are the RCON and meta-commands offered for completion.
+	commands = []string{
+		"players", "admins", "bans", "missions", "version", "update",
+		"say", "kick", "ban", "addBan", "removeBan", "writeBans",
+		"loadBans", "loadScripts", "loadEvents", "MaxPing",
+		"#mission", "#restart", "#reassign", "#lock", "#unlock", "#shutdown", "#restartserver",
+		".connect", ".disconnect", ".help", ".quit",
+	}
+
+	// playerCommands are the commands whose first argument is a player ID.
+	playerCommands = map[string]bool{"say": true, "kick": true, "ban": true}
+)
+
+// complete completes the word before pos in line, which is a command if it is the first word or a
+// player ID if it is the first argument of a command in playerCommands. It returns false if there
+// is nothing to complete.
+func complete(line string, pos int, playerIDs []int) (string, int, bool) {
+	prefix, suffix := line[:pos], line[pos:]
+
+	var candidates []string
+	start := strings.LastIndexByte(prefix, ' ') + 1
+	word := prefix[start:]
+	switch fields := strings.Fields(prefix[:start]); {
+	case len(fields) == 0:
+		candidates = commands
+	case len(fields) == 1 && playerCommands[fields[0]]:
+		if fields[0] == "say" {
+			candidates = append(candidates, "-1")
+		}
+		ids := append([]int(nil), playerIDs...)
+		sort.Ints(ids)
+		for _, id := range ids {
+			candidates = append(candidates, strconv.Itoa(id))
+		}
+	default:
+		return "", 0, false
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+
+	var completed string
+	switch len(matches) {
+	case 0:
+		return "", 0, false
+	case 1:
+		completed = matches[0] + " "
+	default:
+		completed = commonPrefix(matches)
+	}
+	if completed == word {
+		return "", 0, false
+	}
+
+	newPrefix := prefix[:start] + completed
+	return newPrefix + suffix, len(newPrefix), true
+}
+
+// commonPrefix returns the longest common prefix of words.
+func commonPrefix(words []string) string {
+	p := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, p) {
+			p = p[:len(p)-1]
+		}
+	}
+	return p
+}
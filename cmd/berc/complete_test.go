@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		line    string
+		pos     int
+		ids     []int
+		expLine string
+		expPos  int
+		expOk   bool
+	}{
+		{
+			name:    "Unique command",
+			line:    "pla",
+			pos:     3,
+			expLine: "players ",
+			expPos:  8,
+			expOk:   true,
+		},
+		{
+			name:  "Ambiguous command already at the common prefix",
+			line:  "load",
+			pos:   4,
+			expOk: false,
+		},
+		{
+			name:    "Ambiguous command is completed to the common prefix",
+			line:    "#rest",
+			pos:     5,
+			expLine: "#restart",
+			expPos:  8,
+			expOk:   true,
+		},
+		{
+			name:    "Unique hash command",
+			line:    "#u",
+			pos:     2,
+			expLine: "#unlock ",
+			expPos:  8,
+			expOk:   true,
+		},
+		{
+			name:    "Meta-command",
+			line:    ".dis",
+			pos:     4,
+			expLine: ".disconnect ",
+			expPos:  12,
+			expOk:   true,
+		},
+		{
+			name:    "Player ID",
+			line:    "kick 1",
+			pos:     6,
+			ids:     []int{3, 12},
+			expLine: "kick 12 ",
+			expPos:  8,
+			expOk:   true,
+		},
+		{
+			name:    "Everyone for say",
+			line:    "say -",
+			pos:     5,
+			ids:     []int{3},
+			expLine: "say -1 ",
+			expPos:  7,
+			expOk:   true,
+		},
+		{
+			name:    "Completion in the middle of the line",
+			line:    "kick  reason",
+			pos:     5,
+			ids:     []int{7},
+			expLine: "kick 7  reason",
+			expPos:  7,
+			expOk:   true,
+		},
+		{
+			name:  "No player argument",
+			line:  "players 1",
+			pos:   9,
+			ids:   []int{12},
+			expOk: false,
+		},
+		{
+			name:  "No match",
+			line:  "xyz",
+			pos:   3,
+			expOk: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			line, pos, ok := complete(tc.line, tc.pos, tc.ids)
+			if !assert.Equal(t, tc.expOk, ok) || !ok {
+				return
+			}
+			assert.Equal(t, tc.expLine, line)
+			assert.Equal(t, tc.expPos, pos)
+		})
+	}
+}
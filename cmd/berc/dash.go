@@ -0,0 +1,664 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+)
+
+const (
+	// maxLogLines is the number of server messages and command results kept by the dashboard.
+	maxLogLines = 5000
+
+	// highPing is the ping in milliseconds above which it is highlighted.
+	highPing = 250
+
+	// dashKeys is shown in the title bar if there is no status to report.
+	dashKeys = "PgUp/PgDn scroll  F5 refresh  Esc quit"
+
+	// dashHelp is printed to the log pane by the .help meta-command.
+	dashHelp = `Lines are executed as RCON commands on the server, the response is shown here.
+Keys:
+  Tab          complete commands and player IDs
+  Up/Down      browse the command history
+  PgUp/PgDn    scroll this pane
+  F5           refresh the player and ban lists
+  Esc, Ctrl-C  exit
+Meta-commands:
+  .help        print this help
+  .quit        exit`
+)
+
+var (
+	// titleStyle is the style of the title bar and the pane headers.
+	titleStyle = tcell.StyleDefault.Reverse(true)
+
+	// banCommands are the commands which change the ban list.
+	banCommands = map[string]bool{"ban": true, "addBan": true, "removeBan": true, "loadBans": true}
+)
+
+// rect is an area of the screen.
+type rect struct {
+	x, y, w, h int
+}
+
+// logLine is a line of the log pane.
+type logLine struct {
+	text  string
+	style tcell.Style
+}
+
+// dashboard is a full-screen view of a server showing the players, the ban list, server messages
+// and a command input line.
+type dashboard struct {
+	screen tcell.Screen
+	addr   string
+
+	// exec executes a command on the server.
+	exec func(cmd string) (string, error)
+
+	// refresh updates the player and ban lists.
+	refresh func()
+
+	mu      sync.Mutex
+	players []battleye.Player
+	bans    []battleye.Ban
+	log     []logLine
+	scroll  int
+	status  string
+	input   []rune
+	cursor  int
+	history []string
+	histPos int
+}
+
+// runDash runs the dash command.
+func runDash(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dash", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var cf connFlags
+	cf.register(fs)
+	poll := fs.Duration("poll", 10*time.Second, "interval of refreshing the player list")
+	bansInterval := fs.Duration("bans", time.Minute, "interval of refreshing the ban list")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	t, err := cf.target()
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	if t == nil {
+		fmt.Fprintln(stderr, "berc: -address or -server is required")
+		return exitUsage
+	}
+
+	c, err := battleye.NewClient(t.address, t.password, t.options...)
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return connectExitCode(err)
+	}
+	defer c.Close() // nolint: errcheck
+
+	r, err := battleye.NewRoster(c, battleye.PollInterval(*poll))
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return execExitCode(err)
+	}
+	defer r.Close()
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	if err := screen.Init(); err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	defer screen.Fini()
+
+	d := newDashboard(screen, t.address)
+	d.exec = c.Exec
+	refreshBans := func() {
+		bans, err := c.Bans()
+		if err != nil {
+			d.setStatus("ban list: " + err.Error())
+			return
+		}
+		d.setBans(bans)
+	}
+	d.refresh = func() {
+		if err := r.Refresh(); err != nil {
+			d.setStatus("player list: " + err.Error())
+			return
+		}
+		d.setPlayers(r.Players())
+		refreshBans()
+	}
+
+	// Messages are parsed by the client, which uses the names of the roster to attribute chat.
+	c.OnMessage(func(m battleye.Message) {
+		d.addEvent(m.Time, m.Event)
+	})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// The roster is updated by events and its own polling, so reading it is cheap.
+		players := time.NewTicker(time.Second)
+		defer players.Stop()
+		bans := time.NewTicker(*bansInterval)
+		defer bans.Stop()
+
+		d.setPlayers(r.Players())
+		refreshBans()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-players.C:
+				d.setPlayers(r.Players())
+			case <-bans.C:
+				refreshBans()
+			}
+		}
+	}()
+
+	d.loop()
+
+	close(stop)
+	r.Close()
+	c.Close() // nolint: errcheck
+	wg.Wait()
+
+	return exitOK
+}
+
+// newDashboard returns a dashboard of the server at addr drawn on screen.
+func newDashboard(screen tcell.Screen, addr string) *dashboard {
+	return &dashboard{screen: screen, addr: addr}
+}
+
+// loop handles terminal events until the user quits.
+func (d *dashboard) loop() {
+	d.draw()
+	for {
+		switch ev := d.screen.PollEvent().(type) {
+		case nil:
+			return
+		case *tcell.EventResize:
+			d.screen.Sync()
+		case *tcell.EventKey:
+			if d.handleKey(ev) {
+				return
+			}
+		}
+		d.draw()
+	}
+}
+
+// update requests a redraw from the loop goroutine.
+func (d *dashboard) update() {
+	// If the queue is full a redraw is pending anyway.
+	d.screen.PostEvent(tcell.NewEventInterrupt(nil)) // nolint: errcheck
+}
+
+// setPlayers replaces the player list.
+func (d *dashboard) setPlayers(players []battleye.Player) {
+	d.mu.Lock()
+	d.players = players
+	d.mu.Unlock()
+	d.update()
+}
+
+// setBans replaces the ban list.
+func (d *dashboard) setBans(bans []battleye.Ban) {
+	d.mu.Lock()
+	d.bans = bans
+	d.mu.Unlock()
+	d.update()
+}
+
+// setStatus sets the status shown in the title bar.
+func (d *dashboard) setStatus(status string) {
+	d.mu.Lock()
+	d.status = status
+	d.mu.Unlock()
+	d.update()
+}
+
+// addEvent adds the server message e received at t to the log pane.
+func (d *dashboard) addEvent(t time.Time, e event.Event) {
+	d.addLines(eventStyle(e), t.Format("15:04:05")+" "+e.Raw())
+}
+
+// addLines adds text, which may span multiple lines, to the log pane.
+func (d *dashboard) addLines(style tcell.Style, text string) {
+	d.mu.Lock()
+	for _, l := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		d.log = append(d.log, logLine{text: l, style: style})
+		if d.scroll > 0 {
+			// Keep the scrolled view in place.
+			d.scroll++
+		}
+	}
+	if n := len(d.log) - maxLogLines; n > 0 {
+		d.log = append(d.log[:0], d.log[n:]...)
+	}
+	d.mu.Unlock()
+	d.update()
+}
+
+// run executes cmd and adds the result to the log pane.
+func (d *dashboard) run(cmd string) {
+	d.addLines(tcell.StyleDefault.Bold(true), "> "+cmd)
+	resp, err := d.exec(cmd)
+	if err != nil {
+		d.addLines(tcell.StyleDefault.Foreground(tcell.ColorRed), "error: "+err.Error())
+		return
+	}
+	if resp != "" {
+		d.addLines(tcell.StyleDefault, resp)
+	}
+	if banCommands[strings.Fields(cmd)[0]] && d.refresh != nil {
+		d.refresh()
+	}
+}
+
+// handleKey handles a key press and returns true if the user quits.
+func (d *dashboard) handleKey(ev *tcell.EventKey) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return true
+	case tcell.KeyCtrlD:
+		if len(d.input) == 0 {
+			return true
+		}
+	case tcell.KeyEnter:
+		return d.enter()
+	case tcell.KeyRune:
+		d.input = append(d.input[:d.cursor], append([]rune{ev.Rune()}, d.input[d.cursor:]...)...)
+		d.cursor++
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if d.cursor > 0 {
+			d.input = append(d.input[:d.cursor-1], d.input[d.cursor:]...)
+			d.cursor--
+		}
+	case tcell.KeyDelete:
+		if d.cursor < len(d.input) {
+			d.input = append(d.input[:d.cursor], d.input[d.cursor+1:]...)
+		}
+	case tcell.KeyLeft:
+		if d.cursor > 0 {
+			d.cursor--
+		}
+	case tcell.KeyRight:
+		if d.cursor < len(d.input) {
+			d.cursor++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		d.cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		d.cursor = len(d.input)
+	case tcell.KeyCtrlU:
+		d.input, d.cursor = nil, 0
+	case tcell.KeyUp:
+		d.browse(-1)
+	case tcell.KeyDown:
+		d.browse(1)
+	case tcell.KeyTab:
+		d.complete()
+	case tcell.KeyPgUp:
+		d.scroll += d.page()
+	case tcell.KeyPgDn:
+		if d.scroll -= d.page(); d.scroll < 0 {
+			d.scroll = 0
+		}
+	case tcell.KeyF5:
+		if d.refresh != nil {
+			go d.refresh()
+		}
+	case tcell.KeyCtrlL:
+		d.screen.Sync()
+	}
+	return false
+}
+
+// enter executes the input line and returns true if it is the .quit meta-command.
+// mu must be held.
+func (d *dashboard) enter() bool {
+	line := strings.TrimSpace(string(d.input))
+	d.input, d.cursor = nil, 0
+	if line == "" {
+		return false
+	}
+	if len(d.history) == 0 || d.history[len(d.history)-1] != line {
+		d.history = append(d.history, line)
+	}
+	d.histPos = len(d.history)
+	d.scroll = 0
+
+	switch line {
+	case ".quit", ".exit":
+		return true
+	case ".help":
+		go d.addLines(tcell.StyleDefault, dashHelp)
+	default:
+		if strings.HasPrefix(line, ".") {
+			go d.addLines(tcell.StyleDefault, fmt.Sprintf("unknown meta-command %v, see .help", line))
+		} else if d.exec != nil {
+			go d.run(line)
+		}
+	}
+	return false
+}
+
+// browse moves through the history by delta entries.
+// mu must be held.
+func (d *dashboard) browse(delta int) {
+	pos := d.histPos + delta
+	switch {
+	case pos < 0:
+		return
+	case pos >= len(d.history):
+		d.histPos, d.input = len(d.history), nil
+	default:
+		d.histPos, d.input = pos, []rune(d.history[pos])
+	}
+	d.cursor = len(d.input)
+}
+
+// complete completes the word before the cursor.
+// mu must be held.
+func (d *dashboard) complete() {
+	ids := make([]int, len(d.players))
+	for i, p := range d.players {
+		ids[i] = p.ID
+	}
+	line, pos, ok := complete(string(d.input), len(string(d.input[:d.cursor])), ids)
+	if !ok {
+		return
+	}
+	d.input, d.cursor = []rune(line), utf8.RuneCountInString(line[:pos])
+}
+
+// page returns the number of lines scrolled by PgUp and PgDn.
+func (d *dashboard) page() int {
+	w, h := d.screen.Size()
+	_, _, log, _ := layout(w, h)
+	if log.h < 4 {
+		return 1
+	}
+	return log.h / 2
+}
+
+// draw draws the dashboard.
+func (d *dashboard) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.screen
+	s.Clear()
+	w, h := s.Size()
+	players, bans, log, input := layout(w, h)
+
+	status := d.status
+	if status == "" {
+		status = dashKeys
+	}
+	fill(s, rect{0, 0, w, 1}, titleStyle)
+	used := drawText(s, 0, 0, w, titleStyle, " berc "+d.addr+" ")
+	drawText(s, used+1, 0, w-used-1, titleStyle, "| "+status)
+
+	d.drawPlayers(players)
+	d.drawBans(bans)
+	if bans.h > 0 && bans.x > 0 {
+		for y := bans.y; y < bans.y+bans.h; y++ {
+			s.SetContent(bans.x-1, y, tcell.RuneVLine, nil, tcell.StyleDefault)
+		}
+	}
+	d.drawLog(log)
+
+	const prompt = "> "
+	drawText(s, input.x, input.y, input.w, tcell.StyleDefault.Bold(true), prompt)
+	width := input.w - len(prompt) - 1
+	start := 0
+	if width > 0 && d.cursor > width {
+		start = d.cursor - width
+	}
+	drawText(s, input.x+len(prompt), input.y, input.w-len(prompt), tcell.StyleDefault, string(d.input[start:]))
+	s.ShowCursor(input.x+len(prompt)+d.cursor-start, input.y)
+
+	s.Show()
+}
+
+// drawPlayers draws the player table in r.
+// mu must be held.
+func (d *dashboard) drawPlayers(r rect) {
+	if r.h <= 0 {
+		return
+	}
+	s := d.screen
+	fill(s, rect{r.x, r.y, r.w, 1}, titleStyle)
+	drawText(s, r.x, r.y, r.w, titleStyle, fmt.Sprintf(" Players (%v)", len(d.players)))
+	if r.h > 1 {
+		drawText(s, r.x, r.y+1, r.w, tcell.StyleDefault.Bold(true), playerHeader)
+	}
+	for i, p := range d.players {
+		y := r.y + 2 + i
+		if y >= r.y+r.h {
+			break
+		}
+		style := tcell.StyleDefault
+		if p.Ping > highPing {
+			style = style.Foreground(tcell.ColorRed)
+		} else if p.Lobby {
+			style = style.Dim(true)
+		}
+		drawText(s, r.x, y, r.w, style, playerRow(p))
+	}
+}
+
+// drawBans draws the ban list in r.
+// mu must be held.
+func (d *dashboard) drawBans(r rect) {
+	if r.h <= 0 || r.w <= 0 {
+		return
+	}
+	s := d.screen
+	fill(s, rect{r.x, r.y, r.w, 1}, titleStyle)
+	drawText(s, r.x, r.y, r.w, titleStyle, fmt.Sprintf(" Bans (%v)", len(d.bans)))
+	for i, b := range d.bans {
+		y := r.y + 1 + i
+		if y >= r.y+r.h {
+			break
+		}
+		drawText(s, r.x, y, r.w, tcell.StyleDefault, banRow(b))
+	}
+}
+
+// drawLog draws the end of the log pane, scrolled up by d.scroll lines, in r.
+// mu must be held.
+func (d *dashboard) drawLog(r rect) {
+	if r.h <= 0 {
+		return
+	}
+	s := d.screen
+	title := " Messages"
+	if d.scroll > 0 {
+		title += fmt.Sprintf(" (scrolled up %v lines)", d.scroll)
+	}
+	fill(s, rect{r.x, r.y, r.w, 1}, titleStyle)
+	drawText(s, r.x, r.y, r.w, titleStyle, title)
+
+	// Wrap lines from the end until the visible window is filled.
+	rows := r.h - 1
+	var lines []logLine
+	for i := len(d.log) - 1; i >= 0 && len(lines) < rows+d.scroll; i-- {
+		wrapped := wrap(d.log[i].text, r.w)
+		for j := len(wrapped) - 1; j >= 0; j-- {
+			lines = append(lines, logLine{text: wrapped[j], style: d.log[i].style})
+		}
+	}
+	if limit := len(lines) - rows; d.scroll > limit {
+		d.scroll = 0
+		if limit > 0 {
+			d.scroll = limit
+		}
+	}
+
+	lines = lines[d.scroll:]
+	for i := 0; i < rows && i < len(lines); i++ {
+		l := lines[i]
+		drawText(s, r.x, r.y+r.h-1-i, r.w, l.style, l.text)
+	}
+}
+
+// layout returns the areas of the player table, ban list, log pane and input line on a screen
+// of w by h cells. The title bar is the first row.
+func layout(w, h int) (players, bans, log, input rect) {
+	body := h - 2
+	if body < 0 {
+		body = 0
+	}
+	top := body / 2
+	left := w * 3 / 5
+
+	players = rect{0, 1, left, top}
+	bans = rect{left + 1, 1, w - left - 1, top}
+	log = rect{0, 1 + top, w, body - top}
+	input = rect{0, h - 1, w, 1}
+	return players, bans, log, input
+}
+
+// playerHeader is the header of the player table.
+const playerHeader = "  # Ping IP:Port               Name"
+
+// playerRow formats p as a row of the player table.
+func playerRow(p battleye.Player) string {
+	ping := "-"
+	if p.Ping >= 0 {
+		ping = strconv.Itoa(p.Ping)
+	}
+	name := p.Name
+	if p.Lobby {
+		name += " (Lobby)"
+	}
+	if p.GUID == "" || !p.Verified {
+		name += " (?)"
+	}
+	addr := p.IP.String() + ":" + strconv.Itoa(p.Port)
+	return fmt.Sprintf("%3d %4s %-21s %s", p.ID, ping, addr, name)
+}
+
+// banRow formats b as a row of the ban list.
+func banRow(b battleye.Ban) string {
+	banned := b.GUID
+	if b.IP != nil {
+		banned = b.IP.String()
+	}
+	return fmt.Sprintf("%3d %-7s %s %s", b.ID, banDuration(b.MinutesLeft), banned, b.Reason)
+}
+
+// banDuration formats the remaining minutes of a ban.
+func banDuration(minutes int) string {
+	switch {
+	case minutes == battleye.PermanentBan:
+		return "perm"
+	case minutes <= 0:
+		return "expired"
+	case minutes < 60:
+		return fmt.Sprintf("%vm", minutes)
+	case minutes < 24*60:
+		return fmt.Sprintf("%vh%vm", minutes/60, minutes%60)
+	default:
+		return fmt.Sprintf("%vd%vh", minutes/(24*60), minutes%(24*60)/60)
+	}
+}
+
+// eventStyle returns the style of server message e in the log pane.
+func eventStyle(e event.Event) tcell.Style {
+	switch e := e.(type) {
+	case *event.Chat:
+		if e.Admin {
+			return tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		}
+		return tcell.StyleDefault
+	case *event.PlayerKicked:
+		return tcell.StyleDefault.Foreground(tcell.ColorRed)
+	case *event.AdminLoggedIn:
+		return tcell.StyleDefault.Foreground(tcell.ColorFuchsia)
+	case *event.PlayerConnected, *event.PlayerDisconnected:
+		return tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	default:
+		return tcell.StyleDefault.Dim(true)
+	}
+}
+
+// wrap splits text into lines of at most width cells.
+func wrap(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+
+	var lines []string
+	var b strings.Builder
+	used := 0
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			r = ' '
+		}
+		rw := runewidth.RuneWidth(r)
+		if used+rw > width {
+			lines = append(lines, b.String())
+			b.Reset()
+			used = 0
+		}
+		b.WriteRune(r)
+		used += rw
+	}
+	return append(lines, b.String())
+}
+
+// drawText draws text at x, y clipped to w cells and returns the number of cells used.
+func drawText(s tcell.Screen, x, y, w int, style tcell.Style, text string) int {
+	used := 0
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			r = ' '
+		}
+		rw := runewidth.RuneWidth(r)
+		if used+rw > w {
+			break
+		}
+		s.SetContent(x+used, y, r, nil, style)
+		used += rw
+	}
+	return used
+}
+
+// fill fills r with blanks in style.
+func fill(s tcell.Screen, r rect, style tcell.Style) {
+	for y := r.y; y < r.y+r.h; y++ {
+		for x := r.x; x < r.x+r.w; x++ {
+			s.SetContent(x, y, ' ', nil, style)
+		}
+	}
+}
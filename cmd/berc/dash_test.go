@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDashboard returns a dashboard drawn on a simulated screen of w by h cells.
+func newTestDashboard(t *testing.T, w, h int) (*dashboard, tcell.SimulationScreen) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if !assert.NoError(t, s.Init()) {
+		t.FailNow()
+	}
+	s.SetSize(w, h)
+	t.Cleanup(s.Fini)
+	return newDashboard(s, "127.0.0.1:2302"), s
+}
+
+// screenLines returns the contents of s as lines with trailing blanks removed.
+func screenLines(s tcell.SimulationScreen) []string {
+	cells, w, h := s.GetContents()
+	lines := make([]string, h)
+	for y := 0; y < h; y++ {
+		var b strings.Builder
+		for x := 0; x < w; x++ {
+			if r := cells[y*w+x].Runes; len(r) > 0 {
+				b.WriteRune(r[0])
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// typeLine enters line followed by enter into d.
+func typeLine(d *dashboard, line string) bool {
+	for _, r := range line {
+		d.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return d.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+}
+
+func TestLayout(t *testing.T) {
+	t.Parallel()
+
+	players, bans, log, input := layout(100, 30)
+	assert.Equal(t, rect{0, 1, 60, 14}, players)
+	assert.Equal(t, rect{61, 1, 39, 14}, bans)
+	assert.Equal(t, rect{0, 15, 100, 14}, log)
+	assert.Equal(t, rect{0, 29, 100, 1}, input)
+
+	// Tiny screens must not produce negative sizes.
+	players, bans, log, _ = layout(2, 1)
+	for _, r := range []rect{players, bans, log} {
+		assert.True(t, r.h >= 0, "%+v", r)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		text  string
+		width int
+		exp   []string
+	}{
+		{name: "Empty", text: "", width: 5, exp: []string{""}},
+		{name: "Fits", text: "hello", width: 5, exp: []string{"hello"}},
+		{name: "Wrapped", text: "hello world", width: 5, exp: []string{"hello", " worl", "d"}},
+		{name: "Wide runes", text: "日本語", width: 4, exp: []string{"日本", "語"}},
+		{name: "Control characters", text: "a\tb", width: 5, exp: []string{"a b"}},
+		{name: "No width", text: "hello", width: 0, exp: []string{"hello"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, wrap(tc.text, tc.width))
+		})
+	}
+}
+
+func TestBanDuration(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		minutes int
+		exp     string
+	}{
+		{battleye.PermanentBan, "perm"},
+		{0, "expired"},
+		{45, "45m"},
+		{90, "1h30m"},
+		{1437, "23h57m"},
+		{3 * 24 * 60, "3d0h"},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.exp, banDuration(tc.minutes), "%v minutes", tc.minutes)
+	}
+}
+
+func TestPlayerRow(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "  0   31 192.168.1.2:2304      John Doe",
+		playerRow(battleye.Player{ID: 0, IP: net.ParseIP("192.168.1.2"), Port: 2304, Ping: 31, GUID: "d41d8cd98f00b204e9800998ecf8427e", Verified: true, Name: "John Doe"}))
+	assert.Equal(t, " 12    - 10.0.0.3:2316         Jane (Lobby) (?)",
+		playerRow(battleye.Player{ID: 12, IP: net.ParseIP("10.0.0.3"), Port: 2316, Ping: -1, Name: "Jane", Lobby: true}))
+}
+
+func TestDashboardDraw(t *testing.T) {
+	d, s := newTestDashboard(t, 80, 12)
+	d.setPlayers([]battleye.Player{
+		{ID: 3, IP: net.ParseIP("10.0.0.2"), Port: 2304, Ping: 42, GUID: "abc", Verified: true, Name: "Kerry"},
+	})
+	d.setBans([]battleye.Ban{{ID: 0, GUID: "d41d8cd98f00b204e9800998ecf8427e", MinutesLeft: battleye.PermanentBan, Reason: "Cheating"}})
+	d.addEvent(time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC), event.Parse("(Global) Kerry: hello"))
+	d.draw()
+
+	out := strings.Join(screenLines(s), "\n")
+	assert.Contains(t, out, "berc 127.0.0.1:2302")
+	assert.Contains(t, out, "Players (1)")
+	assert.Contains(t, out, "  3   42 10.0.0.2:2304")
+	assert.Contains(t, out, "Bans (1)")
+	assert.Contains(t, out, "  0 perm")
+	assert.Contains(t, out, "15:04:05 (Global) Kerry: hello")
+}
+
+func TestDashboardScroll(t *testing.T) {
+	d, s := newTestDashboard(t, 40, 10)
+	for i := 0; i < 20; i++ {
+		d.addLines(tcell.StyleDefault, "line "+string(rune('a'+i)))
+	}
+	d.draw()
+	lines := screenLines(s)
+	assert.Equal(t, "line t", lines[8])
+
+	d.handleKey(tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone))
+	d.draw()
+	lines = screenLines(s)
+	assert.Equal(t, "line r", lines[8])
+	assert.Contains(t, lines[5], "scrolled up 2 lines")
+
+	// New lines don't move the scrolled view.
+	d.addLines(tcell.StyleDefault, "line u")
+	d.draw()
+	assert.Equal(t, "line r", screenLines(s)[8])
+
+	// Scrolling is limited to the available lines.
+	for i := 0; i < 20; i++ {
+		d.handleKey(tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone))
+	}
+	d.draw()
+	assert.Equal(t, "line a", screenLines(s)[6])
+
+	for i := 0; i < 20; i++ {
+		d.handleKey(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone))
+	}
+	d.draw()
+	assert.Equal(t, "line u", screenLines(s)[8])
+}
+
+func TestDashboardInput(t *testing.T) {
+	d, _ := newTestDashboard(t, 80, 24)
+	d.setPlayers([]battleye.Player{{ID: 7, Name: "Kerry"}})
+
+	cmds := make(chan string, 1)
+	d.exec = func(cmd string) (string, error) {
+		cmds <- cmd
+		if cmd == "bad" {
+			return "", errors.New("timeout")
+		}
+		return "ok", nil
+	}
+
+	// Completion of commands and player IDs.
+	for _, r := range "pla" {
+		d.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	d.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	assert.Equal(t, "players ", string(d.input))
+	d.handleKey(tcell.NewEventKey(tcell.KeyCtrlU, 0, tcell.ModNone))
+	for _, r := range "kick " {
+		d.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	d.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	assert.Equal(t, "kick 7 ", string(d.input))
+	d.handleKey(tcell.NewEventKey(tcell.KeyCtrlU, 0, tcell.ModNone))
+
+	// Editing in the middle of the line.
+	for _, r := range "sy -1 hi" {
+		d.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	d.handleKey(tcell.NewEventKey(tcell.KeyHome, 0, tcell.ModNone))
+	d.handleKey(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone))
+	d.handleKey(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
+	assert.False(t, d.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)))
+	assert.Equal(t, "say -1 hi", <-cmds)
+	assert.Empty(t, d.input)
+
+	assert.False(t, typeLine(d, "bad"))
+	assert.Equal(t, "bad", <-cmds)
+	assert.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.log) > 0 && d.log[len(d.log)-1].text == "error: timeout"
+	}, time.Second, 10*time.Millisecond)
+
+	// History.
+	d.handleKey(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+	assert.Equal(t, "bad", string(d.input))
+	d.handleKey(tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
+	assert.Equal(t, "say -1 hi", string(d.input))
+	d.handleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	d.handleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	assert.Empty(t, d.input)
+
+	assert.True(t, typeLine(d, ".quit"))
+	assert.True(t, d.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+}
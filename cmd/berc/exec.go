@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/internal/errkind"
+)
+
+var (
+	// typedCommands return the parsed results of commands for JSON output.
+	typedCommands = map[string]func(c *battleye.Client) (interface{}, error){
+		"players": func(c *battleye.Client) (interface{}, error) {
+			return c.Players()
+		},
+		"admins": func(c *battleye.Client) (interface{}, error) {
+			return c.Admins()
+		},
+		"missions": func(c *battleye.Client) (interface{}, error) {
+			return c.Missions()
+		},
+		"bans": func(c *battleye.Client) (interface{}, error) {
+			return c.Bans()
+		},
+	}
+)
+
+// rawResult is the JSON output of commands without a typed result.
+type rawResult struct {
+	Command  string `json:"command"`
+	Response string `json:"response"`
+}
+
+// errorResult is the JSON output if a command failed.
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+// runExec runs the exec command.
+func runExec(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: berc exec [flags] command [arguments] [flags]")
+		fs.PrintDefaults()
+	}
+	var cf connFlags
+	cf.register(fs)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	cmdArgs, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	t, err := cf.target()
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	if len(cmdArgs) == 0 || t == nil {
+		fs.Usage()
+		return exitUsage
+	}
+	cmd := strings.Join(cmdArgs, " ")
+
+	c, err := battleye.NewClient(t.address, t.password, t.options...)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, err, connectExitCode(err))
+	}
+	defer c.Close() // nolint: errcheck
+
+	if !*asJSON {
+		resp, err := execChecked(c, cmd)
+		if err != nil {
+			return fail(stdout, stderr, false, err, execExitCode(err))
+		}
+		fmt.Fprintln(stdout, strings.TrimRight(resp, "\n"))
+		return exitOK
+	}
+
+	var result interface{}
+	if f, ok := typedCommands[cmd]; ok {
+		result, err = f(c)
+	} else {
+		var resp string
+		resp, err = execChecked(c, cmd)
+		result = rawResult{Command: cmd, Response: resp}
+	}
+	if err != nil {
+		return fail(stdout, stderr, true, err, execExitCode(err))
+	}
+
+	if err := writeJSON(stdout, result); err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// execer executes commands, which *battleye.Client implements.
+type execer interface {
+	Exec(cmd string) (string, error)
+}
+
+// execChecked executes cmd and returns a *battleye.CommandError if the server replies that it
+// failed, e.g. with "Unknown command", so that the exit code reports it.
+func execChecked(c execer, cmd string) (string, error) {
+	resp, err := c.Exec(cmd)
+	if err != nil {
+		return "", err
+	}
+	return resp, battleye.CheckReply(cmd, resp)
+}
+
+// fail reports err, as JSON on stdout if asJSON is set, and returns code.
+func fail(stdout, stderr io.Writer, asJSON bool, err error, code int) int {
+	if asJSON {
+		writeJSON(stdout, errorResult{Error: err.Error()}) // nolint: errcheck
+	}
+	fmt.Fprintln(stderr, "berc:", err)
+	return code
+}
+
+// writeJSON writes v to w as JSON.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// connectExitCode returns the exit code for an error returned by NewClient.
+func connectExitCode(err error) int {
+	switch err {
+	case battleye.ErrLoginFailed:
+		return exitLoginFailed
+	case battleye.ErrTimeout:
+		return exitTimeout
+	default:
+		return exitError
+	}
+}
+
+// execExitCode returns the exit code for an error returned while executing a command. Only the
+// commands the server or the Client reject are command errors, so that scripts can tell them
+// apart from a lost connection.
+func execExitCode(err error) int {
+	kind, _ := errkind.Of(err)
+	switch kind {
+	case errkind.Timeout:
+		return exitTimeout
+	case errkind.LoginFailed:
+		return exitLoginFailed
+	case errkind.Invalid, errkind.NotFound, errkind.Rejected, errkind.CommandFailed:
+		return exitCommandError
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodes(t *testing.T) {
+	t.Parallel()
+
+	other := errors.New("other")
+
+	assert.Equal(t, exitLoginFailed, connectExitCode(battleye.ErrLoginFailed))
+	assert.Equal(t, exitTimeout, connectExitCode(battleye.ErrTimeout))
+	assert.Equal(t, exitError, connectExitCode(other))
+
+}
+
+func TestExecExitCode(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		err  error
+		exp  int
+	}{
+		{name: "Timeout", err: battleye.ErrTimeout, exp: exitTimeout},
+		{name: "Deadline exceeded", err: context.DeadlineExceeded, exp: exitTimeout},
+		{name: "Login failed", err: battleye.ErrLoginFailed, exp: exitLoginFailed},
+		{name: "Failure reply", err: &battleye.CommandError{Cmd: "loadBans", Msg: "failed"}, exp: exitCommandError},
+		{name: "Kick failed", err: battleye.ErrKickFailed, exp: exitCommandError},
+		{name: "Player not found", err: battleye.ErrPlayerNotFound, exp: exitCommandError},
+		{name: "Invalid command", err: battleye.ErrInvalidCommand, exp: exitCommandError},
+		{name: "Unexpected response", err: battleye.ErrUnexpectedResponse, exp: exitError},
+		{name: "Canceled", err: context.Canceled, exp: exitError},
+		{name: "Closed", err: fmt.Errorf("write: %w", net.ErrClosed), exp: exitError},
+		{name: "Connection refused", err: &net.OpError{Op: "read", Net: "udp", Err: errors.New("connection refused")}, exp: exitError},
+		{name: "Other", err: errors.New("other"), exp: exitError},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, execExitCode(tc.err))
+		})
+	}
+}
+
+// replyExecer is an execer replying with reply.
+type replyExecer string
+
+func (r replyExecer) Exec(cmd string) (string, error) {
+	return string(r), nil
+}
+
+func TestExecChecked(t *testing.T) {
+	t.Parallel()
+
+	resp, err := execChecked(replyExecer("Players on server:\n"), "players")
+	assert.NoError(t, err)
+	assert.Equal(t, "Players on server:\n", resp)
+
+	_, err = execChecked(replyExecer("Unknown command"), "plyers")
+	assert.Equal(t, &battleye.CommandError{Cmd: "plyers", Msg: "Unknown command"}, err)
+	assert.Equal(t, exitCommandError, execExitCode(err))
+}
+
+func TestRunExecUsage(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsage, run([]string{"exec", "-address", "127.0.0.1:2301"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "Usage: berc exec")
+	assert.Empty(t, stdout.String())
+
+	stderr.Reset()
+	assert.Equal(t, exitUsage, run([]string{"unknown"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "unknown"`)
+}
+
+func TestFail(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitTimeout, fail(&stdout, &stderr, true, battleye.ErrTimeout, exitTimeout))
+	assert.JSONEq(t, `{"error": "battleye: timeout"}`, stdout.String())
+	assert.Equal(t, "berc: battleye: timeout\n", stderr.String())
+}
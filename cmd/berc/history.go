@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxHistory is the number of history entries kept.
+	maxHistory = 1000
+
+	// historyFile is the name of the history file in the home directory of the user.
+	historyFile = ".berc_history"
+)
+
+// history is a term.History which persists entries by appending them to a file.
+type history struct {
+	path    string
+	entries []string
+}
+
+// defaultHistoryPath returns the path of the history file in the home directory of the user or
+// an empty string if it is unknown.
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFile)
+}
+
+// loadHistory returns a new history persisted to path loaded with the last entries of the file.
+// If path is empty the history is not persisted.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close() // nolint: errcheck
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		h.push(redact(s.Text()))
+	}
+
+	// The file only grows while in use, so compact it to the loaded entries, which also removes
+	// passwords written by older versions.
+	h.rewrite()
+
+	return h
+}
+
+// Add implements term.History. The password of .connect entries is not recorded.
+func (h *history) Add(entry string) {
+	entry = redact(entry)
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.push(entry)
+	h.append(entry)
+}
+
+// Len implements term.History.
+func (h *history) Len() int {
+	return len(h.entries)
+}
+
+// At implements term.History. Index 0 is the most recent entry.
+func (h *history) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// redact returns entry without the password argument if it is a .connect meta-command, so
+// that it isn't written to disk in plaintext. Recalling the entry prompts for the password.
+func redact(entry string) string {
+	fields := strings.Fields(entry)
+	if len(fields) > 2 && fields[0] == ".connect" {
+		return strings.Join(fields[:2], " ")
+	}
+	return entry
+}
+
+// push adds entry to the in-memory entries.
+func (h *history) push(entry string) {
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+}
+
+// append appends entry to the history file. Errors are ignored as history is not essential.
+func (h *history) append(entry string) {
+	if h.path == "" {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close() // nolint: errcheck
+
+	f.WriteString(entry + "\n") // nolint: errcheck
+}
+
+// rewrite replaces the history file with the in-memory entries.
+func (h *history) rewrite() {
+	f, err := os.OpenFile(h.path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close() // nolint: errcheck
+
+	w := bufio.NewWriter(f)
+	for _, e := range h.entries {
+		w.WriteString(e + "\n") // nolint: errcheck
+	}
+	w.Flush() // nolint: errcheck
+}
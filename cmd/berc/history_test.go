@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), historyFile)
+
+	h := loadHistory(path)
+	assert.Equal(t, 0, h.Len())
+
+	h.Add("players")
+	h.Add("players")
+	h.Add("")
+	h.Add("admins")
+	if !assert.Equal(t, 2, h.Len()) {
+		return
+	}
+	assert.Equal(t, "admins", h.At(0))
+	assert.Equal(t, "players", h.At(1))
+
+	// The history persists.
+	h = loadHistory(path)
+	if !assert.Equal(t, 2, h.Len()) {
+		return
+	}
+	assert.Equal(t, "admins", h.At(0))
+}
+
+func TestHistoryLimit(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), historyFile)
+
+	h := loadHistory(path)
+	for i := 0; i < maxHistory+10; i++ {
+		h.Add(fmt.Sprint("say -1 ", i))
+	}
+	assert.Equal(t, maxHistory, h.Len())
+
+	// Loading compacts the file to the kept entries.
+	h = loadHistory(path)
+	assert.Equal(t, maxHistory, h.Len())
+	assert.Equal(t, fmt.Sprint("say -1 ", maxHistory+9), h.At(0))
+
+	b, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, maxHistory, strings.Count(string(b), "\n"))
+	}
+}
+
+func TestHistoryNotPersisted(t *testing.T) {
+	t.Parallel()
+
+	h := loadHistory("")
+	h.Add("players")
+	assert.Equal(t, 1, h.Len())
+}
+
+func TestHistoryRedactsPasswords(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), historyFile)
+	assert.NoError(t, os.WriteFile(path, []byte(".connect 127.0.0.1:2301 old-secret\n"), 0600))
+
+	h := loadHistory(path)
+	h.Add(".connect 127.0.0.1:2302 secret")
+	h.Add(".connect prod1")
+	h.Add("say -1 .connect a b")
+	if !assert.Equal(t, 4, h.Len()) {
+		return
+	}
+	assert.Equal(t, ".connect 127.0.0.1:2302", h.At(2))
+	assert.Equal(t, ".connect prod1", h.At(1))
+	assert.Equal(t, "say -1 .connect a b", h.At(0))
+
+	b, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(b), "secret")
+	}
+}
@@ -0,0 +1,215 @@
+// Command berc is a BattlEye RCON client.
+//
+// Usage:
+//
+//	berc [shell] [flags]
+//
+// The shell command, which is the default, starts an interactive session. Lines are executed as
+// RCON commands, server messages are printed as they arrive and lines starting with a dot are
+// meta-commands, see .help.
+//
+// The exec command executes a single command for use in scripts:
+//
+//	berc exec [flags] command [arguments]
+//
+// With -json the result is printed as JSON, parsed into typed results for the players, admins,
+// missions and bans commands. The exit code is 3 if the login failed, 4 on timeout and 5 if the
+// command failed.
+//
+// The tail command prints server messages as they arrive, reconnecting if the connection is lost:
+//
+//	berc tail [flags]
+//
+// Messages can be filtered by event type, regular expression and player name, and printed as
+// plain text, JSON Lines or logfmt.
+//
+// The dash command shows a full-screen dashboard of a server with the player table, the ban list,
+// server messages and a command input line:
+//
+//	berc dash [flags]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/config"
+)
+
+const (
+	// passwordEnv is the environment variable the RCON password is read from if not set by flag.
+	passwordEnv = "BERC_PASSWORD"
+)
+
+// Exit codes.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitLoginFailed  = 3
+	exitTimeout      = 4
+	exitCommandError = 5
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command given by args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cmd := "shell"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "shell":
+		return runShell(args, stdin, stdout, stderr)
+	case "exec":
+		return runExec(args, stdout, stderr)
+	case "tail":
+		return runTail(args, stdout, stderr)
+	case "dash":
+		return runDash(args, stderr)
+	case "help":
+		usage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "berc: unknown command %q\n", cmd)
+		usage(stderr)
+		return exitUsage
+	}
+}
+
+// usage prints the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: berc <command> [flags]
+
+Commands:
+  shell   start an interactive session (default)
+  exec    execute a single command and print its result
+  tail    print server messages as they arrive
+  dash    show a full-screen dashboard of a server
+  help    print this help
+
+Run berc <command> -h for the flags of a command.
+`)
+}
+
+// target is a server to connect to.
+type target struct {
+	address  string
+	password string
+	options  []battleye.Option
+}
+
+// connFlags are the flags used to connect to a server.
+type connFlags struct {
+	fs         *flag.FlagSet
+	address    string
+	password   string
+	timeout    time.Duration
+	server     string
+	configPath string
+}
+
+// register registers the flags on fs.
+func (cf *connFlags) register(fs *flag.FlagSet) {
+	cf.fs = fs
+	fs.StringVar(&cf.address, "address", "", "BattlEye RCON server address in host:port form")
+	fs.StringVar(&cf.password, "password", "", "RCON password, defaults to $"+passwordEnv)
+	fs.DurationVar(&cf.timeout, "timeout", 2*time.Second, "read / write timeout")
+	fs.StringVar(&cf.server, "server", "", "name of the server profile to connect to, instead of -address")
+	fs.StringVar(&cf.configPath, "config", config.DefaultPath(), "server profiles file")
+}
+
+// target returns the server set by the flags, or nil if neither -address nor -server is set.
+func (cf *connFlags) target() (*target, error) {
+	if cf.server != "" {
+		return cf.profile(cf.server)
+	}
+	if cf.address == "" {
+		return nil, nil
+	}
+	return &target{address: cf.address, password: cf.pwd(), options: cf.options()}, nil
+}
+
+// profile returns the server profile called name. The connection flags which are set explicitly
+// override the profile.
+func (cf *connFlags) profile(name string) (*target, error) {
+	cfg, err := config.Load(cf.configPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := cfg.Server(name)
+	if err != nil {
+		return nil, fmt.Errorf("%v %q", err, name)
+	}
+	pwd, err := s.ResolvePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &target{address: s.Address, password: pwd, options: s.Options()}
+	cf.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "address":
+			t.address = cf.address
+		case "password":
+			t.password = cf.password
+		case "timeout":
+			t.options = append(t.options, battleye.Timeout(cf.timeout))
+		}
+	})
+	return t, nil
+}
+
+// options returns the Client options set by the flags.
+func (cf *connFlags) options() []battleye.Option {
+	return []battleye.Option{battleye.Timeout(cf.timeout)}
+}
+
+// pwd returns the password set by flag or environment variable.
+func (cf *connFlags) pwd() string {
+	if cf.password != "" {
+		return cf.password
+	}
+	return os.Getenv(passwordEnv)
+}
+
+// parseArgs parses args with fs allowing flags after positional arguments, as in
+// "berc exec -server prod1 players -json". Arguments after the first positional one which are
+// not flags defined by fs, e.g. the -1 in "say -1 hello", are kept as positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		for len(args) > 0 && !isFlag(fs, args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return positional, nil
+		}
+	}
+}
+
+// isFlag returns true if arg is a flag defined by fs.
+func isFlag(fs *flag.FlagSet, arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	name := strings.TrimLeft(arg, "-")
+	if i := strings.IndexByte(name, '='); i != -1 {
+		name = name[:i]
+	}
+	return fs.Lookup(name) != nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name      string
+		args      []string
+		exp       []string
+		expJSON   bool
+		expServer string
+	}{
+		{
+			name:      "Flags first",
+			args:      []string{"-server", "prod1", "-json", "players"},
+			exp:       []string{"players"},
+			expJSON:   true,
+			expServer: "prod1",
+		},
+		{
+			name:      "Interspersed flags",
+			args:      []string{"--server", "prod1", "players", "--json"},
+			exp:       []string{"players"},
+			expJSON:   true,
+			expServer: "prod1",
+		},
+		{
+			name: "Arguments looking like flags",
+			args: []string{"say", "-1", "hello", "-everyone"},
+			exp:  []string{"say", "-1", "hello", "-everyone"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			var cf connFlags
+			cf.register(fs)
+			asJSON := fs.Bool("json", false, "")
+
+			args, err := parseArgs(fs, tc.args)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, args)
+			assert.Equal(t, tc.expJSON, *asJSON)
+			assert.Equal(t, tc.expServer, cf.server)
+		})
+	}
+}
+
+func TestConnFlagsTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	err := os.WriteFile(path, []byte("servers:\n  prod1:\n    address: 192.168.1.102:2301\n    password: secret\n    timeout: 5s\n"), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Setenv(passwordEnv, "from env")
+
+	testcases := []struct {
+		name        string
+		args        []string
+		expNil      bool
+		expErr      bool
+		expAddress  string
+		expPassword string
+		expOptions  int
+	}{
+		{
+			name:   "No target",
+			args:   []string{},
+			expNil: true,
+		},
+		{
+			name:        "Address",
+			args:        []string{"-address", "127.0.0.1:2301"},
+			expAddress:  "127.0.0.1:2301",
+			expPassword: "from env",
+			expOptions:  1,
+		},
+		{
+			name:        "Profile",
+			args:        []string{"-config", path, "-server", "prod1"},
+			expAddress:  "192.168.1.102:2301",
+			expPassword: "secret",
+			expOptions:  1,
+		},
+		{
+			name:        "Profile with overrides",
+			args:        []string{"-config", path, "-server", "prod1", "-password", "override", "-timeout", "1s"},
+			expAddress:  "192.168.1.102:2301",
+			expPassword: "override",
+			expOptions:  2,
+		},
+		{
+			name:   "Unknown profile",
+			args:   []string{"-config", path, "-server", "prod2"},
+			expErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			var cf connFlags
+			cf.register(fs)
+			if !assert.NoError(t, fs.Parse(tc.args)) {
+				return
+			}
+
+			target, err := cf.target()
+			if tc.expErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tc.expNil {
+				assert.Nil(t, target)
+				return
+			}
+			assert.Equal(t, tc.expAddress, target.address)
+			assert.Equal(t, tc.expPassword, target.password)
+			assert.Len(t, target.options, tc.expOptions)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	battleye "github.com/multiplay/go-battleye"
+	"golang.org/x/term"
+)
+
+const (
+	// shellHelp is printed by the .help meta-command.
+	shellHelp = `Lines are executed as RCON commands on the connected server.
+Meta-commands:
+  .connect [address|server] [password]
+                                 connect to a server address or profile, disconnecting from the
+                                 current one
+  .disconnect                    disconnect from the current server
+  .help                          print this help
+  .quit                          disconnect and exit
+`
+)
+
+// console reads lines from the user and writes output without corrupting the line being edited.
+type console interface {
+	io.Writer
+	ReadLine() (string, error)
+	ReadPassword(prompt string) (string, error)
+	SetPrompt(prompt string)
+}
+
+// plainConsole is a console used if the input is not a terminal.
+type plainConsole struct {
+	s  *bufio.Scanner
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write implements io.Writer.
+func (c *plainConsole) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.w.Write(b)
+}
+
+// ReadLine implements console.
+func (c *plainConsole) ReadLine() (string, error) {
+	if !c.s.Scan() {
+		if err := c.s.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return c.s.Text(), nil
+}
+
+// ReadPassword implements console.
+func (c *plainConsole) ReadPassword(string) (string, error) {
+	return c.ReadLine()
+}
+
+// SetPrompt implements console. Plain consoles have no prompt.
+func (c *plainConsole) SetPrompt(string) {}
+
+// newConsole returns a console reading from stdin. If stdin is a terminal it is put into raw mode
+// for line editing, restore must be called to return it to its original state.
+func newConsole(stdin io.Reader, stdout io.Writer, h *history, complete func(string, int, rune) (string, int, bool)) (c console, restore func(), err error) {
+	f, ok := stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return &plainConsole{s: bufio.NewScanner(stdin), w: stdout}, func() {}, nil
+	}
+
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{stdin, stdout}, "")
+	if width, height, err := term.GetSize(fd); err == nil {
+		t.SetSize(width, height) // nolint: errcheck
+	}
+	t.History = h
+	t.AutoCompleteCallback = complete
+
+	return t, func() { term.Restore(fd, state) }, nil // nolint: errcheck
+}
+
+// shell is an interactive RCON session.
+type shell struct {
+	con    console
+	flags  connFlags
+	addr   string
+	client *battleye.Client
+	roster *battleye.Roster
+}
+
+// runShell runs the shell command.
+func runShell(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sh := &shell{}
+	sh.flags.register(fs)
+	historyPath := fs.String("history", defaultHistoryPath(), "history file, empty disables persistent history")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	con, restore, err := newConsole(stdin, stdout, loadHistory(*historyPath), sh.complete)
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	defer restore()
+	sh.con = con
+	sh.setPrompt()
+
+	t, err := sh.flags.target()
+	if err != nil {
+		fmt.Fprintln(sh.con, "error:", err)
+	} else if t != nil {
+		sh.connect(t)
+	}
+
+	return sh.loop()
+}
+
+// loop reads and executes lines until the user quits.
+func (sh *shell) loop() int {
+	defer sh.disconnect()
+
+	for {
+		line, err := sh.con.ReadLine()
+		if err == io.EOF {
+			return exitOK
+		} else if err != nil {
+			fmt.Fprintln(sh.con, "error:", err)
+			return exitError
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "."):
+			if sh.meta(line) {
+				return exitOK
+			}
+		default:
+			sh.exec(line)
+		}
+	}
+}
+
+// meta executes a meta-command and returns true if the shell should exit.
+func (sh *shell) meta(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ".connect":
+		t, err := sh.connectTarget(fields[1:])
+		if err != nil {
+			fmt.Fprintln(sh.con, "error:", err)
+			return false
+		}
+		if t == nil {
+			fmt.Fprintln(sh.con, "usage: .connect [address|server] [password]")
+			return false
+		}
+		if t.password == "" {
+			if t.password, err = sh.con.ReadPassword("Password: "); err != nil {
+				return false
+			}
+		}
+		sh.connect(t)
+	case ".disconnect":
+		sh.disconnect()
+	case ".help":
+		fmt.Fprint(sh.con, shellHelp)
+	case ".quit", ".exit":
+		return true
+	default:
+		fmt.Fprintf(sh.con, "unknown meta-command %v, see .help\n", fields[0])
+	}
+	return false
+}
+
+// connectTarget returns the server to connect to given the arguments of .connect, which is
+// either an address, with the other connection flags applied, or the name of a server profile.
+func (sh *shell) connectTarget(args []string) (*target, error) {
+	if len(args) == 0 {
+		return sh.flags.target()
+	}
+
+	var t *target
+	if strings.Contains(args[0], ":") {
+		t = &target{address: args[0], password: sh.flags.pwd(), options: sh.flags.options()}
+	} else {
+		var err error
+		if t, err = sh.flags.profile(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) > 1 {
+		t.password = args[1]
+	}
+	return t, nil
+}
+
+// exec executes cmd on the connected server and prints the response.
+func (sh *shell) exec(cmd string) {
+	if sh.client == nil {
+		fmt.Fprintln(sh.con, "not connected, see .help")
+		return
+	}
+
+	resp, err := sh.client.Exec(cmd)
+	if err != nil {
+		fmt.Fprintln(sh.con, "error:", err)
+		return
+	}
+	if resp != "" {
+		fmt.Fprintln(sh.con, strings.TrimRight(resp, "\n"))
+	}
+}
+
+// connect connects to t, replacing the current connection.
+func (sh *shell) connect(t *target) {
+	sh.disconnect()
+
+	addr := t.address
+	fmt.Fprintf(sh.con, "connecting to %v...\n", addr)
+	c, err := battleye.NewClient(addr, t.password, t.options...)
+	if err != nil {
+		fmt.Fprintln(sh.con, "error:", err)
+		return
+	}
+	sh.client, sh.addr = c, addr
+
+	// The roster is only used for completion, so the shell works without it.
+	if r, err := battleye.NewRoster(c); err == nil {
+		sh.roster = r
+	}
+
+	c.OnMessage(sh.printMessage)
+
+	fmt.Fprintf(sh.con, "connected to %v\n", addr)
+	sh.setPrompt()
+}
+
+// disconnect closes the current connection if any.
+func (sh *shell) disconnect() {
+	if sh.client == nil {
+		return
+	}
+
+	if sh.roster != nil {
+		sh.roster.Close()
+		sh.roster = nil
+	}
+	if err := sh.client.Close(); err != nil {
+		fmt.Fprintln(sh.con, "error:", err)
+	}
+	sh.client = nil
+
+	fmt.Fprintln(sh.con, "disconnected")
+	sh.setPrompt()
+}
+
+// printMessage prints the server message m.
+func (sh *shell) printMessage(m battleye.Message) {
+	fmt.Fprintln(sh.con, m.Event.Raw())
+}
+
+// setPrompt sets the prompt according to the connection state.
+func (sh *shell) setPrompt() {
+	if sh.client == nil {
+		sh.con.SetPrompt("berc> ")
+		return
+	}
+	sh.con.SetPrompt("berc " + sh.addr + "> ")
+}
+
+// complete is the terminal auto-complete callback completing on tab.
+func (sh *shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	var ids []int
+	if sh.roster != nil {
+		for _, p := range sh.roster.Players() {
+			ids = append(ids, p.ID)
+		}
+	}
+	return complete(line, pos, ids)
+}
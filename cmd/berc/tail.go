@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+)
+
+const (
+	// maxReconnectDelay is the maximum delay between reconnection attempts.
+	maxReconnectDelay = 30 * time.Second
+)
+
+var (
+	// formats are the output formats of tail by name.
+	formats = map[string]func(w io.Writer, t time.Time, e event.Event, timestamps bool) error{
+		"plain":  writePlain,
+		"json":   writeJSONLine,
+		"logfmt": writeLogfmt,
+	}
+)
+
+// field is a key-value pair describing an event.
+type field struct {
+	key   string
+	value interface{}
+}
+
+// tailFilter selects the events printed by tail.
+type tailFilter struct {
+	types  map[string]bool
+	match  *regexp.Regexp
+	player string
+}
+
+// newTailFilter returns a tailFilter from the flag values.
+func newTailFilter(types, match, player string) (*tailFilter, error) {
+	f := &tailFilter{player: player}
+	if types != "" {
+		f.types = make(map[string]bool)
+		for _, t := range strings.Split(types, ",") {
+			f.types[strings.TrimSpace(t)] = true
+		}
+	}
+	if match != "" {
+		var err error
+		if f.match, err = regexp.Compile(match); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// accept returns true if e passes every filter.
+func (f *tailFilter) accept(e event.Event) bool {
+	if f.types != nil && !f.types[event.TypeOf(e)] {
+		return false
+	}
+	if f.match != nil && !f.match.MatchString(e.Raw()) {
+		return false
+	}
+	if f.player != "" && !strings.EqualFold(f.player, event.PlayerName(e)) {
+		return false
+	}
+	return true
+}
+
+// runTail runs the tail command.
+func runTail(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var cf connFlags
+	cf.register(fs)
+	types := fs.String("type", "", "comma separated event types to print: chat, player_connected, player_guid, player_verified, player_disconnected, player_kicked, admin_logged_in, unknown")
+	match := fs.String("match", "", "only print messages matching the regular expression")
+	player := fs.String("player", "", "only print events of the player with this name")
+	format := fs.String("format", "plain", "output format: plain, json (JSON Lines) or logfmt")
+	timestamps := fs.Bool("timestamps", false, "prefix plain output with the time the message was received")
+	check := fs.Duration("check", 30*time.Second, "interval of checking the connection")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	target, err := cf.target()
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitError
+	}
+	if target == nil {
+		fmt.Fprintln(stderr, "berc: -address or -server is required")
+		return exitUsage
+	}
+	write, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "berc: unknown format %q\n", *format)
+		return exitUsage
+	}
+	filter, err := newTailFilter(*types, *match, *player)
+	if err != nil {
+		fmt.Fprintln(stderr, "berc:", err)
+		return exitUsage
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	t := &tail{
+		target: target,
+		filter: filter,
+		write: func(m battleye.Message) error {
+			return write(stdout, m.Time, m.Event, *timestamps)
+		},
+		check:     *check,
+		stderr:    stderr,
+		interrupt: interrupt,
+	}
+	return t.run()
+}
+
+// tail streams server messages, reconnecting when the connection is lost.
+type tail struct {
+	target    *target
+	filter    *tailFilter
+	write     func(m battleye.Message) error
+	check     time.Duration
+	stderr    io.Writer
+	interrupt <-chan os.Signal
+}
+
+// run connects and streams until interrupted and returns the exit code.
+func (t *tail) run() int {
+	delay := time.Second
+	for {
+		c, err := battleye.NewClient(t.target.address, t.target.password, t.target.options...)
+		if err == battleye.ErrLoginFailed {
+			fmt.Fprintln(t.stderr, "berc:", err)
+			return exitLoginFailed
+		} else if err != nil {
+			fmt.Fprintf(t.stderr, "berc: %v, retrying in %v\n", err, delay)
+			select {
+			case <-t.interrupt:
+				return exitOK
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+			continue
+		}
+		delay = time.Second
+
+		// The roster lets the client attribute chat to players whose names contain ": ", so it's
+		// optional.
+		r, rerr := battleye.NewRoster(c)
+		err = t.stream(c)
+		if rerr == nil {
+			r.Close()
+		}
+		c.Close() // nolint: errcheck
+		if err == nil {
+			return exitOK
+		}
+		fmt.Fprintf(t.stderr, "berc: connection lost: %v, reconnecting\n", err)
+	}
+}
+
+// stream prints the messages received by c until the connection is lost, in which case the error
+// is returned, or until interrupted.
+func (t *tail) stream(c *battleye.Client) error {
+	// A dispatcher drops messages if the output falls behind, rather than stalling the client.
+	d, err := battleye.NewDispatcher(c)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	lost := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	msgs := make(chan battleye.Message)
+	d.OnMessage(func(m battleye.Message) {
+		select {
+		case <-stop:
+		case msgs <- m:
+		}
+	})
+	go func() {
+		tick := time.NewTicker(t.check)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				if _, err := c.Exec(""); err != nil {
+					lost <- err
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-t.interrupt:
+			return nil
+		case err := <-lost:
+			return err
+		case m := <-msgs:
+			if !t.filter.accept(m.Event) {
+				continue
+			}
+			if err := t.write(m); err != nil {
+				// Output is gone, e.g. a closed pipe, so there is no point in continuing.
+				return nil
+			}
+		}
+	}
+}
+
+// writePlain writes the raw message, prefixed with the time if timestamps is set.
+func writePlain(w io.Writer, t time.Time, e event.Event, timestamps bool) error {
+	var err error
+	if timestamps {
+		_, err = fmt.Fprintf(w, "%v %v\n", t.Format(time.RFC3339), e.Raw())
+	} else {
+		_, err = fmt.Fprintln(w, e.Raw())
+	}
+	return err
+}
+
+// writeJSONLine writes the event fields as a JSON object on a single line.
+func writeJSONLine(w io.Writer, t time.Time, e event.Event, _ bool) error {
+	m := make(map[string]interface{})
+	for _, f := range eventFields(t, e) {
+		m[f.key] = f.value
+	}
+	return json.NewEncoder(w).Encode(m)
+}
+
+// writeLogfmt writes the event fields as a logfmt line.
+func writeLogfmt(w io.Writer, t time.Time, e event.Event, _ bool) error {
+	var b strings.Builder
+	for i, f := range eventFields(t, e) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		v := fmt.Sprint(f.value)
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, isNotPrint) != -1 {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// isNotPrint returns true if r needs quoting in logfmt.
+func isNotPrint(r rune) bool {
+	return !strconv.IsPrint(r)
+}
+
+// eventFields returns the fields describing e received at t.
+func eventFields(t time.Time, e event.Event) []field {
+	fields := []field{{"time", t.Format(time.RFC3339Nano)}, {"type", event.TypeOf(e)}}
+	switch e := e.(type) {
+	case *event.Chat:
+		fields = append(fields, field{"channel", e.Channel.String()})
+		if e.Admin {
+			fields = append(fields, field{"admin", e.AdminID})
+		} else {
+			fields = append(fields, field{"name", e.Name})
+		}
+		fields = append(fields, field{"text", e.Text})
+	case *event.PlayerConnected:
+		fields = append(fields, field{"id", e.ID}, field{"name", e.Name}, field{"ip", e.IP.String()}, field{"port", e.Port})
+	case *event.PlayerGUID:
+		fields = append(fields, field{"id", e.ID}, field{"name", e.Name}, field{"guid", e.GUID})
+	case *event.PlayerVerified:
+		fields = append(fields, field{"id", e.ID}, field{"name", e.Name}, field{"guid", e.GUID})
+	case *event.PlayerDisconnected:
+		fields = append(fields, field{"id", e.ID}, field{"name", e.Name})
+	case *event.PlayerKicked:
+		fields = append(fields, field{"id", e.ID}, field{"name", e.Name}, field{"guid", e.GUID}, field{"reason", e.Reason})
+	case *event.AdminLoggedIn:
+		fields = append(fields, field{"id", e.ID}, field{"ip", e.IP.String()}, field{"port", e.Port})
+	}
+	return append(fields, field{"msg", e.Raw()})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailFilter(t *testing.T) {
+	t.Parallel()
+
+	chat := event.Parse("(Global) Kerry: hello")
+	connect := event.Parse("Player #1 Miller (10.0.0.2:2304) connected")
+	other := event.Parse("something else")
+
+	testcases := []struct {
+		name   string
+		types  string
+		match  string
+		player string
+		exp    []bool
+	}{
+		{
+			name: "No filter",
+			exp:  []bool{true, true, true},
+		},
+		{
+			name:  "Types",
+			types: "chat, unknown",
+			exp:   []bool{true, false, true},
+		},
+		{
+			name:  "Regexp",
+			match: `^Player #\d+`,
+			exp:   []bool{false, true, false},
+		},
+		{
+			name:   "Player",
+			player: "kerry",
+			exp:    []bool{true, false, false},
+		},
+		{
+			name:   "Combined",
+			types:  "chat",
+			player: "Miller",
+			exp:    []bool{false, false, false},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := newTailFilter(tc.types, tc.match, tc.player)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, []bool{f.accept(chat), f.accept(connect), f.accept(other)})
+		})
+	}
+
+	_, err := newTailFilter("", "(", "")
+	assert.Error(t, err)
+}
+
+func TestTailFormats(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := event.Parse(`(Side) Kerry: say "hi"`)
+
+	testcases := []struct {
+		format     string
+		timestamps bool
+		exp        string
+	}{
+		{
+			format: "plain",
+			exp:    "(Side) Kerry: say \"hi\"\n",
+		},
+		{
+			format:     "plain",
+			timestamps: true,
+			exp:        "2020-01-02T03:04:05Z (Side) Kerry: say \"hi\"\n",
+		},
+		{
+			format: "logfmt",
+			exp:    "time=2020-01-02T03:04:05Z type=chat channel=Side name=Kerry text=\"say \\\"hi\\\"\" msg=\"(Side) Kerry: say \\\"hi\\\"\"\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			var b bytes.Buffer
+			if assert.NoError(t, formats[tc.format](&b, ts, e, tc.timestamps)) {
+				assert.Equal(t, tc.exp, b.String())
+			}
+		})
+	}
+
+	var b bytes.Buffer
+	if assert.NoError(t, formats["json"](&b, ts, event.Parse("Player #1 Miller (10.0.0.2:2304) connected"), false)) {
+		assert.JSONEq(t, `{
+			"time": "2020-01-02T03:04:05Z",
+			"type": "player_connected",
+			"id": 1,
+			"name": "Miller",
+			"ip": "10.0.0.2",
+			"port": 2304,
+			"msg": "Player #1 Miller (10.0.0.2:2304) connected"
+		}`, b.String())
+	}
+}
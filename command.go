@@ -0,0 +1,93 @@
+package battleye
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxCommandLength is the maximum length of a command in bytes which fits in a single datagram
+	// alongside the IPv4, UDP and BattlEye command headers.
+	maxCommandLength = bufferSize - 20 - 8 - 9
+)
+
+// Command builds a command to be executed by ExecCommand from a verb and its arguments.
+//
+// The BattlEye protocol has no quoting or escaping, so arguments are validated instead:
+// words and integers must not contain whitespace, and free text, which the server reads until
+// the end of the command, must be the last argument. Control characters such as newlines and
+// NULs are rejected everywhere. The first invalid argument is reported by Build.
+type Command struct {
+	args []string
+	text bool
+	err  error
+}
+
+// NewCommand returns a new Command which executes verb.
+func NewCommand(verb string) *Command {
+	return (&Command{}).Word(verb)
+}
+
+// Word appends a single word argument.
+func (c *Command) Word(s string) *Command {
+	return c.add(s, s != "" && strings.IndexFunc(s, isSeparator) == -1)
+}
+
+// Int appends an integer argument.
+func (c *Command) Int(i int) *Command {
+	return c.add(strconv.Itoa(i), true)
+}
+
+// Text appends a free text argument, e.g. a message or a reason, which may contain spaces.
+// No other argument can follow it. Empty text is omitted.
+func (c *Command) Text(s string) *Command {
+	c.add(s, strings.IndexFunc(s, unicode.IsControl) == -1)
+	c.text = true
+	return c
+}
+
+// add appends arg if valid and no text argument has been added yet.
+func (c *Command) add(arg string, valid bool) *Command {
+	switch {
+	case c.err != nil:
+	case !valid || c.text:
+		c.err = ErrInvalidCommand
+	case arg != "":
+		c.args = append(c.args, arg)
+	}
+	return c
+}
+
+// Build returns the command as sent to the server or an error if it is invalid.
+func (c *Command) Build() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+
+	cmd := strings.Join(c.args, " ")
+	if len(cmd) > maxCommandLength {
+		return "", ErrCommandTooLong
+	}
+	return cmd, nil
+}
+
+// isSeparator returns true if r would split a command argument.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r)
+}
+
+// ExecCommand builds cmd and executes it the same way as Exec.
+func (c *Client) ExecCommand(cmd *Command) (string, error) {
+	return c.ExecCommandContext(context.Background(), cmd)
+}
+
+// ExecCommandContext builds cmd and executes it the same way as ExecContext.
+func (c *Client) ExecCommandContext(ctx context.Context, cmd *Command) (string, error) {
+	s, err := cmd.Build()
+	if err != nil {
+		return "", err
+	}
+	return c.ExecContext(ctx, s)
+}
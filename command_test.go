@@ -0,0 +1,127 @@
+package battleye
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/multiplay/go-battleye/internal/verb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		cmd    *Command
+		exp    string
+		expErr error
+	}{
+		{
+			name: "Verb only",
+			cmd:  NewCommand("players"),
+			exp:  "players",
+		},
+		{
+			name: "Word, int and text",
+			cmd:  NewCommand("ban").Int(3).Int(60).Text("Team killing, again"),
+			exp:  "ban 3 60 Team killing, again",
+		},
+		{
+			name: "Empty text is omitted",
+			cmd:  NewCommand("kick").Int(3).Text(""),
+			exp:  "kick 3",
+		},
+		{
+			name:   "Empty verb",
+			cmd:    NewCommand(""),
+			expErr: ErrInvalidCommand,
+		},
+		{
+			name:   "Word with whitespace",
+			cmd:    NewCommand("#mission").Word("co10 Escape"),
+			expErr: ErrInvalidCommand,
+		},
+		{
+			name:   "Text with newline",
+			cmd:    NewCommand("say").Int(-1).Text("hello\n#shutdown"),
+			expErr: ErrInvalidCommand,
+		},
+		{
+			name:   "Text with NUL",
+			cmd:    NewCommand("say").Int(-1).Text("hello\x00"),
+			expErr: ErrInvalidCommand,
+		},
+		{
+			name:   "Argument after text",
+			cmd:    NewCommand("say").Text("hello").Int(1),
+			expErr: ErrInvalidCommand,
+		},
+		{
+			name:   "Too long",
+			cmd:    NewCommand("say").Int(-1).Text(strings.Repeat("x", maxCommandLength)),
+			expErr: ErrCommandTooLong,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := tc.cmd.Build()
+			if tc.expErr != nil {
+				assert.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, cmd)
+		})
+	}
+}
+
+func TestClientCommandVerbs(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	// The verbs of the commands sent by the helpers are known, so that they aren't reported as
+	// other in metrics and spans. Only the commands sent matter, not the results.
+	c.Players()                                              // nolint: errcheck
+	c.Admins()                                               // nolint: errcheck
+	c.Bans()                                                 // nolint: errcheck
+	c.Ban(1, 60, "Spam")                                     // nolint: errcheck
+	c.AddBan("d41d8cd98f00b204e9800998ecf8427e", -1, "Hack") // nolint: errcheck
+	c.Kick(1, "AFK")                                         // nolint: errcheck
+	c.Broadcast("Restart")                                   // nolint: errcheck
+	c.Whisper(1, "Hi")                                       // nolint: errcheck
+	c.Missions()                                             // nolint: errcheck
+	c.SetMission("co10_Escape.Altis", Veteran)               // nolint: errcheck
+	c.RestartMission()                                       // nolint: errcheck
+	c.Reassign()                                             // nolint: errcheck
+	c.Lock()                                                 // nolint: errcheck
+	c.Unlock()                                               // nolint: errcheck
+	c.LoadScripts()                                          // nolint: errcheck
+	c.LoadEvents()                                           // nolint: errcheck
+	c.LoadBans()                                             // nolint: errcheck
+	c.MaxPing(200)                                           // nolint: errcheck
+	c.RestartServer(s.Addr)                                  // nolint: errcheck
+	c.Shutdown(s.Addr)                                       // nolint: errcheck
+
+	cmds := s.Commands()
+	assert.Len(t, cmds, 20)
+	for _, cmd := range cmds {
+		v := strings.ToLower(strings.SplitN(cmd, " ", 2)[0])
+		assert.Equal(t, v, verb.Bounded(v), cmd)
+	}
+}
@@ -0,0 +1,290 @@
+// Package config loads server profiles describing how to connect to BattlEye servers, so that
+// tools built on battleye.NewClient can share them.
+//
+// Profiles are described in YAML:
+//
+//	defaults:
+//	  timeout: 2s
+//	  keepalive: 30s
+//	servers:
+//	  prod1:
+//	    address: 192.168.1.102:2301
+//	    password_env: PROD1_RCON_PASSWORD
+//	    dialect: arma3
+//	  prod2:
+//	    address: 192.168.1.103:2301
+//	    password_file: /etc/berc/prod2.pwd
+//	    timeout: 5s
+//	groups:
+//	  eu: [prod1, prod2]
+//
+// Settings which are not set for a server are taken from defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PathEnv is the environment variable which overrides DefaultPath.
+	PathEnv = "BERC_CONFIG"
+)
+
+var (
+	// ErrUnknownServer is returned if a server is not defined.
+	ErrUnknownServer = errors.New("config: unknown server")
+
+	// ErrUnknownGroup is returned if a group is not defined.
+	ErrUnknownGroup = errors.New("config: unknown group")
+
+	// ErrNoAddress is returned if a server has no address.
+	ErrNoAddress = errors.New("config: no address")
+
+	// ErrPasswordSource is returned if a server has not exactly one password source.
+	ErrPasswordSource = errors.New("config: exactly one of password, password_env and password_file must be set")
+
+	// ErrUnknownDialect is returned if a server has an unknown dialect.
+	ErrUnknownDialect = errors.New("config: unknown dialect")
+)
+
+// Dialect is the game a server runs, which determines the commands and messages it supports.
+type Dialect string
+
+// Dialects.
+const (
+	DefaultDialect Dialect = ""
+	ArmA2          Dialect = "arma2"
+	ArmA3          Dialect = "arma3"
+	DayZ           Dialect = "dayz"
+)
+
+// Server is the profile of a server.
+type Server struct {
+	// Name is the name of the server in the configuration.
+	Name string `yaml:"-"`
+
+	// Address is the RCON address of the server in host:port form.
+	Address string `yaml:"address"`
+
+	// Password is the RCON password.
+	Password string `yaml:"password"`
+
+	// PasswordEnv is the environment variable the RCON password is read from.
+	PasswordEnv string `yaml:"password_env"`
+
+	// PasswordFile is the file the RCON password is read from. Surrounding whitespace is ignored.
+	PasswordFile string `yaml:"password_file"`
+
+	// Timeout is the read / write timeout, see battleye.Timeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// KeepAlive is the keep-alive interval, see battleye.KeepAlive.
+	KeepAlive time.Duration `yaml:"keepalive"`
+
+	// MessageBuffer is the size of the server message buffer, see battleye.MessageBuffer.
+	MessageBuffer int `yaml:"message_buffer"`
+
+	// Dialect is the game the server runs.
+	Dialect Dialect `yaml:"dialect"`
+}
+
+// Config is a set of server profiles.
+type Config struct {
+	// Defaults are the settings used for servers which do not set them.
+	Defaults Server `yaml:"defaults"`
+
+	// Servers are the server profiles by name.
+	Servers map[string]*Server `yaml:"servers"`
+
+	// Groups are lists of server names by group name.
+	Groups map[string][]string `yaml:"groups"`
+}
+
+// DefaultPath returns the path of the configuration file used if none is given, which is
+// $BERC_CONFIG if set or berc/servers.yaml in the user configuration directory.
+func DefaultPath() string {
+	if p := os.Getenv(PathEnv); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "berc", "servers.yaml")
+}
+
+// Load reads the configuration file at path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	return Parse(f)
+}
+
+// Parse reads a configuration from r, applies the defaults and validates it.
+func Parse(r io.Reader) (*Config, error) {
+	c := &Config{}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("config: %v", err)
+	}
+
+	for name, s := range c.Servers {
+		if s == nil {
+			s = &Server{}
+			c.Servers[name] = s
+		}
+		s.Name = name
+		s.applyDefaults(&c.Defaults)
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("%w: server %q", err, name)
+		}
+	}
+
+	for name, servers := range c.Groups {
+		for _, s := range servers {
+			if _, ok := c.Servers[s]; !ok {
+				return nil, fmt.Errorf("%w %q in group %q", ErrUnknownServer, s, name)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Server returns the server called name.
+func (c *Config) Server(name string) (*Server, error) {
+	s, ok := c.Servers[name]
+	if !ok {
+		return nil, ErrUnknownServer
+	}
+	return s, nil
+}
+
+// Group returns the servers of the group called name in the order they are listed.
+func (c *Config) Group(name string) ([]*Server, error) {
+	names, ok := c.Groups[name]
+	if !ok {
+		return nil, ErrUnknownGroup
+	}
+	servers := make([]*Server, len(names))
+	for i, n := range names {
+		servers[i] = c.Servers[n]
+	}
+	return servers, nil
+}
+
+// Names returns the names of the servers in alphabetical order.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Servers))
+	for n := range c.Servers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options returns the Client options set by s.
+func (s *Server) Options() []battleye.Option {
+	var opts []battleye.Option
+	if s.Timeout > 0 {
+		opts = append(opts, battleye.Timeout(s.Timeout))
+	}
+	if s.KeepAlive > 0 {
+		opts = append(opts, battleye.KeepAlive(s.KeepAlive))
+	}
+	if s.MessageBuffer > 0 {
+		opts = append(opts, battleye.MessageBuffer(s.MessageBuffer))
+	}
+	return opts
+}
+
+// ResolvePassword returns the RCON password from the password source of s.
+func (s *Server) ResolvePassword() (string, error) {
+	switch {
+	case s.PasswordEnv != "":
+		pwd, ok := os.LookupEnv(s.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("config: environment variable %v not set", s.PasswordEnv)
+		}
+		return pwd, nil
+	case s.PasswordFile != "":
+		b, err := os.ReadFile(s.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	default:
+		return s.Password, nil
+	}
+}
+
+// NewClient returns a new battleye.Client connected to s. The options are applied after the
+// ones set by s.
+func (s *Server) NewClient(options ...battleye.Option) (*battleye.Client, error) {
+	pwd, err := s.ResolvePassword()
+	if err != nil {
+		return nil, err
+	}
+	return battleye.NewClient(s.Address, pwd, append(s.Options(), options...)...)
+}
+
+// applyDefaults sets the settings of s which are not set from d.
+func (s *Server) applyDefaults(d *Server) {
+	if s.Address == "" {
+		s.Address = d.Address
+	}
+	if s.Password == "" && s.PasswordEnv == "" && s.PasswordFile == "" {
+		s.Password, s.PasswordEnv, s.PasswordFile = d.Password, d.PasswordEnv, d.PasswordFile
+	}
+	if s.Timeout == 0 {
+		s.Timeout = d.Timeout
+	}
+	if s.KeepAlive == 0 {
+		s.KeepAlive = d.KeepAlive
+	}
+	if s.MessageBuffer == 0 {
+		s.MessageBuffer = d.MessageBuffer
+	}
+	if s.Dialect == DefaultDialect {
+		s.Dialect = d.Dialect
+	}
+}
+
+// validate returns an error if s is invalid.
+func (s *Server) validate() error {
+	if s.Address == "" {
+		return ErrNoAddress
+	}
+
+	sources := 0
+	for _, v := range []string{s.Password, s.PasswordEnv, s.PasswordFile} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return ErrPasswordSource
+	}
+
+	switch s.Dialect {
+	case DefaultDialect, ArmA2, ArmA3, DayZ:
+		return nil
+	default:
+		return ErrUnknownDialect
+	}
+}
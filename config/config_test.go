@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+defaults:
+  timeout: 2s
+  keepalive: 30s
+  password_env: BERC_TEST_PASSWORD
+  dialect: arma3
+servers:
+  prod1:
+    address: 192.168.1.102:2301
+  prod2:
+    address: 192.168.1.103:2301
+    password: secret
+    timeout: 5s
+    message_buffer: 500
+    dialect: dayz
+groups:
+  eu: [prod2, prod1]
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	c, err := Parse(strings.NewReader(testConfig))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"prod1", "prod2"}, c.Names())
+
+	prod1, err := c.Server("prod1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &Server{
+		Name:        "prod1",
+		Address:     "192.168.1.102:2301",
+		PasswordEnv: "BERC_TEST_PASSWORD",
+		Timeout:     2 * time.Second,
+		KeepAlive:   30 * time.Second,
+		Dialect:     ArmA3,
+	}, prod1)
+	assert.Len(t, prod1.Options(), 2)
+
+	prod2, err := c.Server("prod2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &Server{
+		Name:          "prod2",
+		Address:       "192.168.1.103:2301",
+		Password:      "secret",
+		Timeout:       5 * time.Second,
+		KeepAlive:     30 * time.Second,
+		MessageBuffer: 500,
+		Dialect:       DayZ,
+	}, prod2)
+	assert.Len(t, prod2.Options(), 3)
+
+	eu, err := c.Group("eu")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*Server{prod2, prod1}, eu)
+	}
+
+	_, err = c.Server("prod3")
+	assert.Equal(t, ErrUnknownServer, err)
+	_, err = c.Group("us")
+	assert.Equal(t, ErrUnknownGroup, err)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		config string
+		expErr error
+	}{
+		{
+			name:   "No address",
+			config: "servers:\n  prod1:\n    password: secret\n",
+			expErr: ErrNoAddress,
+		},
+		{
+			name:   "No password",
+			config: "servers:\n  prod1:\n    address: 127.0.0.1:2301\n",
+			expErr: ErrPasswordSource,
+		},
+		{
+			name:   "Multiple passwords",
+			config: "servers:\n  prod1:\n    address: 127.0.0.1:2301\n    password: secret\n    password_env: PWD\n",
+			expErr: ErrPasswordSource,
+		},
+		{
+			name:   "Unknown dialect",
+			config: "servers:\n  prod1:\n    address: 127.0.0.1:2301\n    password: secret\n    dialect: quake\n",
+			expErr: ErrUnknownDialect,
+		},
+		{
+			name:   "Unknown server in group",
+			config: "servers:\n  prod1:\n    address: 127.0.0.1:2301\n    password: secret\ngroups:\n  eu: [prod2]\n",
+			expErr: ErrUnknownServer,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.config))
+			assert.True(t, errors.Is(err, tc.expErr), "unexpected error: %v", err)
+		})
+	}
+
+	_, err := Parse(strings.NewReader("servers:\n  prod1:\n    adress: typo\n"))
+	assert.Error(t, err)
+}
+
+func TestResolvePassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwd")
+	if !assert.NoError(t, os.WriteFile(path, []byte("from file\n"), 0600)) {
+		return
+	}
+	t.Setenv("BERC_TEST_PASSWORD", "from env")
+
+	testcases := []struct {
+		name   string
+		server Server
+		exp    string
+		expErr bool
+	}{
+		{name: "Literal", server: Server{Password: "literal"}, exp: "literal"},
+		{name: "Environment", server: Server{PasswordEnv: "BERC_TEST_PASSWORD"}, exp: "from env"},
+		{name: "Unset environment", server: Server{PasswordEnv: "BERC_TEST_UNSET"}, expErr: true},
+		{name: "File", server: Server{PasswordFile: path}, exp: "from file"},
+		{name: "Missing file", server: Server{PasswordFile: path + ".missing"}, expErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pwd, err := tc.server.ResolvePassword()
+			if tc.expErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.exp, pwd)
+			}
+		})
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(PathEnv, "/etc/berc.yaml")
+	assert.Equal(t, "/etc/berc.yaml", DefaultPath())
+}
@@ -0,0 +1,156 @@
+package battleye
+
+import (
+	"context"
+	"strings"
+)
+
+// Difficulty is the difficulty a mission is played on.
+type Difficulty string
+
+// Mission difficulties.
+const (
+	// DefaultDifficulty selects the difficulty configured on the server.
+	DefaultDifficulty Difficulty = ""
+	Recruit           Difficulty = "Recruit"
+	Regular           Difficulty = "Regular"
+	Veteran           Difficulty = "Veteran"
+	Custom            Difficulty = "Custom"
+)
+
+// Missions returns the names of the missions available on the server.
+func (c *Client) Missions() ([]string, error) {
+	return c.MissionsContext(context.Background())
+}
+
+// MissionsContext returns the names of the missions available on the server, executing the
+// command the same way as ExecContext.
+func (c *Client) MissionsContext(ctx context.Context) ([]string, error) {
+	resp, err := c.ExecContext(ctx, "missions")
+	if err != nil {
+		return nil, err
+	}
+	return parseMissions(resp), nil
+}
+
+// SetMission selects the mission identified by name and starts it on difficulty.
+func (c *Client) SetMission(name string, difficulty Difficulty) error {
+	return c.SetMissionContext(context.Background(), name, difficulty)
+}
+
+// SetMissionContext selects the mission identified by name and starts it on difficulty,
+// executing the command the same way as ExecContext.
+func (c *Client) SetMissionContext(ctx context.Context, name string, difficulty Difficulty) error {
+	cmd := NewCommand("#mission").Word(name)
+	if difficulty != DefaultDifficulty {
+		cmd.Word(string(difficulty))
+	}
+
+	s, err := cmd.Build()
+	if err != nil {
+		return ErrInvalidMission
+	}
+	return c.control(ctx, s)
+}
+
+// RestartMission restarts the current mission.
+func (c *Client) RestartMission() error {
+	return c.RestartMissionContext(context.Background())
+}
+
+// RestartMissionContext restarts the current mission, executing the command the same way as
+// ExecContext.
+func (c *Client) RestartMissionContext(ctx context.Context) error {
+	return c.control(ctx, "#restart")
+}
+
+// Reassign restarts the current mission and moves every player back to role selection.
+func (c *Client) Reassign() error {
+	return c.ReassignContext(context.Background())
+}
+
+// ReassignContext restarts the current mission and moves every player back to role selection,
+// executing the command the same way as ExecContext.
+func (c *Client) ReassignContext(ctx context.Context) error {
+	return c.control(ctx, "#reassign")
+}
+
+// Lock prevents new players from joining the server.
+func (c *Client) Lock() error {
+	return c.LockContext(context.Background())
+}
+
+// LockContext prevents new players from joining the server, executing the command the same way
+// as ExecContext.
+func (c *Client) LockContext(ctx context.Context) error {
+	return c.control(ctx, "#lock")
+}
+
+// Unlock allows new players to join the server.
+func (c *Client) Unlock() error {
+	return c.UnlockContext(context.Background())
+}
+
+// UnlockContext allows new players to join the server, executing the command the same way as
+// ExecContext.
+func (c *Client) UnlockContext(ctx context.Context) error {
+	return c.control(ctx, "#unlock")
+}
+
+// Shutdown shuts down the game server.
+// To prevent shutting down a server by accident, confirm must be the address the Client was
+// created with, exactly as it was passed to NewClient, e.g. "192.168.1.102:2301", otherwise
+// ErrNotConfirmed is returned and nothing is sent.
+func (c *Client) Shutdown(confirm string) error {
+	return c.ShutdownContext(context.Background(), confirm)
+}
+
+// ShutdownContext shuts down the game server, checking confirm the same way as Shutdown and
+// executing the command the same way as ExecContext.
+func (c *Client) ShutdownContext(ctx context.Context, confirm string) error {
+	if confirm != c.addr {
+		return ErrNotConfirmed
+	}
+	return c.control(ctx, "#shutdown")
+}
+
+// RestartServer restarts the game server.
+// As for Shutdown, confirm must be the address the Client was created with, exactly as it was
+// passed to NewClient, otherwise ErrNotConfirmed is returned and nothing is sent.
+func (c *Client) RestartServer(confirm string) error {
+	return c.RestartServerContext(context.Background(), confirm)
+}
+
+// RestartServerContext restarts the game server, checking confirm the same way as RestartServer
+// and executing the command the same way as ExecContext.
+func (c *Client) RestartServerContext(ctx context.Context, confirm string) error {
+	if confirm != c.addr {
+		return ErrNotConfirmed
+	}
+	return c.control(ctx, "#restartserver")
+}
+
+// control executes a server control command which the server does not reply to with content.
+func (c *Client) control(ctx context.Context, cmd string) error {
+	_, err := c.ExecContext(ctx, cmd)
+	return err
+}
+
+// parseMissions parses the response of the missions command.
+//
+// The response is in the form:
+//
+//	Missions on server:
+//	co10_Escape.Altis
+//	MP_COOP_m01.Stratis
+func parseMissions(resp string) []string {
+	var missions []string
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || isTableHeader(line) {
+			continue
+		}
+		missions = append(missions, line)
+	}
+	return missions
+}
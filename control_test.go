@@ -0,0 +1,117 @@
+package battleye
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMissions(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		resp string
+		exp  []string
+	}{
+		{
+			name: "No missions",
+			resp: "Missions on server:\n",
+		},
+		{
+			name: "Multiple missions",
+			resp: "Missions on server:\nco10_Escape.Altis\r\nMP_COOP_m01.Stratis\n",
+			exp:  []string{"co10_Escape.Altis", "MP_COOP_m01.Stratis"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, parseMissions(tc.resp))
+		})
+	}
+}
+
+func TestClientControl(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("missions", "Missions on server:\nco10_Escape.Altis")
+	missions, err := c.Missions()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"co10_Escape.Altis"}, missions)
+	}
+
+	assert.Equal(t, ErrInvalidMission, c.SetMission("", Regular))
+	assert.Equal(t, ErrInvalidMission, c.SetMission("co10 Escape", Regular))
+	assert.Equal(t, ErrNotConfirmed, c.Shutdown(""))
+	assert.Equal(t, ErrNotConfirmed, c.RestartServer("127.0.0.1:1"))
+
+	assert.NoError(t, c.SetMission("co10_Escape.Altis", Veteran))
+	assert.NoError(t, c.SetMission("co10_Escape.Altis", DefaultDifficulty))
+	assert.NoError(t, c.RestartMission())
+	assert.NoError(t, c.Reassign())
+	assert.NoError(t, c.Lock())
+	assert.NoError(t, c.Unlock())
+	assert.NoError(t, c.RestartServer(s.Addr))
+	assert.NoError(t, c.Shutdown(s.Addr))
+
+	assert.Equal(t, []string{
+		"missions",
+		"#mission co10_Escape.Altis Veteran",
+		"#mission co10_Escape.Altis",
+		"#restart",
+		"#reassign",
+		"#lock",
+		"#unlock",
+		"#restartserver",
+		"#shutdown",
+	}, s.Commands())
+}
+
+func TestClientControlContext(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.MissionsContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, c.SetMissionContext(ctx, "co10_Escape.Altis", Regular))
+	assert.Equal(t, context.Canceled, c.RestartMissionContext(ctx))
+	assert.Equal(t, context.Canceled, c.ReassignContext(ctx))
+	assert.Equal(t, context.Canceled, c.LockContext(ctx))
+	assert.Equal(t, context.Canceled, c.UnlockContext(ctx))
+	assert.Equal(t, context.Canceled, c.RestartServerContext(ctx, s.Addr))
+	assert.Equal(t, context.Canceled, c.ShutdownContext(ctx, s.Addr))
+
+	// The confirmation is checked first.
+	assert.Equal(t, ErrNotConfirmed, c.ShutdownContext(context.Background(), "127.0.0.1:1"))
+	assert.Equal(t, ErrNotConfirmed, c.RestartServerContext(context.Background(), ""))
+	assert.Empty(t, s.Commands())
+}
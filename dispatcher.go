@@ -0,0 +1,357 @@
+package battleye
+
+import (
+	"sync"
+	"time"
+
+	"github.com/multiplay/go-battleye/event"
+)
+
+// HandlerID identifies a handler registered with a Dispatcher.
+type HandlerID uint64
+
+// Message is a server message received by a Client.
+type Message struct {
+	// Time is the time the message was received.
+	Time time.Time
+
+	// Event is the message parsed into an event.
+	Event event.Event
+}
+
+// Source is a source of server messages, to which handlers can subscribe instead of
+// draining Client.Messages, so that many consumers can share a Client. *Client and
+// *Dispatcher implement it.
+type Source interface {
+	// OnMessage registers f to be called with every message.
+	OnMessage(f func(Message)) HandlerID
+
+	// Unregister removes the handler identified by id.
+	Unregister(id HandlerID) bool
+
+	// Done returns a channel which is closed when no more messages will be delivered.
+	Done() <-chan struct{}
+}
+
+var (
+	_ Source = (*Client)(nil)
+	_ Source = (*Dispatcher)(nil)
+)
+
+// handler is a handler registered with a Dispatcher.
+type handler struct {
+	id    HandlerID
+	match func(event.Event) bool
+	f     func(Message)
+}
+
+// Dispatcher parses the server messages received by a Client into events and calls the
+// handlers registered for them.
+//
+// By default handlers are called one at a time in the order the events were received and, for
+// the same event, in the order they were registered. A panicking handler does not affect the
+// other handlers.
+type Dispatcher struct {
+	client   *Client
+	queue    chan Message
+	blocking bool
+	sem      chan struct{}
+	onPanic  func(event.Event, interface{})
+	done     *done
+	wg       sync.WaitGroup
+
+	mu       sync.RWMutex
+	handlers []handler
+	nextID   HandlerID
+}
+
+// DispatcherOption is a Dispatcher configuration Option type.
+type DispatcherOption func(d *Dispatcher) error
+
+// DispatchBuffer sets the number of events which can wait to be handled. If the buffer is full,
+// new events are dropped, unless the Dispatcher is Blocking.
+func DispatchBuffer(size int) DispatcherOption {
+	return func(d *Dispatcher) error {
+		if size < 1 {
+			return ErrInvalidMessageBufferSize
+		}
+		d.queue = make(chan Message, size)
+		return nil
+	}
+}
+
+// Blocking makes the Client wait for room in the buffer instead of dropping events, so that no
+// event is lost. The Client doesn't acknowledge a message to the server, nor receive anything
+// else, until it's queued, so handlers must keep up and must not execute commands themselves.
+// Events queued when the Dispatcher is closed are handled before Close returns.
+func Blocking() DispatcherOption {
+	return func(d *Dispatcher) error {
+		d.blocking = true
+		return nil
+	}
+}
+
+// MaxConcurrency sets the maximum number of handlers which run concurrently. If n is greater
+// than one, handlers are no longer called in order.
+func MaxConcurrency(n int) DispatcherOption {
+	return func(d *Dispatcher) error {
+		if n < 1 {
+			return ErrInvalidConcurrency
+		}
+		if n > 1 {
+			d.sem = make(chan struct{}, n)
+		}
+		return nil
+	}
+}
+
+// PanicHandler sets a function which is called with the event and the recovered value if a
+// handler panics.
+func PanicHandler(f func(e event.Event, v interface{})) DispatcherOption {
+	return func(d *Dispatcher) error {
+		d.onPanic = f
+		return nil
+	}
+}
+
+// NewDispatcher returns a new Dispatcher attached to c.
+func NewDispatcher(c *Client, options ...DispatcherOption) (*Dispatcher, error) {
+	d := &Dispatcher{client: c, done: newDone()}
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+	if d.queue == nil {
+		d.queue = make(chan Message, defaultMessageBufferSize)
+	}
+
+	d.wg.Add(1)
+	go d.run()
+
+	c.dispatchersLock.Lock()
+	c.dispatchers = append(c.dispatchers, d)
+	c.dispatchersLock.Unlock()
+
+	return d, nil
+}
+
+// Close detaches d from its Client and waits for the running handlers to return.
+// Events which have not been handled yet are dropped, unless d is Blocking. Close must not be
+// called from a handler.
+func (d *Dispatcher) Close() {
+	// Stopping first releases the Client if it's waiting for room in a blocking queue.
+	d.done.Done()
+
+	c := d.client
+	c.dispatchersLock.Lock()
+	for i, v := range c.dispatchers {
+		if v == d {
+			c.dispatchers = append(c.dispatchers[:i], c.dispatchers[i+1:]...)
+			break
+		}
+	}
+	c.dispatchersLock.Unlock()
+
+	d.stop()
+}
+
+// On registers f to be called with every event for which match returns true.
+func (d *Dispatcher) On(match func(event.Event) bool, f func(event.Event)) HandlerID {
+	return d.register(match, func(m Message) { f(m.Event) })
+}
+
+// OnMessage registers f to be called with every message, which includes the time it was
+// received.
+func (d *Dispatcher) OnMessage(f func(Message)) HandlerID {
+	return d.register(func(event.Event) bool { return true }, f)
+}
+
+// register registers f to be called with every message whose event match returns true for.
+func (d *Dispatcher) register(match func(event.Event) bool, f func(Message)) HandlerID {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextID++
+	d.handlers = append(d.handlers, handler{id: d.nextID, match: match, f: f})
+	return d.nextID
+}
+
+// OnAny registers f to be called with every event.
+func (d *Dispatcher) OnAny(f func(event.Event)) HandlerID {
+	return d.On(func(event.Event) bool { return true }, f)
+}
+
+// OnChat registers f to be called with every chat message.
+func (d *Dispatcher) OnChat(f func(*event.Chat)) HandlerID {
+	return d.On(func(e event.Event) bool {
+		_, ok := e.(*event.Chat)
+		return ok
+	}, func(e event.Event) {
+		f(e.(*event.Chat))
+	})
+}
+
+// OnPlayerConnect registers f to be called when a player connects.
+func (d *Dispatcher) OnPlayerConnect(f func(*event.PlayerConnected)) HandlerID {
+	return d.On(func(e event.Event) bool {
+		_, ok := e.(*event.PlayerConnected)
+		return ok
+	}, func(e event.Event) {
+		f(e.(*event.PlayerConnected))
+	})
+}
+
+// OnPlayerDisconnect registers f to be called when a player disconnects.
+func (d *Dispatcher) OnPlayerDisconnect(f func(*event.PlayerDisconnected)) HandlerID {
+	return d.On(func(e event.Event) bool {
+		_, ok := e.(*event.PlayerDisconnected)
+		return ok
+	}, func(e event.Event) {
+		f(e.(*event.PlayerDisconnected))
+	})
+}
+
+// OnKick registers f to be called when a player is kicked.
+func (d *Dispatcher) OnKick(f func(*event.PlayerKicked)) HandlerID {
+	return d.On(func(e event.Event) bool {
+		_, ok := e.(*event.PlayerKicked)
+		return ok
+	}, func(e event.Event) {
+		f(e.(*event.PlayerKicked))
+	})
+}
+
+// Unregister removes the handler identified by id. It returns false if no such handler is
+// registered. A call of the handler which is already running is not interrupted.
+func (d *Dispatcher) Unregister(id HandlerID) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, h := range d.handlers {
+		if h.id == id {
+			d.handlers = append(d.handlers[:i:i], d.handlers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Done returns a channel which is closed when d is closed, or stopped by its Client being
+// closed.
+func (d *Dispatcher) Done() <-chan struct{} {
+	return d.done.C()
+}
+
+// dispatch queues m to be handled. If the queue is full m is dropped, unless d is blocking in
+// which case dispatch waits until there is room, or d or its Client is closed.
+func (d *Dispatcher) dispatch(m Message) {
+	if d.blocking {
+		select {
+		case d.queue <- m:
+		case <-d.done.C():
+		case <-d.client.done.C():
+		}
+		return
+	}
+
+	select {
+	case d.queue <- m:
+	default:
+	}
+}
+
+// stop stops handling events and waits for the running handlers to return.
+func (d *Dispatcher) stop() {
+	d.done.Done()
+	d.wg.Wait()
+}
+
+// run is a goroutine which handles the queued events.
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.done.C():
+			if d.blocking {
+				d.drain()
+			}
+			return
+		case m := <-d.queue:
+			d.handle(m)
+		}
+	}
+}
+
+// drain handles the queued messages.
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case m := <-d.queue:
+			d.handle(m)
+		default:
+			return
+		}
+	}
+}
+
+// handle calls the handlers matching m.
+func (d *Dispatcher) handle(m Message) {
+	d.mu.RLock()
+	handlers := d.handlers
+	d.mu.RUnlock()
+
+	for _, h := range handlers {
+		if !d.matches(h, m) {
+			continue
+		}
+
+		if d.sem == nil {
+			d.call(h, m)
+			continue
+		}
+
+		if d.blocking {
+			d.sem <- struct{}{}
+		} else {
+			select {
+			case <-d.done.C():
+				return
+			case d.sem <- struct{}{}:
+			}
+		}
+		d.wg.Add(1)
+		go func(h handler) {
+			defer func() {
+				<-d.sem
+				d.wg.Done()
+			}()
+			d.call(h, m)
+		}(h)
+	}
+}
+
+// matches returns true if h matches m, recovering from panics of its predicate, in which case
+// it doesn't match.
+func (d *Dispatcher) matches(h handler, m Message) bool {
+	defer d.recovered(m)
+	return h.match(m.Event)
+}
+
+// call calls h with m recovering from panics.
+func (d *Dispatcher) call(h handler, m Message) {
+	defer d.recovered(m)
+	h.f(m)
+}
+
+// recovered recovers from a panic handling m and reports it to the PanicHandler. It must be
+// deferred.
+func (d *Dispatcher) recovered(m Message) {
+	if v := recover(); v != nil && d.onPanic != nil {
+		d.onPanic(m.Event, v)
+	}
+}
@@ -0,0 +1,273 @@
+package battleye
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the raw messages of the events it handles.
+type recorder struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (r *recorder) record(e event.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, e.Raw())
+}
+
+func (r *recorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.msgs...)
+}
+
+func TestDispatcherOptions(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		opts   []DispatcherOption
+		expErr error
+	}{
+		{name: "Nil option", opts: []DispatcherOption{nil}, expErr: ErrNilOption},
+		{name: "Invalid buffer", opts: []DispatcherOption{DispatchBuffer(0)}, expErr: ErrInvalidMessageBufferSize},
+		{name: "Invalid concurrency", opts: []DispatcherOption{MaxConcurrency(0)}, expErr: ErrInvalidConcurrency},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewDispatcher(&Client{}, tc.opts...)
+			assert.Nil(t, d)
+			assert.EqualError(t, err, tc.expErr.Error())
+		})
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	c := &Client{}
+	panics := make(chan interface{}, 2)
+	d, err := NewDispatcher(c, PanicHandler(func(e event.Event, v interface{}) { panics <- v }))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer d.Close()
+
+	var any, chat, connect, disconnect, kick recorder
+	d.OnAny(any.record)
+	d.OnChat(func(e *event.Chat) { chat.record(e) })
+	d.OnPlayerConnect(func(e *event.PlayerConnected) { connect.record(e) })
+	d.OnPlayerDisconnect(func(e *event.PlayerDisconnected) { disconnect.record(e) })
+	d.OnKick(func(e *event.PlayerKicked) { kick.record(e) })
+	d.On(func(e event.Event) bool { return e.Raw() == "boom" }, func(event.Event) { panic("boom") })
+	d.On(func(e event.Event) bool {
+		if e.Raw() == "bad match" {
+			panic("bad match")
+		}
+		return false
+	}, func(event.Event) {})
+
+	msgs := []string{
+		"Player #1 Kerry (10.0.0.2:2304) connected",
+		"(Global) Kerry: hello",
+		"boom",
+		"bad match",
+		"Player #1 Kerry (-) has been kicked by BattlEye: Admin Kick",
+		"Player #1 Kerry disconnected",
+	}
+	for _, m := range msgs {
+		d.dispatch(Message{Event: event.Parse(m)})
+	}
+
+	assert.Eventually(t, func() bool { return len(any.recorded()) == len(msgs) }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, msgs, any.recorded())
+	assert.Equal(t, msgs[:1], connect.recorded())
+	assert.Equal(t, msgs[1:2], chat.recorded())
+	assert.Equal(t, msgs[4:5], kick.recorded())
+	assert.Equal(t, msgs[5:], disconnect.recorded())
+	assert.Equal(t, "boom", <-panics)
+
+	// Panics of predicates are recovered too, and don't stop the other handlers.
+	assert.Equal(t, "bad match", <-panics)
+}
+
+func TestDispatcherUnregister(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewDispatcher(&Client{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer d.Close()
+
+	var first, second recorder
+	id := d.OnAny(first.record)
+	d.OnAny(second.record)
+
+	d.dispatch(Message{Event: event.Parse("one")})
+	assert.Eventually(t, func() bool { return len(second.recorded()) == 1 }, time.Second, 10*time.Millisecond)
+
+	assert.True(t, d.Unregister(id))
+	assert.False(t, d.Unregister(id))
+
+	d.dispatch(Message{Event: event.Parse("two")})
+	assert.Eventually(t, func() bool { return len(second.recorded()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"one"}, first.recorded())
+}
+
+func TestDispatcherMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewDispatcher(&Client{}, MaxConcurrency(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var running, max, handled int32
+	d.OnAny(func(event.Event) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&handled, 1)
+	})
+
+	for i := 0; i < 6; i++ {
+		d.dispatch(Message{Event: event.Parse("msg")})
+	}
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&handled) == 6 }, time.Second, 10*time.Millisecond)
+	d.Close()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&max))
+}
+
+func TestClientDispatcher(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	d, err := NewDispatcher(c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	chats := make(chan *event.Chat, 1)
+	d.OnChat(func(e *event.Chat) { chats <- e })
+
+	s.SendServerMessage("(Side) Kerry: hello")
+	select {
+	case e := <-chats:
+		assert.Equal(t, "Kerry", e.Name)
+		assert.Equal(t, "hello", e.Text)
+	case <-time.After(time.Second):
+		assert.Fail(t, "chat not dispatched")
+	}
+
+	// Closing the Client stops its dispatchers.
+	assert.NoError(t, c.Close())
+	assert.True(t, d.done.IsDone())
+}
+
+func TestDispatcherBlocking(t *testing.T) {
+	t.Parallel()
+
+	c := &Client{done: newDone()}
+	d, err := NewDispatcher(c, DispatchBuffer(1), Blocking())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var handled recorder
+	release := make(chan struct{})
+	d.OnMessage(func(m Message) {
+		<-release
+		handled.record(m.Event)
+	})
+
+	// The first message is being handled and the second fills the buffer, so the third waits.
+	d.dispatch(Message{Event: event.Parse("one")})
+	d.dispatch(Message{Event: event.Parse("two")})
+	dispatched := make(chan struct{})
+	go func() {
+		d.dispatch(Message{Event: event.Parse("three")})
+		close(dispatched)
+	}()
+	select {
+	case <-dispatched:
+		assert.Fail(t, "message not blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-dispatched
+
+	// Queued messages are handled before Close returns.
+	d.Close()
+	assert.Equal(t, []string{"one", "two", "three"}, handled.recorded())
+	select {
+	case <-d.Done():
+	default:
+		assert.Fail(t, "dispatcher not done")
+	}
+}
+
+func TestClientOnMessage(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Many consumers share the messages of a Client.
+	first, second := make(chan Message, 1), make(chan Message, 1)
+	c.OnMessage(func(m Message) { first <- m })
+	id := c.OnMessage(func(m Message) { second <- m })
+
+	before := time.Now()
+	s.SendServerMessage("(Side) Kerry: hello")
+	for _, ch := range []chan Message{first, second} {
+		select {
+		case m := <-ch:
+			assert.Equal(t, "(Side) Kerry: hello", m.Event.Raw())
+			assert.False(t, m.Time.Before(before))
+		case <-time.After(time.Second):
+			assert.Fail(t, "message not handled")
+		}
+	}
+	assert.True(t, c.Unregister(id))
+	assert.False(t, c.Unregister(id))
+
+	assert.NoError(t, c.Close())
+	select {
+	case <-c.Done():
+	default:
+		assert.Fail(t, "client not done")
+	}
+}
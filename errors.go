@@ -32,6 +32,68 @@ var (
 	// ErrLoginFailed is returned by NewClient if it was unable to connect to the server due to auth failure.
 	ErrLoginFailed = errors.New("battleye: login failed")
 
+	// ErrUnexpectedResponse is returned if the response to a command cannot be parsed.
+	ErrUnexpectedResponse = errors.New("battleye: unexpected response")
+
+	// ErrInvalidPlayerID is returned if a player ID is negative.
+	ErrInvalidPlayerID = errors.New("battleye: invalid player id")
+
+	// ErrPlayerNotFound is returned if no player is connected with the given ID.
+	ErrPlayerNotFound = errors.New("battleye: player not found")
+
+	// ErrKickFailed is returned by Kick if the player is still connected after being kicked.
+	ErrKickFailed = errors.New("battleye: kick failed")
+
+	// ErrInvalidGUID is returned if a BattlEye GUID is not 32 hexadecimal digits.
+	ErrInvalidGUID = errors.New("battleye: invalid guid")
+
+	// ErrInvalidBanDuration is returned if a ban duration is neither positive nor PermanentBan.
+	ErrInvalidBanDuration = errors.New("battleye: invalid ban duration")
+
+	// ErrEmptyMessage is returned if a message is empty after removing control characters.
+	ErrEmptyMessage = errors.New("battleye: empty message")
+
+	// ErrMessageTooLong is returned if a message is longer than what the server can handle.
+	ErrMessageTooLong = errors.New("battleye: message too long")
+
+	// ErrInvalidMission is returned if a mission name is empty or contains whitespace.
+	ErrInvalidMission = errors.New("battleye: invalid mission")
+
+	// ErrNotConfirmed is returned by destructive commands if the confirmation does not match.
+	ErrNotConfirmed = errors.New("battleye: not confirmed")
+
+	// ErrInvalidPing is returned by MaxPing if the ping limit is not positive.
+	ErrInvalidPing = errors.New("battleye: invalid ping")
+
+	// ErrInvalidCommand is returned by Command.Build if an argument is invalid.
+	ErrInvalidCommand = errors.New("battleye: invalid command")
+
+	// ErrCommandTooLong is returned by Command.Build if the command does not fit in a single packet.
+	ErrCommandTooLong = errors.New("battleye: command too long")
+
+	// ErrInvalidNetwork is returned by TrustedAdminNetworks if a network is not a valid IP or CIDR.
+	ErrInvalidNetwork = errors.New("battleye: invalid network")
+
+	// ErrInvalidConcurrency is returned if MaxConcurrency Option is used with a limit less than 1.
+	ErrInvalidConcurrency = errors.New("battleye: invalid concurrency")
+
+	// ErrInvalidPollInterval is returned if PollInterval Option is used with a non-positive interval.
+	ErrInvalidPollInterval = errors.New("battleye: invalid poll interval")
+
 	// ErrTimeout is returned after the timeout period elapsed while waiting for response or error from the BattlEye server.
 	ErrTimeout = errors.New("battleye: timeout")
 )
+
+// CommandError is returned if the server reports that a command failed.
+type CommandError struct {
+	// Cmd is the command that failed.
+	Cmd string
+
+	// Msg is the message the server replied with.
+	Msg string
+}
+
+// Error implements error.
+func (e *CommandError) Error() string {
+	return "battleye: " + e.Cmd + ": " + e.Msg
+}
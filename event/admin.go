@@ -0,0 +1,33 @@
+package event
+
+import (
+	"net"
+	"regexp"
+)
+
+var (
+	adminLoggedInRegexp = regexp.MustCompile(`^RCon admin #(\d+) \((\S+):(\d+)\) logged in$`)
+)
+
+// AdminLoggedIn is sent when an RCON admin logs in.
+//
+//	RCon admin #1 (1.2.3.4:2306) logged in
+type AdminLoggedIn struct {
+	raw
+	ID   int
+	IP   net.IP
+	Port int
+}
+
+// parseAdminLoggedIn parses an AdminLoggedIn message.
+func parseAdminLoggedIn(msg string) Event {
+	m := adminLoggedInRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	ip := net.ParseIP(m[2])
+	if ip == nil {
+		return nil
+	}
+	return &AdminLoggedIn{ID: atoi(m[1]), IP: ip, Port: atoi(m[3])}
+}
@@ -0,0 +1,33 @@
+package event
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdminLoggedIn(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		msg string
+		exp Event
+	}{
+		{
+			msg: "RCon admin #1 (203.0.113.7:51234) logged in",
+			exp: &AdminLoggedIn{ID: 1, IP: net.ParseIP("203.0.113.7"), Port: 51234},
+		},
+		{
+			msg: "RCon admin #1 (invalid:51234) logged in",
+			exp: &Unknown{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.msg, func(t *testing.T) {
+			tc.exp.(rawSetter).setRaw(tc.msg)
+			assert.Equal(t, tc.exp, Parse(tc.msg))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Channel is the chat channel a message was sent on.
+type Channel int
+
+// Chat channels.
+const (
+	UnknownChannel Channel = iota
+	Global
+	Side
+	Command
+	Group
+	Vehicle
+	Direct
+)
+
+var (
+	// channelNames are the channel names used in chat messages.
+	channelNames = []string{
+		UnknownChannel: "Unknown",
+		Global:         "Global",
+		Side:           "Side",
+		Command:        "Command",
+		Group:          "Group",
+		Vehicle:        "Vehicle",
+		Direct:         "Direct",
+	}
+
+	// adminChatRegexp matches the prefix of chat messages sent by RCON admins.
+	adminChatRegexp = regexp.MustCompile(`^RCon admin #(\d+): `)
+)
+
+// String implements fmt.Stringer.
+func (c Channel) String() string {
+	if c < 0 || int(c) >= len(channelNames) {
+		return channelNames[UnknownChannel]
+	}
+	return channelNames[c]
+}
+
+// Chat is sent when a player or an RCON admin writes in the chat.
+// Name is empty if the message was sent by an RCON admin.
+//
+//	(Side) Name: text
+//	RCon admin #0: (Global) text
+type Chat struct {
+	raw
+	Channel Channel
+	Name    string
+	Text    string
+	Admin   bool
+	AdminID int
+}
+
+// parseChat parses a Chat message. If the sender name is ambiguous because the message
+// contains more than one ": ", the longest of names which fits is used, otherwise the name
+// ends at the first ": ".
+func parseChat(msg string, names []string) Event {
+	if m := adminChatRegexp.FindStringSubmatch(msg); m != nil {
+		e := &Chat{Admin: true, AdminID: atoi(m[1])}
+		e.Channel, e.Text = splitChannel(msg[len(m[0]):])
+		return e
+	}
+
+	ch, rest := splitChannel(msg)
+	if ch == UnknownChannel {
+		return nil
+	}
+
+	name := senderName(rest, names)
+	if name == "" {
+		return nil
+	}
+	return &Chat{Channel: ch, Name: name, Text: rest[len(name)+2:]}
+}
+
+// splitChannel splits the leading "(Channel) " from s.
+func splitChannel(s string) (Channel, string) {
+	if !strings.HasPrefix(s, "(") {
+		return UnknownChannel, s
+	}
+	i := strings.Index(s, ") ")
+	if i == -1 {
+		return UnknownChannel, s
+	}
+	for ch := Global; int(ch) < len(channelNames); ch++ {
+		if channelNames[ch] == s[1:i] {
+			return ch, s[i+2:]
+		}
+	}
+	return UnknownChannel, s
+}
+
+// senderName returns the sender name at the start of s, which is in the form "Name: text".
+func senderName(s string, names []string) string {
+	var name string
+	for _, n := range names {
+		if len(n) > len(name) && strings.HasPrefix(s, n+": ") {
+			name = n
+		}
+	}
+	if name != "" {
+		return name
+	}
+
+	i := strings.Index(s, ": ")
+	if i < 1 {
+		return ""
+	}
+	return s[:i]
+}
@@ -0,0 +1,107 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChat(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name  string
+		msg   string
+		names []string
+		exp   Event
+	}{
+		{
+			name: "Global",
+			msg:  "(Global) Dwarden: hello everyone",
+			exp:  &Chat{Channel: Global, Name: "Dwarden", Text: "hello everyone"},
+		},
+		{
+			name: "Side",
+			msg:  "(Side) Miller: contact, grid 045 112",
+			exp:  &Chat{Channel: Side, Name: "Miller", Text: "contact, grid 045 112"},
+		},
+		{
+			name: "Vehicle",
+			msg:  "(Vehicle) Kerry: get in",
+			exp:  &Chat{Channel: Vehicle, Name: "Kerry", Text: "get in"},
+		},
+		{
+			name: "Direct",
+			msg:  "(Direct) Kerry: psst",
+			exp:  &Chat{Channel: Direct, Name: "Kerry", Text: "psst"},
+		},
+		{
+			name: "Group",
+			msg:  "(Group) Kerry: on me",
+			exp:  &Chat{Channel: Group, Name: "Kerry", Text: "on me"},
+		},
+		{
+			name: "Command",
+			msg:  "(Command) Kerry: push north",
+			exp:  &Chat{Channel: Command, Name: "Kerry", Text: "push north"},
+		},
+		{
+			name: "Empty text",
+			msg:  "(Global) Kerry: ",
+			exp:  &Chat{Channel: Global, Name: "Kerry"},
+		},
+		{
+			name: "Name with parentheses",
+			msg:  "(Global) [TAG] Miller (Sniper): text: with colon",
+			exp:  &Chat{Channel: Global, Name: "[TAG] Miller (Sniper)", Text: "text: with colon"},
+		},
+		{
+			name:  "Name with colon resolved by known names",
+			msg:   "(Global) Sgt: Pepper: hello: world",
+			names: []string{"Sgt", "Sgt: Pepper", "Someone"},
+			exp:   &Chat{Channel: Global, Name: "Sgt: Pepper", Text: "hello: world"},
+		},
+		{
+			name:  "Unknown name falls back to first colon",
+			msg:   "(Global) Sgt: Pepper: hello",
+			names: []string{"Someone"},
+			exp:   &Chat{Channel: Global, Name: "Sgt", Text: "Pepper: hello"},
+		},
+		{
+			name: "RCON admin broadcast",
+			msg:  "RCon admin #0: (Global) server restart in 5 minutes",
+			exp:  &Chat{Channel: Global, Text: "server restart in 5 minutes", Admin: true},
+		},
+		{
+			name: "RCON admin message without channel",
+			msg:  "RCon admin #2: hello",
+			exp:  &Chat{Channel: UnknownChannel, Text: "hello", Admin: true, AdminID: 2},
+		},
+		{
+			name: "Unknown channel",
+			msg:  "(Lobby) Kerry: hello",
+			exp:  &Unknown{},
+		},
+		{
+			name: "Missing name",
+			msg:  "(Global) : hello",
+			exp:  &Unknown{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Parser{Names: func() []string { return tc.names }}
+			tc.exp.(rawSetter).setRaw(tc.msg)
+			assert.Equal(t, tc.exp, p.Parse(tc.msg))
+		})
+	}
+}
+
+func TestChannelString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Side", Side.String())
+	assert.Equal(t, "Unknown", UnknownChannel.String())
+	assert.Equal(t, "Unknown", Channel(42).String())
+}
@@ -0,0 +1,126 @@
+// Package event parses the messages a BattlEye server broadcasts to RCON clients, as returned by
+// Client.Messages, into typed events.
+package event
+
+// Event is a server message parsed by Parse.
+type Event interface {
+	// Raw returns the server message the Event was parsed from.
+	Raw() string
+}
+
+// raw holds the server message an Event was parsed from.
+type raw string
+
+// Raw implements Event.
+func (r raw) Raw() string {
+	return string(r)
+}
+
+// setRaw sets the server message an Event was parsed from.
+func (r *raw) setRaw(msg string) {
+	*r = raw(msg)
+}
+
+// rawSetter is implemented by every Event through the embedded raw.
+type rawSetter interface {
+	setRaw(msg string)
+}
+
+// Unknown is a server message which is not recognised by Parse.
+type Unknown struct {
+	raw
+}
+
+// parser parses msg and returns nil if it is not recognised.
+// The raw message of the returned Event is set by Parse.
+type parser func(msg string) Event
+
+// parsers are tried in order by Parse.
+var parsers = []parser{
+	parsePlayerKicked,
+	parsePlayerConnected,
+	parsePlayerDisconnected,
+	parsePlayerGUID,
+	parsePlayerVerified,
+	parseAdminLoggedIn,
+}
+
+// Parser parses server messages into Events.
+type Parser struct {
+	// Names, if set, returns the names of the players on the server. It is used to attribute
+	// chat messages to the right player if the name contains ": ".
+	Names func() []string
+}
+
+// Parse parses msg into an Event. Messages which are not recognised are returned as *Unknown.
+func (p *Parser) Parse(msg string) Event {
+	e := p.parse(msg)
+	if e == nil {
+		e = &Unknown{}
+	}
+	e.(rawSetter).setRaw(msg)
+	return e
+}
+
+// parse returns the Event msg is parsed into or nil if it is not recognised.
+func (p *Parser) parse(msg string) Event {
+	for _, f := range parsers {
+		if e := f(msg); e != nil {
+			return e
+		}
+	}
+
+	var names []string
+	if p.Names != nil {
+		names = p.Names()
+	}
+	return parseChat(msg, names)
+}
+
+// Parse parses msg into an Event using a Parser with no known player names.
+func Parse(msg string) Event {
+	return (&Parser{}).Parse(msg)
+}
+
+// TypeOf returns the name of the type of e in snake case, e.g. chat or player_connected, for use
+// in filters and structured output.
+func TypeOf(e Event) string {
+	switch e.(type) {
+	case *Chat:
+		return "chat"
+	case *PlayerConnected:
+		return "player_connected"
+	case *PlayerGUID:
+		return "player_guid"
+	case *PlayerVerified:
+		return "player_verified"
+	case *PlayerDisconnected:
+		return "player_disconnected"
+	case *PlayerKicked:
+		return "player_kicked"
+	case *AdminLoggedIn:
+		return "admin_logged_in"
+	default:
+		return "unknown"
+	}
+}
+
+// PlayerName returns the name of the player e is about or an empty string.
+func PlayerName(e Event) string {
+	switch e := e.(type) {
+	case *Chat:
+		return e.Name
+	case *PlayerConnected:
+		return e.Name
+	case *PlayerGUID:
+		return e.Name
+	case *PlayerVerified:
+		return e.Name
+	case *PlayerDisconnected:
+		return e.Name
+	case *PlayerKicked:
+		return e.Name
+	default:
+		return ""
+	}
+}
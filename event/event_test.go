@@ -0,0 +1,34 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeOf(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		msg     string
+		expType string
+		expName string
+	}{
+		{msg: "(Global) Kerry: hello", expType: "chat", expName: "Kerry"},
+		{msg: "Player #1 Miller (10.0.0.2:2304) connected", expType: "player_connected", expName: "Miller"},
+		{msg: "Player #1 Miller - BE GUID: d41d8cd98f00b204e9800998ecf8427e", expType: "player_guid", expName: "Miller"},
+		{msg: "Verified GUID (d41d8cd98f00b204e9800998ecf8427e) of player #1 Miller", expType: "player_verified", expName: "Miller"},
+		{msg: "Player #1 Miller disconnected", expType: "player_disconnected", expName: "Miller"},
+		{msg: "Player #1 Miller (d41d8cd98f00b204e9800998ecf8427e) has been kicked by BattlEye: Admin Kick", expType: "player_kicked", expName: "Miller"},
+		{msg: "RCon admin #1 (203.0.113.7:51234) logged in", expType: "admin_logged_in"},
+		{msg: "something else", expType: "unknown"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.msg, func(t *testing.T) {
+			e := Parse(tc.msg)
+			assert.Equal(t, tc.expType, TypeOf(e))
+			assert.Equal(t, tc.expName, PlayerName(e))
+		})
+	}
+}
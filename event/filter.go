@@ -0,0 +1,69 @@
+package event
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Filter is a loaded BattlEye filter file, e.g. scripts.txt, used to look up the filter line
+// which caused a RestrictionKick.
+type Filter struct {
+	// Name is the file name of the filter, e.g. scripts.txt.
+	Name string
+
+	lines []string
+}
+
+// ParseFilter reads the filter file called name from r.
+func ParseFilter(name string, r io.Reader) (*Filter, error) {
+	f := &Filter{Name: name}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		f.lines = append(f.lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// LoadFilter reads the filter file at path.
+func LoadFilter(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint: errcheck
+
+	return ParseFilter(filepath.Base(path), file)
+}
+
+// Line returns the filter line identified by restriction number n.
+// BattlEye numbers restrictions by their zero based line number in the file, so n is the line
+// shown as n+1 by a text editor.
+func (f *Filter) Line(n int) (string, bool) {
+	if n < 0 || n >= len(f.lines) {
+		return "", false
+	}
+	return f.lines[n], true
+}
+
+// Filters are loaded filter files by name.
+type Filters map[string]*Filter
+
+// Add adds f to fs.
+func (fs Filters) Add(f *Filter) {
+	fs[f.Name] = f
+}
+
+// Lookup returns the filter line which caused the RestrictionKick kr.
+func (fs Filters) Lookup(kr KickReason) (string, bool) {
+	f, ok := fs[kr.FilterFile()]
+	if !ok {
+		return "", false
+	}
+	return f.Line(kr.Number)
+}
@@ -0,0 +1,103 @@
+package event
+
+import (
+	"regexp"
+	"strings"
+)
+
+// KickType classifies the reason of a kick.
+type KickType int
+
+// Kick types.
+const (
+	// OtherKick is a kick reason which is not recognised.
+	OtherKick KickType = iota
+
+	// RestrictionKick is a kick by a BattlEye filter, e.g. Script Restriction #12.
+	RestrictionKick
+
+	// BanKick is a kick of a banned player, e.g. Global Ban #abc or Admin Ban.
+	BanKick
+
+	// AdminKick is a kick issued by an RCON admin.
+	AdminKick
+
+	// NotRespondingKick is a kick of a client which stopped responding to BattlEye.
+	NotRespondingKick
+)
+
+// BanType is the type of ban which caused a BanKick.
+type BanType int
+
+// Ban types.
+const (
+	NoBan BanType = iota
+	GlobalBan
+	AdminBan
+)
+
+var (
+	restrictionRegexp = regexp.MustCompile(`^(\w+) Restriction #(\d+)(?: (.*))?$`)
+	globalBanRegexp   = regexp.MustCompile(`^Global Ban #(\S+)$`)
+	adminActionRegexp = regexp.MustCompile(`^Admin (Ban|Kick)(?: \((.*)\))?$`)
+)
+
+// KickReason is a decoded kick reason.
+type KickReason struct {
+	// Type is the type of the kick.
+	Type KickType
+
+	// Restriction is the filter which caused a RestrictionKick, e.g. Script or CreateVehicle.
+	Restriction string
+
+	// Number is the restriction number of a RestrictionKick, which identifies the line of the filter
+	// file, see Filter.
+	Number int
+
+	// Ban is the type of ban which caused a BanKick.
+	Ban BanType
+
+	// BanID is the identifier of a GlobalBan.
+	BanID string
+
+	// Detail is the reason given by an admin or any additional text after a restriction.
+	Detail string
+
+	// Reason is the kick reason as sent by the server.
+	Reason string
+}
+
+// DecodeKickReason decodes the reason of a kick as found in PlayerKicked.
+func DecodeKickReason(reason string) KickReason {
+	kr := KickReason{Reason: reason}
+	if m := restrictionRegexp.FindStringSubmatch(reason); m != nil {
+		kr.Type, kr.Restriction, kr.Number, kr.Detail = RestrictionKick, m[1], atoi(m[2]), m[3]
+	} else if m := globalBanRegexp.FindStringSubmatch(reason); m != nil {
+		kr.Type, kr.Ban, kr.BanID = BanKick, GlobalBan, m[1]
+	} else if m := adminActionRegexp.FindStringSubmatch(reason); m != nil {
+		kr.Type, kr.Detail = AdminKick, m[2]
+		if m[1] == "Ban" {
+			kr.Type, kr.Ban = BanKick, AdminBan
+		}
+	} else if reason == "Client not responding" {
+		kr.Type = NotRespondingKick
+	}
+	return kr
+}
+
+// FilterFile returns the name of the filter file which caused a RestrictionKick, e.g.
+// scripts.txt for Script Restriction, or an empty string for other kicks.
+func (kr KickReason) FilterFile() string {
+	if kr.Type != RestrictionKick {
+		return ""
+	}
+	if kr.Restriction == "Script" {
+		return "scripts.txt"
+	}
+	return strings.ToLower(kr.Restriction) + ".txt"
+}
+
+// KickReason returns the decoded reason of the kick.
+func (e *PlayerKicked) KickReason() KickReason {
+	return DecodeKickReason(e.Reason)
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKickReason(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		reason  string
+		exp     KickReason
+		expFile string
+	}{
+		{
+			reason:  "Script Restriction #12",
+			exp:     KickReason{Type: RestrictionKick, Restriction: "Script", Number: 12},
+			expFile: "scripts.txt",
+		},
+		{
+			reason:  "CreateVehicle Restriction #3",
+			exp:     KickReason{Type: RestrictionKick, Restriction: "CreateVehicle", Number: 3},
+			expFile: "createvehicle.txt",
+		},
+		{
+			reason:  "RemoteExec Restriction #0 spawn",
+			exp:     KickReason{Type: RestrictionKick, Restriction: "RemoteExec", Number: 0, Detail: "spawn"},
+			expFile: "remoteexec.txt",
+		},
+		{
+			reason: "Global Ban #a1b2c3",
+			exp:    KickReason{Type: BanKick, Ban: GlobalBan, BanID: "a1b2c3"},
+		},
+		{
+			reason: "Admin Ban",
+			exp:    KickReason{Type: BanKick, Ban: AdminBan},
+		},
+		{
+			reason: "Admin Ban (Cheating)",
+			exp:    KickReason{Type: BanKick, Ban: AdminBan, Detail: "Cheating"},
+		},
+		{
+			reason: "Admin Kick (AFK (too long))",
+			exp:    KickReason{Type: AdminKick, Detail: "AFK (too long)"},
+		},
+		{
+			reason: "Client not responding",
+			exp:    KickReason{Type: NotRespondingKick},
+		},
+		{
+			reason: "Bad Player Name",
+			exp:    KickReason{Type: OtherKick},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.reason, func(t *testing.T) {
+			tc.exp.Reason = tc.reason
+			kr := DecodeKickReason(tc.reason)
+			assert.Equal(t, tc.exp, kr)
+			assert.Equal(t, tc.expFile, kr.FilterFile())
+		})
+	}
+}
+
+func TestFilters(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseFilter("scripts.txt", strings.NewReader("//new\n1 \"createDialog\"\n5 \"setDamage\" !=\"player setDamage 0\"\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	fs := Filters{}
+	fs.Add(f)
+
+	line, ok := fs.Lookup(DecodeKickReason("Script Restriction #2"))
+	assert.True(t, ok)
+	assert.Equal(t, `5 "setDamage" !="player setDamage 0"`, line)
+
+	_, ok = fs.Lookup(DecodeKickReason("Script Restriction #3"))
+	assert.False(t, ok)
+
+	_, ok = fs.Lookup(DecodeKickReason("CreateVehicle Restriction #1"))
+	assert.False(t, ok)
+
+	_, ok = fs.Lookup(DecodeKickReason("Admin Ban"))
+	assert.False(t, ok)
+
+	e := Parse("Player #1 Kerry (-) has been kicked by BattlEye: Script Restriction #1").(*PlayerKicked)
+	line, ok = fs.Lookup(e.KickReason())
+	assert.True(t, ok)
+	assert.Equal(t, `1 "createDialog"`, line)
+}
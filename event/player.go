@@ -0,0 +1,126 @@
+package event
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+)
+
+var (
+	playerConnectedRegexp    = regexp.MustCompile(`^Player #(\d+) (.+) \((\S+):(\d+)\) connected$`)
+	playerDisconnectedRegexp = regexp.MustCompile(`^Player #(\d+) (.+) disconnected$`)
+	playerGUIDRegexp         = regexp.MustCompile(`^Player #(\d+) (.+) - (?:BE |Legacy )?GUID: ([0-9a-fA-F]+)(?: \(unverified\))?$`)
+	playerVerifiedRegexp     = regexp.MustCompile(`^Verified GUID \(([0-9a-fA-F]+)\) of player #(\d+) (.+)$`)
+	playerKickedRegexp       = regexp.MustCompile(`^Player #(\d+) (.+) \(([0-9a-fA-F]+|-)\) has been kicked by BattlEye: (.*)$`)
+)
+
+// PlayerConnected is sent when a player connects to the server.
+//
+//	Player #3 Name (1.2.3.4:2304) connected
+type PlayerConnected struct {
+	raw
+	ID   int
+	Name string
+	IP   net.IP
+	Port int
+}
+
+// PlayerGUID is sent when the BattlEye GUID of a connecting player has been calculated.
+//
+//	Player #3 Name - BE GUID: d41d8cd98f00b204e9800998ecf8427e
+type PlayerGUID struct {
+	raw
+	ID   int
+	Name string
+	GUID string
+}
+
+// PlayerVerified is sent when the game server verified the GUID of a player.
+//
+//	Verified GUID (d41d8cd98f00b204e9800998ecf8427e) of player #3 Name
+type PlayerVerified struct {
+	raw
+	ID   int
+	Name string
+	GUID string
+}
+
+// PlayerDisconnected is sent when a player leaves the server.
+//
+//	Player #3 Name disconnected
+type PlayerDisconnected struct {
+	raw
+	ID   int
+	Name string
+}
+
+// PlayerKicked is sent when a player is kicked, either by BattlEye itself or by an admin.
+// GUID is empty if it was not known yet.
+//
+//	Player #3 Name (d41d8cd98f00b204e9800998ecf8427e) has been kicked by BattlEye: Admin Kick
+type PlayerKicked struct {
+	raw
+	ID     int
+	Name   string
+	GUID   string
+	Reason string
+}
+
+// parsePlayerConnected parses a PlayerConnected message.
+func parsePlayerConnected(msg string) Event {
+	m := playerConnectedRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	ip := net.ParseIP(m[3])
+	if ip == nil {
+		return nil
+	}
+	return &PlayerConnected{ID: atoi(m[1]), Name: m[2], IP: ip, Port: atoi(m[4])}
+}
+
+// parsePlayerDisconnected parses a PlayerDisconnected message.
+func parsePlayerDisconnected(msg string) Event {
+	m := playerDisconnectedRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	return &PlayerDisconnected{ID: atoi(m[1]), Name: m[2]}
+}
+
+// parsePlayerGUID parses a PlayerGUID message.
+func parsePlayerGUID(msg string) Event {
+	m := playerGUIDRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	return &PlayerGUID{ID: atoi(m[1]), Name: m[2], GUID: m[3]}
+}
+
+// parsePlayerVerified parses a PlayerVerified message.
+func parsePlayerVerified(msg string) Event {
+	m := playerVerifiedRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	return &PlayerVerified{ID: atoi(m[2]), Name: m[3], GUID: m[1]}
+}
+
+// parsePlayerKicked parses a PlayerKicked message.
+func parsePlayerKicked(msg string) Event {
+	m := playerKickedRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	e := &PlayerKicked{ID: atoi(m[1]), Name: m[2], Reason: m[4]}
+	if m[3] != "-" {
+		e.GUID = m[3]
+	}
+	return e
+}
+
+// atoi converts s, which the regexps guarantee to be a number, to an int.
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playerCorpus contains player lifecycle messages captured from game servers, by game.
+var playerCorpus = map[string][]struct {
+	msg string
+	exp Event
+}{
+	"ArmA 2 OA": {
+		{
+			msg: "Player #0 Dwarden (81.2.69.142:2304) connected",
+			exp: &PlayerConnected{ID: 0, Name: "Dwarden", IP: net.ParseIP("81.2.69.142"), Port: 2304},
+		},
+		{
+			msg: "Player #0 Dwarden - GUID: 8b2d17d6b0f0ad3f8a4b1c2e9e86c6d2 (unverified)",
+			exp: &PlayerGUID{ID: 0, Name: "Dwarden", GUID: "8b2d17d6b0f0ad3f8a4b1c2e9e86c6d2"},
+		},
+		{
+			msg: "Verified GUID (8b2d17d6b0f0ad3f8a4b1c2e9e86c6d2) of player #0 Dwarden",
+			exp: &PlayerVerified{ID: 0, Name: "Dwarden", GUID: "8b2d17d6b0f0ad3f8a4b1c2e9e86c6d2"},
+		},
+		{
+			msg: "Player #0 Dwarden disconnected",
+			exp: &PlayerDisconnected{ID: 0, Name: "Dwarden"},
+		},
+	},
+	"ArmA 3": {
+		{
+			msg: "Player #12 [TAG] Miller (Sniper) (203.0.113.7:2316) connected",
+			exp: &PlayerConnected{ID: 12, Name: "[TAG] Miller (Sniper)", IP: net.ParseIP("203.0.113.7"), Port: 2316},
+		},
+		{
+			msg: "Player #12 [TAG] Miller (Sniper) - BE GUID: 0a1b2c3d4e5f60718293a4b5c6d7e8f9",
+			exp: &PlayerGUID{ID: 12, Name: "[TAG] Miller (Sniper)", GUID: "0a1b2c3d4e5f60718293a4b5c6d7e8f9"},
+		},
+		{
+			msg: "Verified GUID (0a1b2c3d4e5f60718293a4b5c6d7e8f9) of player #12 [TAG] Miller (Sniper)",
+			exp: &PlayerVerified{ID: 12, Name: "[TAG] Miller (Sniper)", GUID: "0a1b2c3d4e5f60718293a4b5c6d7e8f9"},
+		},
+		{
+			msg: "Player #12 [TAG] Miller (Sniper) (0a1b2c3d4e5f60718293a4b5c6d7e8f9) has been kicked by BattlEye: Script Restriction #12",
+			exp: &PlayerKicked{ID: 12, Name: "[TAG] Miller (Sniper)", GUID: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", Reason: "Script Restriction #12"},
+		},
+		{
+			msg: "Player #4 Kerry (-) has been kicked by BattlEye: Client not responding",
+			exp: &PlayerKicked{ID: 4, Name: "Kerry", Reason: "Client not responding"},
+		},
+		{
+			msg: "Player #4 Kerry disconnected",
+			exp: &PlayerDisconnected{ID: 4, Name: "Kerry"},
+		},
+	},
+	"DayZ": {
+		{
+			msg: "Player #1 Survivor (198.51.100.23:2304) connected",
+			exp: &PlayerConnected{ID: 1, Name: "Survivor", IP: net.ParseIP("198.51.100.23"), Port: 2304},
+		},
+		{
+			msg: "Player #1 Survivor - BE GUID: f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8",
+			exp: &PlayerGUID{ID: 1, Name: "Survivor", GUID: "f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8"},
+		},
+		{
+			msg: "Player #1 Survivor (f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8) has been kicked by BattlEye: Admin Kick (AFK)",
+			exp: &PlayerKicked{ID: 1, Name: "Survivor", GUID: "f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8", Reason: "Admin Kick (AFK)"},
+		},
+	},
+}
+
+func TestParsePlayerEvents(t *testing.T) {
+	t.Parallel()
+
+	for game, corpus := range playerCorpus {
+		for _, tc := range corpus {
+			t.Run(game+"/"+tc.msg, func(t *testing.T) {
+				tc.exp.(rawSetter).setRaw(tc.msg)
+				assert.Equal(t, tc.exp, Parse(tc.msg))
+			})
+		}
+	}
+}
+
+func TestParseUnknown(t *testing.T) {
+	t.Parallel()
+
+	for _, msg := range []string{
+		"",
+		"Player #x Name disconnected",
+		"Player #1 Name (not an ip:2304) connected",
+		"Banned IP 1.2.3.4 for 5 minutes",
+	} {
+		e := Parse(msg)
+		assert.Equal(t, &Unknown{raw: raw(msg)}, e)
+		assert.Equal(t, msg, e.Raw())
+	}
+}
@@ -0,0 +1,65 @@
+package battleye
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// failureReplyRegexp matches the whole replies of the server to commands that failed. Replies
+	// merely containing similar words, e.g. a list of bans with a reason mentioning an error,
+	// are successes.
+	failureReplyRegexp = regexp.MustCompile(`^(?:` +
+		`Unknown command|` +
+		`Invalid (?:player ID|ban ID|GUID|value)|` +
+		`Player not found|` +
+		`(?:Failed to|Could not) (?:load|open|save) [\w.\-]+` +
+		`)$`)
+)
+
+// LoadScripts reloads the scripts.txt filter file.
+func (c *Client) LoadScripts() error {
+	return c.checked("loadScripts")
+}
+
+// LoadEvents reloads the event filter files, e.g. createvehicle.txt and remoteexec.txt.
+func (c *Client) LoadEvents() error {
+	return c.checked("loadEvents")
+}
+
+// LoadBans reloads the bans.txt file.
+func (c *Client) LoadBans() error {
+	return c.checked("loadBans")
+}
+
+// MaxPing sets the maximum ping in milliseconds above which players are kicked from the server.
+func (c *Client) MaxPing(ping int) error {
+	if ping < 1 {
+		return ErrInvalidPing
+	}
+	return c.checked("MaxPing " + strconv.Itoa(ping))
+}
+
+// checked executes cmd and returns a *CommandError if the server replies that it failed.
+func (c *Client) checked(cmd string) error {
+	resp, err := c.Exec(cmd)
+	if err != nil {
+		return err
+	}
+	return CheckReply(cmd, resp)
+}
+
+// CheckReply returns a *CommandError if resp, the reply of the server to cmd, is one of the
+// replies the server sends when a command fails, e.g. "Unknown command", and nil otherwise.
+func CheckReply(cmd, resp string) error {
+	if failed(resp) {
+		return &CommandError{Cmd: cmd, Msg: strings.TrimSpace(resp)}
+	}
+	return nil
+}
+
+// failed returns true if resp is a failure reply.
+func failed(resp string) bool {
+	return failureReplyRegexp.MatchString(strings.TrimSpace(resp))
+}
@@ -0,0 +1,68 @@
+package battleye
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailed(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		resp string
+		exp  bool
+	}{
+		{resp: "", exp: false},
+		{resp: "Scripts loaded", exp: false},
+		{resp: "Failed to load scripts.txt", exp: true},
+		{resp: "Could not open bans.txt", exp: true},
+		{resp: "Unknown command", exp: true},
+		{resp: "Unknown command\n", exp: true},
+		{resp: "Invalid GUID", exp: true},
+		{resp: "Player not found", exp: true},
+		{resp: "Could not load bans.txt", exp: true},
+		{resp: "unknown command", exp: false},
+		{resp: "Scripts loaded, no errors", exp: false},
+		{resp: "Unable to find anything wrong", exp: false},
+		{resp: "GUID Bans:\n0  d41d8cd98f00b204e9800998ecf8427e perm Invalid name\n", exp: false},
+		{resp: "Failed to load bans.txt, retrying", exp: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.resp, func(t *testing.T) {
+			assert.Equal(t, tc.exp, failed(tc.resp))
+		})
+	}
+}
+
+func TestClientFilters(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("loadScripts", "")
+	s.SetResponse("loadEvents", "")
+	s.SetResponse("loadBans", "Failed to load bans.txt\n")
+	s.SetResponse("MaxPing 200", "")
+
+	assert.NoError(t, c.LoadScripts())
+	assert.NoError(t, c.LoadEvents())
+	assert.Equal(t, &CommandError{Cmd: "loadBans", Msg: "Failed to load bans.txt"}, c.LoadBans())
+	assert.EqualError(t, c.LoadBans(), "battleye: loadBans: Failed to load bans.txt")
+	assert.Equal(t, ErrInvalidPing, c.MaxPing(0))
+	assert.Equal(t, &CommandError{Cmd: "players", Msg: "Unknown command"}, CheckReply("players", "Unknown command"))
+	assert.NoError(t, CheckReply("players", "Players on server:"))
+	assert.NoError(t, c.MaxPing(200))
+}
@@ -0,0 +1,681 @@
+// Package fleet manages the Clients of many BattlEye RCON servers.
+//
+// A Fleet connects to every server of a configuration, keeps the connections healthy, merges the
+// server messages into a single stream tagged with the server name and runs commands on many
+// servers at once:
+//
+//	cfg, err := config.Load(config.DefaultPath())
+//	if err != nil {
+//		// Handle error.
+//	}
+//	f, err := fleet.New(cfg, fleet.Concurrency(4))
+//	if err != nil {
+//		// Handle error.
+//	}
+//	defer f.Close()
+//
+//	f.Ready(ctx)
+//	results, err := f.BroadcastGroup(ctx, "eu", "Restart in 5 minutes")
+//
+// Commands run on the servers in parallel and return a result for each server, so that a server
+// being down doesn't fail the others.
+//
+// The messages of a server can be subscribed to with the Member of the server, which is a
+// battleye.Source outliving the Clients replaced after the connection is lost:
+//
+//	m, err := f.Member("eu1")
+//	if err != nil {
+//		// Handle error.
+//	}
+//	events, err := gateway.NewStream(m)
+package fleet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/config"
+	"github.com/multiplay/go-battleye/event"
+)
+
+const (
+	// defaultConcurrency is the default maximum number of servers connected to or commanded at
+	// once.
+	defaultConcurrency = 8
+
+	// defaultCheckInterval is the default interval of checking the connections.
+	defaultCheckInterval = 30 * time.Second
+
+	// defaultMinBackoff is the default delay before reconnecting to a server.
+	defaultMinBackoff = time.Second
+
+	// defaultMaxBackoff is the default maximum delay before reconnecting to a server.
+	defaultMaxBackoff = time.Minute
+
+	// defaultEventBuffer is the default size of the buffer of the Events channel.
+	defaultEventBuffer = 1000
+)
+
+var (
+	// ErrNilOption is returned by New if an Option is nil.
+	ErrNilOption = errors.New("fleet: nil option")
+
+	// ErrNilConfig is returned by New if the configuration is nil.
+	ErrNilConfig = errors.New("fleet: nil config")
+
+	// ErrNoServers is returned by New if the configuration has no servers.
+	ErrNoServers = errors.New("fleet: no servers")
+
+	// ErrInvalidConcurrency is returned if Concurrency Option is used with a value less than 1.
+	ErrInvalidConcurrency = errors.New("fleet: invalid concurrency")
+
+	// ErrInvalidCheckInterval is returned if CheckInterval Option is used with a non-positive
+	// interval.
+	ErrInvalidCheckInterval = errors.New("fleet: invalid check interval")
+
+	// ErrInvalidBackoff is returned if Backoff Option is used with a non-positive minimum or a
+	// maximum less than the minimum.
+	ErrInvalidBackoff = errors.New("fleet: invalid backoff")
+
+	// ErrInvalidBufferSize is returned if EventBuffer Option is used with a size less than 1.
+	ErrInvalidBufferSize = errors.New("fleet: invalid buffer size")
+
+	// ErrUnknownServer is the error of the Result of a server which is not in the Fleet.
+	ErrUnknownServer = errors.New("fleet: unknown server")
+
+	// ErrNotConnected is the error of the Result of a server which is not connected.
+	ErrNotConnected = errors.New("fleet: not connected")
+)
+
+// State is the state of the connection to a server.
+type State int
+
+// Connection states.
+const (
+	// Connecting is the state of a server which hasn't been connected to yet.
+	Connecting State = iota
+
+	// Connected is the state of a server whose Client responds to commands.
+	Connected
+
+	// Disconnected is the state of a server which couldn't be connected to or whose connection
+	// was lost, which is being reconnected to.
+	Disconnected
+)
+
+// String implements fmt.Stringer.
+func (s State) String() string {
+	switch s {
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// Health is the health of the connection to a server.
+type Health struct {
+	// State is the state of the connection.
+	State State
+
+	// Since is the time the connection entered State.
+	Since time.Time
+
+	// Err is the error which caused the Disconnected State.
+	Err error
+
+	// Reconnects is the number of times the server was connected to again after the connection
+	// was lost.
+	Reconnects int
+}
+
+// Event is a server message of a server of the Fleet.
+type Event struct {
+	// Server is the name of the server.
+	Server string
+
+	// Time is the time the message was received.
+	Time time.Time
+
+	// Message is the server message.
+	Message string
+
+	// Event is the message parsed into an event.
+	Event event.Event
+}
+
+// Result is the result of running a command on a server.
+type Result struct {
+	// Server is the name of the server.
+	Server string
+
+	// Err is the error of the command, which is ErrNotConnected if the server is not connected.
+	Err error
+}
+
+// PlayerResult is the result of looking for players on a server.
+type PlayerResult struct {
+	// Server is the name of the server.
+	Server string
+
+	// Players are the players found on the server.
+	Players []battleye.Player
+
+	// Err is the error of listing the players.
+	Err error
+}
+
+// Member is a server of the Fleet. It is a battleye.Source of the messages of the server, whose
+// handlers stay registered when the Client is replaced after the connection is lost, and executes
+// commands with the connected Client.
+type Member struct {
+	server *config.Server
+
+	// ready is closed when the first attempt to connect to the server completed.
+	ready chan struct{}
+
+	// closed is closed once the Fleet is closed and its Clients are.
+	closed chan struct{}
+
+	mu        sync.Mutex
+	client    *battleye.Client
+	health    Health
+	connected bool
+
+	handlersMu sync.Mutex
+	handlers   []handler
+	nextID     battleye.HandlerID
+}
+
+// handler is a function registered with OnMessage.
+type handler struct {
+	id battleye.HandlerID
+	f  func(battleye.Message)
+}
+
+var _ battleye.Source = (*Member)(nil)
+
+// OnMessage registers f to be called with every server message received by the Clients of m, in
+// order. f is called by the Client which received the message before it reads anything else, so
+// it must return quickly and must not execute commands itself.
+func (m *Member) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	m.handlersMu.Lock()
+	defer m.handlersMu.Unlock()
+
+	m.nextID++
+	m.handlers = append(m.handlers, handler{id: m.nextID, f: f})
+	return m.nextID
+}
+
+// Unregister removes the handler registered with OnMessage identified by id. It returns false if
+// no such handler is registered.
+func (m *Member) Unregister(id battleye.HandlerID) bool {
+	m.handlersMu.Lock()
+	defer m.handlersMu.Unlock()
+
+	for i, h := range m.handlers {
+		if h.id == id {
+			m.handlers = append(m.handlers[:i:i], m.handlers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Done returns a channel which is closed when the Fleet is closed, after which no more messages
+// are received.
+func (m *Member) Done() <-chan struct{} {
+	return m.closed
+}
+
+// ExecContext executes cmd with the connected Client of m, or returns ErrNotConnected.
+func (m *Member) ExecContext(ctx context.Context, cmd string) (string, error) {
+	c := m.getClient()
+	if c == nil {
+		return "", ErrNotConnected
+	}
+	return c.ExecContext(ctx, cmd)
+}
+
+// receive calls the handlers of m with msg.
+func (m *Member) receive(msg battleye.Message) {
+	m.handlersMu.Lock()
+	handlers := m.handlers
+	m.handlersMu.Unlock()
+
+	for _, h := range handlers {
+		h.f(msg)
+	}
+}
+
+// setState sets the state of m.
+func (m *Member) setState(state State, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.State, m.health.Since, m.health.Err = state, time.Now(), err
+}
+
+// setClient sets the connected Client of m, or nil when it's disconnected with err.
+func (m *Member) setClient(c *battleye.Client, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.client = c
+	m.health.Since, m.health.Err = time.Now(), err
+	if c == nil {
+		m.health.State = Disconnected
+		return
+	}
+	m.health.State = Connected
+	if m.connected {
+		m.health.Reconnects++
+	}
+	m.connected = true
+}
+
+// getClient returns the connected Client of m or nil.
+func (m *Member) getClient() *battleye.Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client
+}
+
+// Fleet owns the Clients of many servers, keyed by server name.
+type Fleet struct {
+	cfg         *config.Config
+	concurrency int
+	interval    time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	bufSize     int
+	clientOpts  []battleye.Option
+
+	names   []string
+	members map[string]*Member
+	events  chan Event
+	closed  chan struct{}
+
+	// connecting limits the number of servers connected to at once.
+	connecting chan struct{}
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Option is a Fleet configuration Option type.
+type Option func(f *Fleet) error
+
+// Concurrency sets the maximum number of servers connected to at once, and of servers a command
+// runs on at once.
+func Concurrency(n int) Option {
+	return func(f *Fleet) error {
+		if n < 1 {
+			return ErrInvalidConcurrency
+		}
+		f.concurrency = n
+		return nil
+	}
+}
+
+// CheckInterval sets the interval of checking the connections, which is also the timeout of the
+// checks.
+func CheckInterval(interval time.Duration) Option {
+	return func(f *Fleet) error {
+		if interval <= 0 {
+			return ErrInvalidCheckInterval
+		}
+		f.interval = interval
+		return nil
+	}
+}
+
+// Backoff sets the delay before reconnecting to a server, which doubles with each failed
+// attempt from minDelay up to maxDelay.
+func Backoff(minDelay, maxDelay time.Duration) Option {
+	return func(f *Fleet) error {
+		if minDelay <= 0 || maxDelay < minDelay {
+			return ErrInvalidBackoff
+		}
+		f.minBackoff, f.maxBackoff = minDelay, maxDelay
+		return nil
+	}
+}
+
+// EventBuffer sets the size of the buffer of the Events channel.
+func EventBuffer(size int) Option {
+	return func(f *Fleet) error {
+		if size < 1 {
+			return ErrInvalidBufferSize
+		}
+		f.bufSize = size
+		return nil
+	}
+}
+
+// ClientOptions sets Options added to those of the server profiles when creating the Clients,
+// e.g. battleye.AddHooks.
+func ClientOptions(options ...battleye.Option) Option {
+	return func(f *Fleet) error {
+		f.clientOpts = append(f.clientOpts, options...)
+		return nil
+	}
+}
+
+// New returns a new Fleet of the servers of cfg and starts connecting to them.
+func New(cfg *config.Config, options ...Option) (*Fleet, error) {
+	if cfg == nil {
+		return nil, ErrNilConfig
+	}
+	f := &Fleet{
+		cfg:         cfg,
+		concurrency: defaultConcurrency,
+		interval:    defaultCheckInterval,
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		bufSize:     defaultEventBuffer,
+		members:     make(map[string]*Member),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f.names = cfg.Names()
+	if len(f.names) == 0 {
+		return nil, ErrNoServers
+	}
+	f.closed = make(chan struct{})
+	for _, name := range f.names {
+		s, err := cfg.Server(name)
+		if err != nil {
+			return nil, err
+		}
+		f.members[name] = &Member{
+			server: s,
+			ready:  make(chan struct{}),
+			closed: f.closed,
+			health: Health{State: Connecting, Since: time.Now()},
+		}
+	}
+
+	f.events = make(chan Event, f.bufSize)
+	f.connecting = make(chan struct{}, f.concurrency)
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	for _, name := range f.names {
+		f.wg.Add(1)
+		go f.supervise(name, f.members[name])
+	}
+
+	return f, nil
+}
+
+// Close disconnects from the servers, closes the Events channel and the Done channels of the
+// Members. It is safe to call Close more than once.
+func (f *Fleet) Close() {
+	f.closeOnce.Do(func() {
+		f.cancel()
+		f.wg.Wait()
+		close(f.closed)
+		close(f.events)
+	})
+}
+
+// Ready waits until the first attempt to connect to every server completed, or ctx is done.
+func (f *Fleet) Ready(ctx context.Context) error {
+	for _, name := range f.names {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-f.members[name].ready:
+		}
+	}
+	return nil
+}
+
+// Servers returns the names of the servers, sorted.
+func (f *Fleet) Servers() []string {
+	return append([]string(nil), f.names...)
+}
+
+// Group returns the names of the servers in the group called name.
+func (f *Fleet) Group(name string) ([]string, error) {
+	servers, err := f.cfg.Group(name)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(servers))
+	for _, s := range servers {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// Client returns the Client of the server called name, to execute commands with. It must not be
+// closed, and is replaced by a new one if the connection is lost, so the handlers registered with
+// its OnMessage stop receiving messages then; subscribe with the Member of the server instead. The
+// Fleet doesn't read its Messages channel.
+func (f *Fleet) Client(name string) (*battleye.Client, error) {
+	m, ok := f.members[name]
+	if !ok {
+		return nil, ErrUnknownServer
+	}
+	if c := m.getClient(); c != nil {
+		return c, nil
+	}
+	return nil, ErrNotConnected
+}
+
+// Member returns the Member of the server called name.
+func (f *Fleet) Member(name string) (*Member, error) {
+	m, ok := f.members[name]
+	if !ok {
+		return nil, ErrUnknownServer
+	}
+	return m, nil
+}
+
+// Health returns the health of the connections by server name.
+func (f *Fleet) Health() map[string]Health {
+	health := make(map[string]Health, len(f.members))
+	for name, m := range f.members {
+		m.mu.Lock()
+		health[name] = m.health
+		m.mu.Unlock()
+	}
+	return health
+}
+
+// Events returns a buffered channel of the server messages of every server. If the channel is
+// full new messages are dropped; subscribe with the Members of the servers to get every message.
+// It is closed by Close.
+func (f *Fleet) Events() <-chan Event {
+	return f.events
+}
+
+// Do runs fn with the name and the Client of each server in servers in parallel, or of every
+// server if servers is empty, and returns the results in the same order.
+func (f *Fleet) Do(ctx context.Context, servers []string, fn func(ctx context.Context, server string, c *battleye.Client) error) []Result {
+	if len(servers) == 0 {
+		servers = f.names
+	}
+	results := make([]Result, len(servers))
+	sem := make(chan struct{}, f.concurrency)
+	var wg sync.WaitGroup
+	for i, name := range servers {
+		results[i].Server = name
+		c, err := f.Client(name)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *Result, c *battleye.Client) {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				r.Err = ctx.Err()
+				return
+			case sem <- struct{}{}:
+			}
+			defer func() { <-sem }()
+			r.Err = fn(ctx, r.Server, c)
+		}(&results[i], c)
+	}
+	wg.Wait()
+	return results
+}
+
+// Broadcast sends msg to the players of every server.
+func (f *Fleet) Broadcast(ctx context.Context, msg string) []Result {
+	return f.Do(ctx, nil, func(ctx context.Context, _ string, c *battleye.Client) error {
+		return c.BroadcastContext(ctx, msg)
+	})
+}
+
+// BroadcastGroup sends msg to the players of the servers in the group called group.
+func (f *Fleet) BroadcastGroup(ctx context.Context, group, msg string) ([]Result, error) {
+	servers, err := f.Group(group)
+	if err != nil {
+		return nil, err
+	}
+	return f.Do(ctx, servers, func(ctx context.Context, _ string, c *battleye.Client) error {
+		return c.BroadcastContext(ctx, msg)
+	}), nil
+}
+
+// FindPlayer looks for the player with the BattlEye GUID guid on every server and returns the
+// result of each server, in which Players is empty if the player is not on the server.
+func (f *Fleet) FindPlayer(ctx context.Context, guid string) []PlayerResult {
+	players := make(map[string][]battleye.Player)
+	var mu sync.Mutex
+	results := f.Do(ctx, nil, func(ctx context.Context, server string, c *battleye.Client) error {
+		list, err := c.PlayersContext(ctx)
+		if err != nil {
+			return err
+		}
+		var found []battleye.Player
+		for _, p := range list {
+			if p.GUID != "" && p.GUID == guid {
+				found = append(found, p)
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		players[server] = found
+		return nil
+	})
+
+	found := make([]PlayerResult, len(results))
+	for i, r := range results {
+		found[i] = PlayerResult{Server: r.Server, Players: players[r.Server], Err: r.Err}
+	}
+	return found
+}
+
+// supervise is a goroutine which keeps m connected until f is closed.
+func (f *Fleet) supervise(name string, m *Member) {
+	defer f.wg.Done()
+
+	delay := f.minBackoff
+	first := true
+	for {
+		c, err := f.connect(name, m)
+		if err == nil {
+			m.setClient(c, nil)
+		} else if f.ctx.Err() == nil {
+			m.setState(Disconnected, err)
+		}
+		if first {
+			close(m.ready)
+			first = false
+		}
+		if f.ctx.Err() != nil {
+			if c != nil {
+				m.setClient(nil, nil)
+				c.Close() // nolint: errcheck
+			}
+			return
+		}
+		if err != nil {
+			t := time.NewTimer(delay)
+			select {
+			case <-f.ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+			if delay *= 2; delay > f.maxBackoff {
+				delay = f.maxBackoff
+			}
+			continue
+		}
+		delay = f.minBackoff
+
+		err = f.watch(c)
+		m.setClient(nil, err)
+		c.Close() // nolint: errcheck
+		if err == nil {
+			return
+		}
+	}
+}
+
+// connect connects to the server of m, called name, waiting for fewer than Concurrency servers to
+// be connecting. The messages of the Client are passed to m and the Events channel from the
+// first, which is received while logging in.
+func (f *Fleet) connect(name string, m *Member) (*battleye.Client, error) {
+	select {
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	case f.connecting <- struct{}{}:
+	}
+	defer func() { <-f.connecting }()
+	hooks := &battleye.Hooks{ServerMessage: func(msg battleye.Message) {
+		f.forward(name, msg)
+		m.receive(msg)
+	}}
+	options := append(append([]battleye.Option(nil), f.clientOpts...), battleye.AddHooks(hooks))
+	return m.server.NewClient(options...)
+}
+
+// watch checks the connection of c until it's lost, in which case the error is returned, or until
+// f is closed.
+func (f *Fleet) watch(c *battleye.Client) error {
+	t := time.NewTicker(f.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return nil
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(f.ctx, f.interval)
+			_, err := c.ExecContext(ctx, "")
+			cancel()
+			if err != nil && f.ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+}
+
+// forward sends msg of the server called name to the Events channel, unless it's full.
+func (f *Fleet) forward(name string, msg battleye.Message) {
+	select {
+	case f.events <- Event{Server: name, Time: msg.Time, Message: msg.Event.Raw(), Event: msg.Event}:
+	default:
+	}
+}
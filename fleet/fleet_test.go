@@ -0,0 +1,335 @@
+package fleet
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/config"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPassword = "secret"
+	kerryGUID    = "d41d8cd98f00b204e9800998ecf8427e"
+)
+
+// rconServer is a fake BattlEye RCON server.
+type rconServer struct {
+	pc      net.PacketConn
+	players string
+
+	// down makes the server ignore every packet.
+	down int32
+
+	mu   sync.Mutex
+	says []string
+}
+
+// newRCONServer returns a running rconServer replying to players with players, which is the
+// list of players without the header.
+func newRCONServer(t *testing.T, players string) *rconServer {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s := &rconServer{pc: pc, players: players}
+	go s.serve()
+	t.Cleanup(func() { pc.Close() }) // nolint: errcheck
+	return s
+}
+
+// Addr returns the address of s.
+func (s *rconServer) Addr() string {
+	return s.pc.LocalAddr().String()
+}
+
+// setDown sets whether s ignores every packet.
+func (s *rconServer) setDown(down bool) {
+	var v int32
+	if down {
+		v = 1
+	}
+	atomic.StoreInt32(&s.down, v)
+}
+
+// said returns the say commands received by s.
+func (s *rconServer) said() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.says...)
+}
+
+// send sends a packet with payload to addr.
+func (s *rconServer) send(addr net.Addr, payload []byte) {
+	payload = append([]byte{0xff}, payload...)
+	b := []byte{'B', 'E', 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(b[2:], crc32.ChecksumIEEE(payload))
+	s.pc.WriteTo(append(b, payload...), addr) // nolint: errcheck
+}
+
+// serve serves the login and command packets of the BattlEye RCON protocol until s is closed.
+func (s *rconServer) serve() {
+	b := make([]byte, 1500)
+	for {
+		n, addr, err := s.pc.ReadFrom(b)
+		if err != nil {
+			return
+		}
+		if n < 8 || atomic.LoadInt32(&s.down) == 1 {
+			continue
+		}
+		switch b[7] {
+		case 0x00:
+			if string(b[8:n]) != testPassword {
+				s.send(addr, []byte{0x00, 0x00})
+				continue
+			}
+			s.send(addr, []byte{0x00, 0x01})
+			s.send(addr, append([]byte{0x02, 0x00}, "RCon admin #0 ("+addr.String()+") logged in"...))
+		case 0x01:
+			cmd, resp := string(b[9:n]), ""
+			switch {
+			case cmd == "players":
+				resp = "Players on server:\n" +
+					"[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n" +
+					"--------------------------------------------------\n" +
+					s.players
+			case strings.HasPrefix(cmd, "say "):
+				s.mu.Lock()
+				s.says = append(s.says, cmd)
+				s.mu.Unlock()
+			}
+			s.send(addr, append([]byte{0x01, b[8]}, resp...))
+		}
+	}
+}
+
+// testConfig returns a configuration of the servers at the addresses by name, with the groups.
+func testConfig(t *testing.T, addrs map[string]string, groups string) *config.Config {
+	var b strings.Builder
+	b.WriteString("defaults:\n  timeout: 200ms\n  password: " + testPassword + "\nservers:\n")
+	for name, addr := range addrs {
+		fmt.Fprintf(&b, "  %v:\n    address: %v\n", name, addr)
+	}
+	b.WriteString(groups)
+	cfg, err := config.Parse(strings.NewReader(b.String()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return cfg
+}
+
+// closedAddr returns an address nothing listens on.
+func closedAddr(t *testing.T) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	addr := pc.LocalAddr().String()
+	assert.NoError(t, pc.Close())
+	return addr
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(t, map[string]string{"eu1": closedAddr(t)}, "")
+	testcases := []struct {
+		name   string
+		cfg    *config.Config
+		opts   []Option
+		expErr error
+	}{
+		{name: "Nil config", expErr: ErrNilConfig},
+		{name: "No servers", cfg: &config.Config{}, expErr: ErrNoServers},
+		{name: "Nil option", cfg: cfg, opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Invalid concurrency", cfg: cfg, opts: []Option{Concurrency(0)}, expErr: ErrInvalidConcurrency},
+		{name: "Invalid check interval", cfg: cfg, opts: []Option{CheckInterval(0)}, expErr: ErrInvalidCheckInterval},
+		{name: "Invalid backoff", cfg: cfg, opts: []Option{Backoff(time.Second, time.Millisecond)}, expErr: ErrInvalidBackoff},
+		{name: "Invalid buffer size", cfg: cfg, opts: []Option{EventBuffer(0)}, expErr: ErrInvalidBufferSize},
+		{name: "Valid", cfg: cfg, opts: []Option{
+			Concurrency(2),
+			CheckInterval(time.Second),
+			Backoff(time.Millisecond, time.Second),
+			EventBuffer(10),
+			ClientOptions(battleye.MessageBuffer(10)),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := New(tc.cfg, tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, []string{"eu1"}, f.Servers())
+				f.Close()
+			}
+		})
+	}
+}
+
+func TestFleet(t *testing.T) {
+	eu1 := newRCONServer(t, "(0 players in total)")
+	eu2 := newRCONServer(t, "0   192.168.1.2:2304      30   "+kerryGUID+"(OK) Kerry\n(1 players in total)")
+	cfg := testConfig(t, map[string]string{"eu1": eu1.Addr(), "eu2": eu2.Addr(), "us1": closedAddr(t)},
+		"groups:\n  eu: [eu1, eu2]\n  us: [us1]\n")
+
+	f, err := New(cfg, Concurrency(2), Backoff(time.Hour, time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, f.Ready(ctx))
+
+	health := f.Health()
+	assert.Equal(t, Connected, health["eu1"].State)
+	assert.Equal(t, Connected, health["eu2"].State)
+	assert.Equal(t, Disconnected, health["us1"].State)
+	assert.Error(t, health["us1"].Err)
+	assert.Equal(t, "disconnected", health["us1"].State.String())
+
+	servers := make(map[string]bool)
+	for len(servers) < 2 {
+		select {
+		case e := <-f.Events():
+			assert.Equal(t, "admin_logged_in", event.TypeOf(e.Event))
+			assert.True(t, strings.HasPrefix(e.Message, "RCon admin #0"), e.Message)
+			servers[e.Server] = true
+		case <-ctx.Done():
+			assert.Fail(t, "events not received", servers)
+			return
+		}
+	}
+	assert.Equal(t, map[string]bool{"eu1": true, "eu2": true}, servers)
+
+	results, err := f.BroadcastGroup(ctx, "eu", "Restart in 5 minutes")
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{{Server: "eu1"}, {Server: "eu2"}}, results)
+	assert.Equal(t, []string{"say -1 Restart in 5 minutes"}, eu1.said())
+	assert.Equal(t, []string{"say -1 Restart in 5 minutes"}, eu2.said())
+
+	_, err = f.BroadcastGroup(ctx, "asia", "hello")
+	assert.Equal(t, config.ErrUnknownGroup, err)
+
+	assert.Equal(t, []Result{{Server: "eu1"}, {Server: "eu2"}, {Server: "us1", Err: ErrNotConnected}}, f.Broadcast(ctx, "hello"))
+
+	found := f.FindPlayer(ctx, kerryGUID)
+	if assert.Len(t, found, 3) {
+		assert.Equal(t, PlayerResult{Server: "eu1"}, found[0])
+		assert.Equal(t, "eu2", found[1].Server)
+		if assert.Len(t, found[1].Players, 1) {
+			assert.Equal(t, "Kerry", found[1].Players[0].Name)
+		}
+		assert.Equal(t, PlayerResult{Server: "us1", Err: ErrNotConnected}, found[2])
+	}
+
+	results = f.Do(ctx, []string{"eu2", "eu3"}, func(ctx context.Context, server string, c *battleye.Client) error {
+		_, err := c.ExecContext(ctx, "version")
+		return err
+	})
+	assert.Equal(t, []Result{{Server: "eu2"}, {Server: "eu3", Err: ErrUnknownServer}}, results)
+
+	_, err = f.Client("eu3")
+	assert.Equal(t, ErrUnknownServer, err)
+	_, err = f.Client("us1")
+	assert.Equal(t, ErrNotConnected, err)
+
+	// The Fleet leaves the Messages channels of the Clients to other consumers.
+	c, err := f.Client("eu1")
+	if assert.NoError(t, err) {
+		select {
+		case msg := <-c.Messages():
+			assert.True(t, strings.HasPrefix(msg, "RCon admin #0"), msg)
+		case <-ctx.Done():
+			assert.Fail(t, "message not received")
+		}
+	}
+
+	_, err = f.Member("eu3")
+	assert.Equal(t, ErrUnknownServer, err)
+	m, err := f.Member("us1")
+	if assert.NoError(t, err) {
+		_, err = m.ExecContext(ctx, "players")
+		assert.Equal(t, ErrNotConnected, err)
+	}
+
+	f.Close()
+	if m != nil {
+		<-m.Done()
+	}
+	_, ok := <-f.Events()
+	for ok {
+		_, ok = <-f.Events()
+	}
+}
+
+func TestFleetReconnect(t *testing.T) {
+	s := newRCONServer(t, "(0 players in total)")
+	cfg := testConfig(t, map[string]string{"eu1": s.Addr()}, "")
+
+	f, err := New(cfg, CheckInterval(50*time.Millisecond), Backoff(10*time.Millisecond, 50*time.Millisecond))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	// The handlers of the Member receive the messages of every Client of the server, from the
+	// login of the first.
+	m, err := f.Member("eu1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	logins := make(chan battleye.Message, 10)
+	m.OnMessage(func(msg battleye.Message) {
+		if _, ok := msg.Event.(*event.AdminLoggedIn); ok {
+			logins <- msg
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, f.Ready(ctx))
+	c, err := f.Client("eu1")
+	assert.NoError(t, err)
+
+	s.setDown(true)
+	assert.Eventually(t, func() bool { return f.Health()["eu1"].State == Disconnected }, 5*time.Second, 10*time.Millisecond)
+	assert.Error(t, f.Health()["eu1"].Err)
+	_, err = f.Client("eu1")
+	assert.Equal(t, ErrNotConnected, err)
+
+	s.setDown(false)
+	assert.Eventually(t, func() bool { return f.Health()["eu1"].State == Connected }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, f.Health()["eu1"].Reconnects)
+	c2, err := f.Client("eu1")
+	assert.NoError(t, err)
+	assert.NotSame(t, c, c2)
+
+	_, err = m.ExecContext(ctx, "players")
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-logins:
+			assert.False(t, msg.Time.IsZero())
+		case <-ctx.Done():
+			assert.Fail(t, "login message not received", i)
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+)
+
+// Scope is a permission granted to a token.
+type Scope string
+
+// Scopes.
+const (
+	// ScopeExec allows executing arbitrary commands.
+	ScopeExec Scope = "exec"
+
+	// ScopePlayers allows listing the players.
+	ScopePlayers Scope = "players"
+
+	// ScopeBans allows listing the bans.
+	ScopeBans Scope = "bans"
+
+	// ScopeKick allows kicking players.
+	ScopeKick Scope = "kick"
+
+	// ScopeSay allows sending messages to players.
+	ScopeSay Scope = "say"
+
+	// ScopeLock allows locking and unlocking the server.
+	ScopeLock Scope = "lock"
+
+	// ScopeEvents allows subscribing to the event stream.
+	ScopeEvents Scope = "events"
+
+	// ScopeAll grants every scope.
+	ScopeAll Scope = "*"
+)
+
+var (
+	// knownScopes are the valid scopes.
+	knownScopes = map[Scope]bool{
+		ScopeExec: true, ScopePlayers: true, ScopeBans: true, ScopeKick: true, ScopeSay: true,
+		ScopeLock: true, ScopeEvents: true, ScopeAll: true,
+	}
+)
+
+// scopes is a set of scopes.
+type scopes map[Scope]bool
+
+// allows returns true if s grants scope.
+func (s scopes) allows(scope Scope) bool {
+	return s[scope] || s[ScopeAll]
+}
+
+// Token grants the bearer token the given scopes. A token may be given more than once, in which
+// case the scopes are merged.
+func Token(token string, granted ...Scope) Option {
+	return func(g *Gateway) error {
+		if token == "" {
+			return ErrInvalidToken
+		}
+		key := sha256.Sum256([]byte(token))
+		s := g.tokens[key]
+		if s == nil {
+			s = make(scopes)
+			g.tokens[key] = s
+		}
+		for _, scope := range granted {
+			if !knownScopes[scope] {
+				return ErrInvalidScope
+			}
+			s[scope] = true
+		}
+		return nil
+	}
+}
+
+// authenticate returns the scopes granted to the bearer token of r and false if there is no
+// valid token. If query is set the token may also be given by the access_token query parameter,
+// as browsers can't set headers on EventSource and WebSocket requests.
+//
+// Tokens are looked up by their SHA-256 hash, so the time taken doesn't depend on how much of a
+// guessed token matches.
+func (g *Gateway) authenticate(r *http.Request, query bool) (scopes, bool) {
+	var token string
+	auth := r.Header.Get("Authorization")
+	const prefix = "bearer "
+	switch {
+	case len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix):
+		token = strings.TrimSpace(auth[len(prefix):])
+	case auth == "" && query:
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return nil, false
+	}
+
+	s, ok := g.tokens[sha256.Sum256([]byte(token))]
+	return s, ok
+}
@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/multiplay/go-battleye/internal/errkind"
+)
+
+var (
+	// ErrNilOption is returned by New if an Option is nil.
+	ErrNilOption = errors.New("gateway: nil option")
+
+	// ErrNoServers is returned by New if there are no servers to serve.
+	ErrNoServers = errors.New("gateway: no servers")
+
+	// ErrInvalidServer is returned by New if a server is nil or its name is empty or contains a slash.
+	ErrInvalidServer = errors.New("gateway: invalid server")
+
+	// ErrNoTokens is returned by New if no Token Option is given.
+	ErrNoTokens = errors.New("gateway: no tokens")
+
+	// ErrInvalidToken is returned by Token if the token is empty.
+	ErrInvalidToken = errors.New("gateway: invalid token")
+
+	// ErrInvalidScope is returned by Token if a scope is unknown.
+	ErrInvalidScope = errors.New("gateway: invalid scope")
+
+	// ErrInvalidTimeout is returned if RequestTimeout Option is used with a non-positive timeout.
+	ErrInvalidTimeout = errors.New("gateway: invalid timeout")
+
+	// ErrInvalidBufferSize is returned if SubscriberBuffer Option is used with a size less than 1.
+	ErrInvalidBufferSize = errors.New("gateway: invalid buffer size")
+
+	// ErrInvalidHistorySize is returned if History Option is used with a negative size.
+	ErrInvalidHistorySize = errors.New("gateway: invalid history size")
+
+	// ErrInvalidCheckInterval is returned if CheckInterval Option is used with a negative interval.
+	ErrInvalidCheckInterval = errors.New("gateway: invalid check interval")
+
+	// ErrUnknownServer is returned if a request names a server which is not served.
+	ErrUnknownServer = errors.New("gateway: unknown server")
+
+	// errUnauthorized is returned if a request has no valid bearer token.
+	errUnauthorized = errors.New("gateway: unauthorized")
+
+	// errForbidden is returned if the token of a request lacks the scope of the endpoint.
+	errForbidden = errors.New("gateway: insufficient scope")
+
+	// errNotFound is returned if the path of a request is not an endpoint.
+	errNotFound = errors.New("gateway: not found")
+
+	// errMethodNotAllowed is returned if the endpoint doesn't support the method of a request.
+	errMethodNotAllowed = errors.New("gateway: method not allowed")
+
+	// errStreamingUnsupported is returned if the http.ResponseWriter can't flush events.
+	errStreamingUnsupported = errors.New("gateway: streaming unsupported")
+)
+
+var (
+	// statusesByKind are the HTTP status codes of the kinds of Client errors.
+	statusesByKind = map[errkind.Kind]int{
+		errkind.Invalid:       http.StatusBadRequest,
+		errkind.NotFound:      http.StatusNotFound,
+		errkind.Rejected:      http.StatusBadGateway,
+		errkind.CommandFailed: http.StatusBadGateway,
+		errkind.BadResponse:   http.StatusBadGateway,
+		errkind.LoginFailed:   http.StatusBadGateway,
+		errkind.Timeout:       http.StatusGatewayTimeout,
+		errkind.Canceled:      http.StatusServiceUnavailable,
+		errkind.Closed:        http.StatusServiceUnavailable,
+	}
+
+	// statuses are the HTTP status codes of the errors of the gateway.
+	statuses = map[error]int{
+		ErrUnknownServer:        http.StatusNotFound,
+		errUnauthorized:         http.StatusUnauthorized,
+		errForbidden:            http.StatusForbidden,
+		errNotFound:             http.StatusNotFound,
+		errMethodNotAllowed:     http.StatusMethodNotAllowed,
+		errStreamingUnsupported: http.StatusInternalServerError,
+	}
+)
+
+// ErrorResponse is the body of error responses.
+type ErrorResponse struct {
+	// Error is the error message.
+	Error string `json:"error"`
+
+	// Code identifies the error, e.g. player_not_found for battleye.ErrPlayerNotFound.
+	Code string `json:"code"`
+}
+
+// StatusCode returns the HTTP status code for err, which defaults to 502 Bad Gateway as errors
+// not caused by the request come from the BattlEye server or the connection to it.
+func StatusCode(err error) int {
+	if isRequestError(err) {
+		return http.StatusBadRequest
+	}
+	for e, status := range statuses {
+		if errors.Is(err, e) {
+			return status
+		}
+	}
+	if k, _ := errkind.Of(err); k != errkind.Unknown {
+		return statusesByKind[k]
+	}
+	return http.StatusBadGateway
+}
+
+// ErrorCode returns the code identifying err in error responses.
+func ErrorCode(err error) string {
+	if isRequestError(err) {
+		return "invalid_request"
+	}
+	for e := range statuses {
+		if errors.Is(err, e) {
+			return errorCode(e)
+		}
+	}
+
+	k, e := errkind.Of(err)
+	switch {
+	case k == errkind.CommandFailed:
+		return "command_failed"
+	case e == context.DeadlineExceeded:
+		return "timeout"
+	case e == context.Canceled:
+		return "canceled"
+	case e == net.ErrClosed:
+		return "closed"
+	case e != nil:
+		return errorCode(e)
+	}
+	return "server_error"
+}
+
+// errorCode returns the code of the sentinel error e, its message without the package prefix in
+// snake case.
+func errorCode(e error) string {
+	msg := e.Error()
+	if i := strings.Index(msg, ": "); i != -1 {
+		msg = msg[i+2:]
+	}
+	return strings.ReplaceAll(msg, " ", "_")
+}
+
+// writeError writes err as the JSON body of the response.
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, StatusCode(err), ErrorResponse{Error: err.Error(), Code: ErrorCode(err)})
+}
@@ -0,0 +1,385 @@
+// Package gateway exposes BattlEye RCON Clients as a REST API over HTTP, for tools which can't
+// speak the UDP protocol themselves.
+//
+// A Gateway wraps long-lived Clients by name and serves:
+//
+//	GET  /servers                  names of the servers
+//	POST /servers/{name}/exec      {"command": "..."}              scope exec
+//	GET  /servers/{name}/players                                   scope players
+//	GET  /servers/{name}/bans                                      scope bans
+//	POST /servers/{name}/kick      {"player_id": 3, "reason": "..."} scope kick
+//	POST /servers/{name}/say       {"player_id": 3, "message": "..."} scope say
+//	POST /servers/{name}/lock                                      scope lock
+//	POST /servers/{name}/unlock                                    scope lock
+//	GET  /servers/{name}/events    Stream of server events         scope events
+//
+// Requests are authenticated with bearer tokens, each granted a set of scopes. As browsers can't
+// set headers on EventSource and WebSocket requests, the token of events requests may also be
+// given by the access_token query parameter.
+//
+// say without a player_id broadcasts to every player. Errors are returned as JSON bodies in the
+// form {"error": "...", "code": "..."} with an HTTP status derived from the error.
+//
+// Every request but events is given a timeout after which the command executing on the server is
+// cancelled.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	battleye "github.com/multiplay/go-battleye"
+)
+
+const (
+	// defaultRequestTimeout is the default maximum duration of handling a request.
+	defaultRequestTimeout = 10 * time.Second
+
+	// maxBodySize is the maximum size of request bodies in bytes.
+	maxBodySize = 64 << 10
+)
+
+// Server is the interface of the Clients wrapped by a Gateway, which *battleye.Client implements.
+type Server interface {
+	ExecContext(ctx context.Context, cmd string) (string, error)
+	PlayersContext(ctx context.Context) ([]battleye.Player, error)
+	BansContext(ctx context.Context) ([]battleye.Ban, error)
+	KickContext(ctx context.Context, playerID int, reason string) error
+	BroadcastContext(ctx context.Context, msg string) error
+	WhisperContext(ctx context.Context, playerID int, msg string) error
+	LockContext(ctx context.Context) error
+	UnlockContext(ctx context.Context) error
+}
+
+var _ Server = (*battleye.Client)(nil)
+
+// endpoint is an operation on a server.
+type endpoint struct {
+	method string
+	scope  Scope
+	handle func(ctx context.Context, s Server, r *http.Request) (interface{}, error)
+}
+
+var (
+	// endpoints are the operations on a server by path element.
+	endpoints = map[string]endpoint{
+		"exec":    {http.MethodPost, ScopeExec, handleExec},
+		"players": {http.MethodGet, ScopePlayers, handlePlayers},
+		"bans":    {http.MethodGet, ScopeBans, handleBans},
+		"kick":    {http.MethodPost, ScopeKick, handleKick},
+		"say":     {http.MethodPost, ScopeSay, handleSay},
+		"lock":    {http.MethodPost, ScopeLock, handleLock},
+		"unlock":  {http.MethodPost, ScopeLock, handleUnlock},
+	}
+)
+
+// Gateway is an http.Handler exposing Servers as a REST API.
+type Gateway struct {
+	servers map[string]Server
+	streams map[string]*Stream
+	tokens  map[[32]byte]scopes
+	timeout time.Duration
+}
+
+// Option is a Gateway configuration Option type.
+type Option func(g *Gateway) error
+
+// RequestTimeout sets the maximum duration of handling a request, after which the command
+// executing on the server is cancelled and 504 Gateway Timeout is returned.
+func RequestTimeout(timeout time.Duration) Option {
+	return func(g *Gateway) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		g.timeout = timeout
+		return nil
+	}
+}
+
+// Events serves s as the event stream of the server called name, see Stream.
+// The request timeout doesn't apply to event streams.
+func Events(name string, s *Stream) Option {
+	return func(g *Gateway) error {
+		if _, ok := g.servers[name]; !ok || s == nil {
+			return ErrUnknownServer
+		}
+		g.streams[name] = s
+		return nil
+	}
+}
+
+// New returns a Gateway serving servers by name. At least one Token Option is required.
+func New(servers map[string]Server, options ...Option) (*Gateway, error) {
+	if len(servers) == 0 {
+		return nil, ErrNoServers
+	}
+
+	g := &Gateway{
+		servers: make(map[string]Server, len(servers)),
+		streams: make(map[string]*Stream),
+		tokens:  make(map[[32]byte]scopes),
+		timeout: defaultRequestTimeout,
+	}
+	for name, s := range servers {
+		if name == "" || strings.Contains(name, "/") || s == nil {
+			return nil, ErrInvalidServer
+		}
+		g.servers[name] = s
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(g.tokens) == 0 {
+		return nil, ErrNoTokens
+	}
+
+	return g, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	events := len(parts) == 3 && parts[2] == "events"
+	granted, ok := g.authenticate(r, events)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="battleye"`)
+		writeError(w, errUnauthorized)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "servers":
+		if r.Method != http.MethodGet {
+			writeError(w, errMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, g.names())
+	case len(parts) == 3 && parts[0] == "servers":
+		g.serveEndpoint(w, r, granted, parts[1], parts[2])
+	default:
+		writeError(w, errNotFound)
+	}
+}
+
+// serveEndpoint handles the operation called op on the server called name.
+func (g *Gateway) serveEndpoint(w http.ResponseWriter, r *http.Request, granted scopes, name, op string) {
+	s, ok := g.servers[name]
+	if !ok {
+		writeError(w, ErrUnknownServer)
+		return
+	}
+	if op == "events" {
+		g.serveEvents(w, r, granted, name)
+		return
+	}
+	e, ok := endpoints[op]
+	if !ok {
+		writeError(w, errNotFound)
+		return
+	}
+	if r.Method != e.method {
+		w.Header().Set("Allow", e.method)
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+	if !granted.allows(e.scope) {
+		writeError(w, errForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), g.timeout)
+	defer cancel()
+
+	v, err := e.handle(ctx, s, r)
+	switch {
+	case err != nil:
+		writeError(w, err)
+	case v == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// serveEvents serves the event stream of the server called name.
+func (g *Gateway) serveEvents(w http.ResponseWriter, r *http.Request, granted scopes, name string) {
+	st, ok := g.streams[name]
+	if !ok {
+		writeError(w, errNotFound)
+		return
+	}
+	if !granted.allows(ScopeEvents) {
+		writeError(w, errForbidden)
+		return
+	}
+	st.ServeHTTP(w, r)
+}
+
+// names returns the sorted names of the servers.
+func (g *Gateway) names() []string {
+	names := make([]string, 0, len(g.servers))
+	for name := range g.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExecRequest is the body of exec requests.
+type ExecRequest struct {
+	// Command is the command to execute.
+	Command string `json:"command"`
+}
+
+// ExecResponse is the body of exec responses.
+type ExecResponse struct {
+	// Command is the executed command.
+	Command string `json:"command"`
+
+	// Response is the response of the server.
+	Response string `json:"response"`
+}
+
+// KickRequest is the body of kick requests.
+type KickRequest struct {
+	// PlayerID is the ID of the player to kick.
+	PlayerID *int `json:"player_id"`
+
+	// Reason is shown to the player.
+	Reason string `json:"reason"`
+}
+
+// SayRequest is the body of say requests.
+type SayRequest struct {
+	// PlayerID is the ID of the player to send the message to or nil to send it to every player.
+	PlayerID *int `json:"player_id"`
+
+	// Message is the message to send.
+	Message string `json:"message"`
+}
+
+// handleExec handles exec requests.
+func handleExec(ctx context.Context, s Server, r *http.Request) (interface{}, error) {
+	var req ExecRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Command) == "" || strings.IndexFunc(req.Command, unicode.IsControl) != -1 {
+		return nil, battleye.ErrInvalidCommand
+	}
+
+	resp, err := s.ExecContext(ctx, req.Command)
+	if err != nil {
+		return nil, err
+	}
+	return ExecResponse{Command: req.Command, Response: resp}, nil
+}
+
+// handlePlayers handles players requests.
+func handlePlayers(ctx context.Context, s Server, _ *http.Request) (interface{}, error) {
+	players, err := s.PlayersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if players == nil {
+		players = []battleye.Player{}
+	}
+	return players, nil
+}
+
+// handleBans handles bans requests.
+func handleBans(ctx context.Context, s Server, _ *http.Request) (interface{}, error) {
+	bans, err := s.BansContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bans == nil {
+		bans = []battleye.Ban{}
+	}
+	return bans, nil
+}
+
+// handleKick handles kick requests.
+func handleKick(ctx context.Context, s Server, r *http.Request) (interface{}, error) {
+	var req KickRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.PlayerID == nil {
+		return nil, battleye.ErrInvalidPlayerID
+	}
+	return nil, s.KickContext(ctx, *req.PlayerID, req.Reason)
+}
+
+// handleSay handles say requests.
+func handleSay(ctx context.Context, s Server, r *http.Request) (interface{}, error) {
+	var req SayRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	if req.PlayerID == nil {
+		return nil, s.BroadcastContext(ctx, req.Message)
+	}
+	return nil, s.WhisperContext(ctx, *req.PlayerID, req.Message)
+}
+
+// handleLock handles lock requests.
+func handleLock(ctx context.Context, s Server, _ *http.Request) (interface{}, error) {
+	return nil, s.LockContext(ctx)
+}
+
+// handleUnlock handles unlock requests.
+func handleUnlock(ctx context.Context, s Server, _ *http.Request) (interface{}, error) {
+	return nil, s.UnlockContext(ctx)
+}
+
+// decode decodes the JSON body of r into v.
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &requestError{err: err}
+	}
+	return nil
+}
+
+// writeJSON writes v as the JSON body of the response with status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint: errcheck
+}
+
+// requestError is returned if the request body is invalid.
+type requestError struct {
+	err error
+}
+
+// Error implements error.
+func (e *requestError) Error() string {
+	return "gateway: invalid request: " + e.err.Error()
+}
+
+// Unwrap returns the decoding error.
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// isRequestError returns true if err is caused by an invalid request.
+func isRequestError(err error) bool {
+	var re *requestError
+	return errors.As(err, &re)
+}
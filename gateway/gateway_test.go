@@ -0,0 +1,402 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	adminToken = "admin-token"
+	readToken  = "read-token"
+)
+
+// fakeServer is a Server recording the calls made to it.
+type fakeServer struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+	block bool
+}
+
+func (s *fakeServer) call(ctx context.Context, call string) error {
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	s.mu.Unlock()
+	if s.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+func (s *fakeServer) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *fakeServer) ExecContext(ctx context.Context, cmd string) (string, error) {
+	if err := s.call(ctx, "exec "+cmd); err != nil {
+		return "", err
+	}
+	return "Response to: " + cmd, nil
+}
+
+func (s *fakeServer) PlayersContext(ctx context.Context) ([]battleye.Player, error) {
+	if err := s.call(ctx, "players"); err != nil {
+		return nil, err
+	}
+	return []battleye.Player{{ID: 3, IP: net.ParseIP("10.0.0.2"), Port: 2304, Ping: 42, Name: "Kerry"}}, nil
+}
+
+func (s *fakeServer) BansContext(ctx context.Context) ([]battleye.Ban, error) {
+	return nil, s.call(ctx, "bans")
+}
+
+func (s *fakeServer) KickContext(ctx context.Context, playerID int, reason string) error {
+	return s.call(ctx, "kick "+strconv.Itoa(playerID)+" "+reason)
+}
+
+func (s *fakeServer) BroadcastContext(ctx context.Context, msg string) error {
+	return s.call(ctx, "broadcast "+msg)
+}
+
+func (s *fakeServer) WhisperContext(ctx context.Context, playerID int, msg string) error {
+	return s.call(ctx, "whisper "+strconv.Itoa(playerID)+" "+msg)
+}
+
+func (s *fakeServer) LockContext(ctx context.Context) error {
+	return s.call(ctx, "lock")
+}
+
+func (s *fakeServer) UnlockContext(ctx context.Context) error {
+	return s.call(ctx, "unlock")
+}
+
+// newTestGateway returns a Gateway serving srv as prod1 and a test HTTP server for it.
+func newTestGateway(t *testing.T, srv *fakeServer, opts ...Option) *httptest.Server {
+	opts = append([]Option{
+		Token(adminToken, ScopeAll),
+		Token(readToken, ScopePlayers, ScopeBans),
+	}, opts...)
+	g, err := New(map[string]Server{"prod1": srv, "prod2": &fakeServer{}}, opts...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ts := httptest.NewServer(g)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// do sends a request and returns the status code and body.
+func do(t *testing.T, ts *httptest.Server, method, path, token, body string) (int, string) {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	b, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, strings.TrimSpace(string(b))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	srv := map[string]Server{"prod1": &fakeServer{}}
+	testcases := []struct {
+		name    string
+		servers map[string]Server
+		opts    []Option
+		expErr  error
+	}{
+		{name: "No servers", opts: []Option{Token("t")}, expErr: ErrNoServers},
+		{name: "Invalid server name", servers: map[string]Server{"a/b": &fakeServer{}}, expErr: ErrInvalidServer},
+		{name: "No tokens", servers: srv, expErr: ErrNoTokens},
+		{name: "Empty token", servers: srv, opts: []Option{Token("")}, expErr: ErrInvalidToken},
+		{name: "Unknown scope", servers: srv, opts: []Option{Token("t", "shutdown")}, expErr: ErrInvalidScope},
+		{name: "Invalid timeout", servers: srv, opts: []Option{Token("t"), RequestTimeout(0)}, expErr: ErrInvalidTimeout},
+		{name: "Nil option", servers: srv, opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Valid", servers: srv, opts: []Option{Token("t", ScopeExec), RequestTimeout(time.Second)}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := New(tc.servers, tc.opts...)
+			if tc.expErr != nil {
+				assert.Nil(t, g)
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, g)
+		})
+	}
+}
+
+func TestGateway(t *testing.T) {
+	testcases := []struct {
+		name      string
+		method    string
+		path      string
+		token     string
+		body      string
+		err       error
+		expStatus int
+		expBody   string
+		expCalls  []string
+	}{
+		{
+			name:      "No token",
+			method:    http.MethodGet,
+			path:      "/servers",
+			expStatus: http.StatusUnauthorized,
+			expBody:   `{"error":"gateway: unauthorized","code":"unauthorized"}`,
+		},
+		{
+			name:      "Wrong token",
+			method:    http.MethodGet,
+			path:      "/servers",
+			token:     "wrong",
+			expStatus: http.StatusUnauthorized,
+			expBody:   `{"error":"gateway: unauthorized","code":"unauthorized"}`,
+		},
+		{
+			name:      "Servers",
+			method:    http.MethodGet,
+			path:      "/servers",
+			token:     readToken,
+			expStatus: http.StatusOK,
+			expBody:   `["prod1","prod2"]`,
+		},
+		{
+			name:      "Unknown server",
+			method:    http.MethodGet,
+			path:      "/servers/prod3/players",
+			token:     readToken,
+			expStatus: http.StatusNotFound,
+			expBody:   `{"error":"gateway: unknown server","code":"unknown_server"}`,
+		},
+		{
+			name:      "Unknown endpoint",
+			method:    http.MethodGet,
+			path:      "/servers/prod1/shutdown",
+			token:     adminToken,
+			expStatus: http.StatusNotFound,
+			expBody:   `{"error":"gateway: not found","code":"not_found"}`,
+		},
+		{
+			name:      "Wrong method",
+			method:    http.MethodGet,
+			path:      "/servers/prod1/lock",
+			token:     adminToken,
+			expStatus: http.StatusMethodNotAllowed,
+			expBody:   `{"error":"gateway: method not allowed","code":"method_not_allowed"}`,
+		},
+		{
+			name:      "Players",
+			method:    http.MethodGet,
+			path:      "/servers/prod1/players",
+			token:     readToken,
+			expStatus: http.StatusOK,
+			expBody:   `[{"id":3,"ip":"10.0.0.2","port":2304,"ping":42,"verified":false,"name":"Kerry","lobby":false}]`,
+			expCalls:  []string{"players"},
+		},
+		{
+			name:      "No bans",
+			method:    http.MethodGet,
+			path:      "/servers/prod1/bans",
+			token:     readToken,
+			expStatus: http.StatusOK,
+			expBody:   `[]`,
+			expCalls:  []string{"bans"},
+		},
+		{
+			name:      "Insufficient scope",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/kick",
+			token:     readToken,
+			body:      `{"player_id":3}`,
+			expStatus: http.StatusForbidden,
+			expBody:   `{"error":"gateway: insufficient scope","code":"insufficient_scope"}`,
+		},
+		{
+			name:      "Exec",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/exec",
+			token:     adminToken,
+			body:      `{"command":"version"}`,
+			expStatus: http.StatusOK,
+			expBody:   `{"command":"version","response":"Response to: version"}`,
+			expCalls:  []string{"exec version"},
+		},
+		{
+			name:      "Exec with newline",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/exec",
+			token:     adminToken,
+			body:      `{"command":"say -1 a\nkick 3"}`,
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"error":"battleye: invalid command","code":"invalid_command"}`,
+		},
+		{
+			name:      "Invalid body",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/exec",
+			token:     adminToken,
+			body:      `{"cmd":"version"}`,
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"error":"gateway: invalid request: json: unknown field \"cmd\"","code":"invalid_request"}`,
+		},
+		{
+			name:      "Kick",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/kick",
+			token:     adminToken,
+			body:      `{"player_id":0,"reason":"AFK"}`,
+			expStatus: http.StatusNoContent,
+			expCalls:  []string{"kick 0 AFK"},
+		},
+		{
+			name:      "Kick without player",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/kick",
+			token:     adminToken,
+			body:      `{"reason":"AFK"}`,
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"error":"battleye: invalid player id","code":"invalid_player_id"}`,
+		},
+		{
+			name:      "Kick unknown player",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/kick",
+			token:     adminToken,
+			body:      `{"player_id":9}`,
+			err:       battleye.ErrPlayerNotFound,
+			expStatus: http.StatusNotFound,
+			expBody:   `{"error":"battleye: player not found","code":"player_not_found"}`,
+			expCalls:  []string{"kick 9 "},
+		},
+		{
+			name:      "Broadcast",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/say",
+			token:     adminToken,
+			body:      `{"message":"restart in 5 minutes"}`,
+			expStatus: http.StatusNoContent,
+			expCalls:  []string{"broadcast restart in 5 minutes"},
+		},
+		{
+			name:      "Whisper",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/say",
+			token:     adminToken,
+			body:      `{"player_id":3,"message":"hi"}`,
+			expStatus: http.StatusNoContent,
+			expCalls:  []string{"whisper 3 hi"},
+		},
+		{
+			name:      "Lock",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/lock",
+			token:     adminToken,
+			expStatus: http.StatusNoContent,
+			expCalls:  []string{"lock"},
+		},
+		{
+			name:      "Unlock",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/unlock",
+			token:     adminToken,
+			expStatus: http.StatusNoContent,
+			expCalls:  []string{"unlock"},
+		},
+		{
+			name:      "Command error",
+			method:    http.MethodPost,
+			path:      "/servers/prod1/lock",
+			token:     adminToken,
+			err:       &battleye.CommandError{Cmd: "#lock", Msg: "Unknown command"},
+			expStatus: http.StatusBadGateway,
+			expBody:   `{"error":"battleye: #lock: Unknown command","code":"command_failed"}`,
+			expCalls:  []string{"lock"},
+		},
+		{
+			name:      "Server timeout",
+			method:    http.MethodGet,
+			path:      "/servers/prod1/players",
+			token:     readToken,
+			err:       battleye.ErrTimeout,
+			expStatus: http.StatusGatewayTimeout,
+			expBody:   `{"error":"battleye: timeout","code":"timeout"}`,
+			expCalls:  []string{"players"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &fakeServer{err: tc.err}
+			ts := newTestGateway(t, srv)
+
+			status, body := do(t, ts, tc.method, tc.path, tc.token, tc.body)
+			assert.Equal(t, tc.expStatus, status)
+			assert.Equal(t, tc.expBody, body)
+			assert.Equal(t, tc.expCalls, srv.Calls())
+		})
+	}
+}
+
+func TestGatewayRequestTimeout(t *testing.T) {
+	srv := &fakeServer{block: true}
+	ts := newTestGateway(t, srv, RequestTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	status, body := do(t, ts, http.MethodPost, "/servers/prod1/exec", adminToken, `{"command":"players"}`)
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Equal(t, `{"error":"context deadline exceeded","code":"timeout"}`, body)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestStatusCode(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		err       error
+		expStatus int
+		expCode   string
+	}{
+		{battleye.ErrMessageTooLong, http.StatusBadRequest, "message_too_long"},
+		{battleye.ErrInvalidGUID, http.StatusBadRequest, "invalid_guid"},
+		{battleye.ErrKickFailed, http.StatusBadGateway, "kick_failed"},
+		{battleye.ErrTimeout, http.StatusGatewayTimeout, "timeout"},
+		{&battleye.CommandError{Cmd: "loadBans", Msg: "Unknown command"}, http.StatusBadGateway, "command_failed"},
+		{context.Canceled, http.StatusServiceUnavailable, "canceled"},
+		{net.ErrClosed, http.StatusServiceUnavailable, "closed"},
+		{errors.New("read udp: connection refused"), http.StatusBadGateway, "server_error"},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expStatus, StatusCode(tc.err), tc.err.Error())
+		assert.Equal(t, tc.expCode, ErrorCode(tc.err), tc.err.Error())
+	}
+}
@@ -0,0 +1,538 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/multiplay/go-battleye/internal/fanout"
+)
+
+const (
+	// defaultSubscriberBuffer is the default number of events buffered for each subscriber.
+	defaultSubscriberBuffer = 100
+
+	// defaultHistory is the default number of events kept for resuming subscribers.
+	defaultHistory = 1000
+
+	// defaultCheckInterval is the default interval of checking the connection to the server.
+	defaultCheckInterval = 30 * time.Second
+
+	// writeTimeout is the maximum duration of writing an event to a WebSocket.
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	// heartbeat is the interval of sending SSE comments and WebSocket pings to idle subscribers,
+	// so that proxies don't close the connection and dead subscribers are noticed.
+	heartbeat = 30 * time.Second
+)
+
+// Stream event types in addition to the server message types returned by event.TypeOf.
+const (
+	// StateType is the type of connection state changes.
+	StateType = "state"
+
+	// GapType is the type of the event sent to a resuming subscriber if events it missed are no
+	// longer in the history.
+	GapType = "gap"
+)
+
+// Connection states.
+const (
+	// StateConnected is the state of a Client which responds to commands.
+	StateConnected = "connected"
+
+	// StateDisconnected is the state of a Client which doesn't respond to commands.
+	StateDisconnected = "disconnected"
+
+	// StateClosed is the state of a closed Client. No more events follow.
+	StateClosed = "closed"
+)
+
+// Source is the interface of the Client a Stream is fed from, which *battleye.Client implements.
+type Source interface {
+	battleye.Source
+	ExecContext(ctx context.Context, cmd string) (string, error)
+}
+
+// StreamEvent is an event sent to Stream subscribers.
+type StreamEvent struct {
+	// ID identifies the event for resuming. It is 0 for events which are not kept in the history.
+	ID uint64 `json:"id,omitempty"`
+
+	// Time is the time the server message was received or the state changed.
+	Time time.Time `json:"time"`
+
+	// Type is StateType, GapType or the type of the server message as returned by event.TypeOf.
+	Type string `json:"type"`
+
+	// Message is the server message.
+	Message string `json:"message,omitempty"`
+
+	// State is the connection state of StateType events.
+	State string `json:"state,omitempty"`
+
+	// Error is the reason of the connection state.
+	Error string `json:"error,omitempty"`
+
+	// Missed is the number of events a resuming subscriber missed in GapType events.
+	Missed uint64 `json:"missed,omitempty"`
+
+	// player is the name of the player the server message is about.
+	player string
+}
+
+// streamFilter selects the events sent to a subscriber.
+type streamFilter struct {
+	types  map[string]bool
+	match  *regexp.Regexp
+	player string
+}
+
+// newStreamFilter returns the filter set by the type, match and player query parameters of r.
+func newStreamFilter(r *http.Request) (*streamFilter, error) {
+	q := r.URL.Query()
+	f := &streamFilter{player: q.Get("player")}
+	if types := q.Get("type"); types != "" {
+		f.types = make(map[string]bool)
+		for _, t := range strings.Split(types, ",") {
+			f.types[strings.TrimSpace(t)] = true
+		}
+	}
+	if match := q.Get("match"); match != "" {
+		var err error
+		if f.match, err = regexp.Compile(match); err != nil {
+			return nil, &requestError{err: err}
+		}
+	}
+	return f, nil
+}
+
+// accept returns true if e passes every filter. Gap events always pass.
+func (f *streamFilter) accept(e StreamEvent) bool {
+	switch {
+	case e.Type == GapType:
+		return true
+	case f.types != nil && !f.types[e.Type]:
+		return false
+	case e.Type == StateType:
+		return true
+	case f.match != nil && !f.match.MatchString(e.Message):
+		return false
+	case f.player != "" && !strings.EqualFold(f.player, e.player):
+		return false
+	}
+	return true
+}
+
+// Stream is an http.Handler streaming the server messages and connection state changes of a
+// Client to many subscribers over Server-Sent Events or WebSocket.
+//
+// Every event is sent as JSON StreamEvent. Subscribers select events with the query parameters
+// type (comma separated types), match (regular expression matched against the server message)
+// and player (player name).
+//
+// Subscribers resume with the Last-Event-ID header, which browsers set when reconnecting an
+// EventSource, or the last_event_id query parameter. Events after it are replayed from the
+// history and a GapType event tells if some are no longer available.
+//
+// Subscribers which don't keep up are disconnected, so that they resume from the history rather
+// than silently missing events.
+type Stream struct {
+	src       Source
+	handler   battleye.HandlerID
+	bufSize   int
+	interval  time.Duration
+	upgrader  websocket.Upgrader
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	// hub sends the events to the subscribers. Its lock guards the fields below, so that
+	// subscribers resume from a history consistent with the events they are sent.
+	hub    *fanout.Hub[StreamEvent]
+	ring   []StreamEvent
+	head   int
+	n      int
+	lastID uint64
+	state  StreamEvent
+}
+
+// StreamOption is a Stream configuration Option type.
+type StreamOption func(s *Stream) error
+
+// SubscriberBuffer sets the number of events buffered for each subscriber.
+func SubscriberBuffer(size int) StreamOption {
+	return func(s *Stream) error {
+		if size < 1 {
+			return ErrInvalidBufferSize
+		}
+		s.bufSize = size
+		return nil
+	}
+}
+
+// History sets the number of events kept for resuming subscribers. 0 disables resuming.
+func History(size int) StreamOption {
+	return func(s *Stream) error {
+		if size < 0 {
+			return ErrInvalidHistorySize
+		}
+		s.ring = make([]StreamEvent, size)
+		return nil
+	}
+}
+
+// CheckInterval sets the interval of checking the connection to the server, which detects
+// connection state changes. 0 disables checking.
+func CheckInterval(interval time.Duration) StreamOption {
+	return func(s *Stream) error {
+		if interval < 0 {
+			return ErrInvalidCheckInterval
+		}
+		s.interval = interval
+		return nil
+	}
+}
+
+// CheckOrigin sets the function deciding whether WebSocket connections are accepted from the
+// Origin of r. By default only connections from the same host are accepted.
+func CheckOrigin(f func(r *http.Request) bool) StreamOption {
+	return func(s *Stream) error {
+		s.upgrader.CheckOrigin = f
+		return nil
+	}
+}
+
+// NewStream returns a new Stream of the events of src. The Stream subscribes to the messages of
+// src, which can be shared with other consumers.
+func NewStream(src Source, options ...StreamOption) (*Stream, error) {
+	s := &Stream{
+		src:      src,
+		bufSize:  defaultSubscriberBuffer,
+		interval: defaultCheckInterval,
+		ring:     make([]StreamEvent, defaultHistory),
+		done:     make(chan struct{}),
+		state:    StreamEvent{Type: StateType, State: StateConnected, Time: time.Now()},
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	s.hub = fanout.New[StreamEvent](s.bufSize)
+	s.handler = src.OnMessage(s.handle)
+	s.wg.Add(1)
+	go s.run()
+	if s.interval > 0 {
+		s.wg.Add(1)
+		go s.check()
+	}
+
+	return s, nil
+}
+
+// Close stops s and disconnects the subscribers.
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		s.src.Unregister(s.handler)
+		close(s.done)
+	})
+	s.wg.Wait()
+	s.hub.Close()
+}
+
+// ServeHTTP implements http.Handler. WebSocket upgrade requests are served over WebSocket and
+// every other request over Server-Sent Events.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+
+	filter, err := newStreamFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	lastID, resume, err := lastEventID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWebSocket(w, r, filter, lastID, resume)
+		return
+	}
+	s.serveSSE(w, r, filter, lastID, resume)
+}
+
+// lastEventID returns the ID of the last event received by a resuming subscriber and false if
+// the subscriber is not resuming.
+func lastEventID(r *http.Request) (uint64, bool, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, false, &requestError{err: fmt.Errorf("invalid last event id %q", v)}
+	}
+	return id, true, nil
+}
+
+// serveSSE streams events as Server-Sent Events.
+func (s *Stream) serveSSE(w http.ResponseWriter, r *http.Request, filter *streamFilter, lastID uint64, resume bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errStreamingUnsupported)
+		return
+	}
+
+	sub, backlog := s.subscribe(filter, lastID, resume)
+	defer s.hub.Unsubscribe(sub)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range backlog {
+		if err := writeSSE(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	t := time.NewTicker(heartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, e); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSE writes e as a Server-Sent Event named after its type.
+func writeSSE(w http.ResponseWriter, e StreamEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if e.ID != 0 {
+		if _, err := fmt.Fprintf(w, "id: %v\n", e.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %v\ndata: %s\n\n", e.Type, b)
+	return err
+}
+
+// serveWebSocket streams events as WebSocket text messages.
+func (s *Stream) serveWebSocket(w http.ResponseWriter, r *http.Request, filter *streamFilter, lastID uint64, resume bool) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has replied with an error.
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+
+	sub, backlog := s.subscribe(filter, lastID, resume)
+	defer s.hub.Unsubscribe(sub)
+
+	// Read to process control frames and notice the subscriber closing the connection.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(e StreamEvent) error {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout)) // nolint: errcheck
+		return conn.WriteJSON(e)
+	}
+	for _, e := range backlog {
+		if err := write(e); err != nil {
+			return
+		}
+	}
+
+	t := time.NewTicker(heartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case <-t.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case e, ok := <-sub.C():
+			if !ok {
+				code, reason := websocket.CloseGoingAway, "stream closed"
+				if sub.Slow() {
+					code, reason = websocket.CloseTryAgainLater, "subscriber too slow, resume from last event id"
+				}
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeTimeout)) // nolint: errcheck
+				return
+			}
+			if err := write(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns it with the events to send before the ones
+// received on its channel: a gap event if events after lastID are no longer in the history, the
+// events after lastID if resuming and the current connection state.
+func (s *Stream) subscribe(filter *streamFilter, lastID uint64, resume bool) (*fanout.Subscriber[StreamEvent], []StreamEvent) {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+
+	var backlog []StreamEvent
+	if resume && lastID < s.lastID {
+		oldest := s.lastID + 1
+		if s.n > 0 {
+			oldest = s.ring[s.head].ID
+		}
+		if lastID+1 < oldest {
+			backlog = append(backlog, StreamEvent{Type: GapType, Time: time.Now(), Missed: oldest - lastID - 1})
+		}
+		for i := 0; i < s.n; i++ {
+			if e := s.ring[(s.head+i)%len(s.ring)]; e.ID > lastID && filter.accept(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	if filter.accept(s.state) {
+		state := s.state
+		state.ID = 0
+		backlog = append(backlog, state)
+	}
+
+	return s.hub.SubscribeLocked(filter.accept), backlog
+}
+
+// publish adds e to the history and sends it to the subscribers.
+func (s *Stream) publish(e StreamEvent) {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+
+	s.publishLocked(e)
+}
+
+// publishLocked adds e to the history and sends it to the subscribers. Subscribers whose buffer is
+// full are dropped.
+// The lock of hub must be held.
+func (s *Stream) publishLocked(e StreamEvent) {
+	s.lastID++
+	e.ID = s.lastID
+	if size := len(s.ring); size > 0 {
+		if s.n < size {
+			s.ring[(s.head+s.n)%size] = e
+			s.n++
+		} else {
+			s.ring[s.head] = e
+			s.head = (s.head + 1) % size
+		}
+	}
+	if e.Type == StateType {
+		s.state = e
+	}
+	s.hub.PublishLocked(e)
+}
+
+// handle publishes the server message m.
+func (s *Stream) handle(m battleye.Message) {
+	s.publish(StreamEvent{Time: m.Time, Type: event.TypeOf(m.Event), Message: m.Event.Raw(), player: event.PlayerName(m.Event)})
+}
+
+// run is a goroutine which closes the subscribers when src is done.
+func (s *Stream) run() {
+	defer s.wg.Done()
+
+	select {
+	case <-s.done:
+	case <-s.src.Done():
+		s.setState(StateClosed, nil)
+		s.hub.Close()
+	}
+}
+
+// check is a goroutine which periodically checks the connection to the server.
+func (s *Stream) check() {
+	defer s.wg.Done()
+
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			_, err := s.src.ExecContext(ctx, "")
+			cancel()
+			if err != nil {
+				s.setState(StateDisconnected, err)
+			} else {
+				s.setState(StateConnected, nil)
+			}
+		}
+	}
+}
+
+// setState publishes a state event if state differs from the current one.
+func (s *Stream) setState(state string, err error) {
+	s.hub.Lock()
+	defer s.hub.Unlock()
+
+	if s.state.State == state || s.state.State == StateClosed {
+		return
+	}
+	e := StreamEvent{Time: time.Now(), Type: StateType, State: state}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	s.publishLocked(e)
+}
@@ -0,0 +1,412 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSource is a Source fed by the test.
+type fakeSource struct {
+	done chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	handlers map[battleye.HandlerID]func(battleye.Message)
+	nextID   battleye.HandlerID
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{done: make(chan struct{}), handlers: make(map[battleye.HandlerID]func(battleye.Message))}
+}
+
+func (s *fakeSource) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handlers[s.nextID] = f
+	return s.nextID
+}
+
+func (s *fakeSource) Unregister(id battleye.HandlerID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.handlers[id]
+	delete(s.handlers, id)
+	return ok
+}
+
+func (s *fakeSource) Done() <-chan struct{} {
+	return s.done
+}
+
+// send calls the registered handlers with msg.
+func (s *fakeSource) send(msg string) {
+	s.mu.Lock()
+	handlers := make([]func(battleye.Message), 0, len(s.handlers))
+	for _, f := range s.handlers {
+		handlers = append(handlers, f)
+	}
+	s.mu.Unlock()
+
+	m := battleye.Message{Time: time.Now(), Event: event.Parse(msg)}
+	for _, f := range handlers {
+		f(m)
+	}
+}
+
+func (s *fakeSource) ExecContext(ctx context.Context, cmd string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return "", s.err
+}
+
+func (s *fakeSource) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// newTestStream returns a Stream of src and a test HTTP server for it.
+func newTestStream(t *testing.T, src Source, opts ...StreamOption) (*Stream, *httptest.Server) {
+	st, err := NewStream(src, opts...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ts := httptest.NewServer(st)
+	t.Cleanup(func() {
+		ts.Close()
+		st.Close()
+	})
+	return st, ts
+}
+
+// sseEvent is an event as received over SSE.
+type sseEvent struct {
+	id    string
+	event string
+	data  StreamEvent
+}
+
+// sseClient reads Server-Sent Events.
+type sseClient struct {
+	resp   *http.Response
+	events chan sseEvent
+}
+
+// subscribeSSE subscribes to the stream at url.
+func subscribeSSE(t *testing.T, url string, header http.Header) *sseClient {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { resp.Body.Close() }) // nolint: errcheck
+
+	c := &sseClient{resp: resp, events: make(chan sseEvent, 100)}
+	go func() {
+		defer close(c.events)
+		sc := bufio.NewScanner(resp.Body)
+		var e sseEvent
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if e.event != "" {
+					c.events <- e
+				}
+				e = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				e.id = line[4:]
+			case strings.HasPrefix(line, "event: "):
+				e.event = line[7:]
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(line[6:]), &e.data) // nolint: errcheck
+			}
+		}
+	}()
+	return c
+}
+
+// next returns the next event or fails after a timeout.
+func (c *sseClient) next(t *testing.T) sseEvent {
+	select {
+	case e, ok := <-c.events:
+		if !ok {
+			assert.Fail(t, "stream closed")
+			t.FailNow()
+		}
+		return e
+	case <-time.After(testStreamTimeout):
+		assert.Fail(t, "no event received")
+		t.FailNow()
+	}
+	return sseEvent{}
+}
+
+const testStreamTimeout = 2 * time.Second
+
+func TestNewStream(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		opts   []StreamOption
+		expErr error
+	}{
+		{name: "Invalid buffer", opts: []StreamOption{SubscriberBuffer(0)}, expErr: ErrInvalidBufferSize},
+		{name: "Invalid history", opts: []StreamOption{History(-1)}, expErr: ErrInvalidHistorySize},
+		{name: "Invalid check interval", opts: []StreamOption{CheckInterval(-time.Second)}, expErr: ErrInvalidCheckInterval},
+		{name: "Nil option", opts: []StreamOption{nil}, expErr: ErrNilOption},
+		{name: "Valid", opts: []StreamOption{SubscriberBuffer(10), History(0), CheckInterval(0)}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			st, err := NewStream(newFakeSource(), tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				st.Close()
+			}
+		})
+	}
+}
+
+func TestStreamSSE(t *testing.T) {
+	src := newFakeSource()
+	_, ts := newTestStream(t, src, CheckInterval(0))
+
+	all := subscribeSSE(t, ts.URL, nil)
+	chat := subscribeSSE(t, ts.URL+"?type=chat,state&player=kerry", nil)
+	assert.Equal(t, "text/event-stream", all.resp.Header.Get("Content-Type"))
+
+	// The current state is sent first.
+	e := all.next(t)
+	assert.Equal(t, "", e.id)
+	assert.Equal(t, StateType, e.event)
+	assert.Equal(t, StateConnected, e.data.State)
+	assert.Equal(t, StateType, chat.next(t).event)
+
+	src.send("Player #1 Kerry (10.0.0.2:2304) connected")
+	src.send("(Global) Miller: hi")
+	src.send("(Global) Kerry: hello")
+
+	e = all.next(t)
+	assert.Equal(t, "1", e.id)
+	assert.Equal(t, "player_connected", e.event)
+	assert.Equal(t, "Player #1 Kerry (10.0.0.2:2304) connected", e.data.Message)
+	assert.Equal(t, "chat", all.next(t).event)
+	assert.Equal(t, "3", all.next(t).id)
+
+	// Filtered by type and player.
+	e = chat.next(t)
+	assert.Equal(t, "3", e.id)
+	assert.Equal(t, "(Global) Kerry: hello", e.data.Message)
+}
+
+func TestStreamResume(t *testing.T) {
+	src := newFakeSource()
+	_, ts := newTestStream(t, src, CheckInterval(0), History(3))
+
+	// Wait for the subscriber to be registered, so that no message is published before it.
+	sub := subscribeSSE(t, ts.URL, nil)
+	sub.next(t)
+	for i := 1; i <= 5; i++ {
+		src.send("message " + strconv.Itoa(i))
+		sub.next(t)
+	}
+
+	testcases := []struct {
+		name      string
+		header    http.Header
+		query     string
+		expEvents []string
+		expMissed uint64
+	}{
+		{
+			name:      "From history",
+			header:    http.Header{"Last-Event-Id": {"3"}},
+			expEvents: []string{"4", "5", ""},
+		},
+		{
+			name:      "By query parameter",
+			query:     "?last_event_id=4",
+			expEvents: []string{"5", ""},
+		},
+		{
+			name:      "Missed events",
+			header:    http.Header{"Last-Event-Id": {"1"}},
+			expEvents: []string{"", "3", "4", "5", ""},
+			expMissed: 1,
+		},
+		{
+			name:      "Up to date",
+			header:    http.Header{"Last-Event-Id": {"5"}},
+			expEvents: []string{""},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := subscribeSSE(t, ts.URL+tc.query, tc.header)
+			var ids []string
+			for range tc.expEvents {
+				e := c.next(t)
+				ids = append(ids, e.id)
+				if e.event == GapType {
+					assert.Equal(t, tc.expMissed, e.data.Missed)
+				}
+			}
+			assert.Equal(t, tc.expEvents, ids)
+		})
+	}
+
+	status, body := do(t, ts, http.MethodGet, "/?last_event_id=x", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "invalid_request")
+}
+
+func TestStreamSlowSubscriber(t *testing.T) {
+	src := newFakeSource()
+	st, err := NewStream(src, CheckInterval(0), SubscriberBuffer(1))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer st.Close()
+
+	sub, _ := st.subscribe(&streamFilter{}, 0, false)
+	src.send("message 1")
+	src.send("message 2")
+
+	assert.Equal(t, "message 1", (<-sub.C()).Message)
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+	assert.True(t, sub.Slow())
+}
+
+func TestStreamState(t *testing.T) {
+	src := newFakeSource()
+	_, ts := newTestStream(t, src, CheckInterval(10*time.Millisecond))
+
+	c := subscribeSSE(t, ts.URL+"?type=state", nil)
+	assert.Equal(t, StateConnected, c.next(t).data.State)
+
+	src.setErr(errors.New("battleye: timeout"))
+	e := c.next(t)
+	assert.Equal(t, StateDisconnected, e.data.State)
+	assert.Equal(t, "battleye: timeout", e.data.Error)
+
+	src.setErr(nil)
+	assert.Equal(t, StateConnected, c.next(t).data.State)
+
+	close(src.done)
+	assert.Equal(t, StateClosed, c.next(t).data.State)
+	select {
+	case _, ok := <-c.events:
+		assert.False(t, ok)
+	case <-time.After(testStreamTimeout):
+		assert.Fail(t, "stream not closed")
+	}
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	src := newFakeSource()
+	_, ts := newTestStream(t, src, CheckInterval(0))
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "?type=chat,state"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+
+	// The current state is sent once subscribed.
+	var e StreamEvent
+	conn.SetReadDeadline(time.Now().Add(testStreamTimeout)) // nolint: errcheck
+	if !assert.NoError(t, conn.ReadJSON(&e)) {
+		return
+	}
+	assert.Equal(t, StateConnected, e.State)
+
+	src.send("(Global) Kerry: hello")
+	if !assert.NoError(t, conn.ReadJSON(&e)) {
+		return
+	}
+	assert.Equal(t, uint64(1), e.ID)
+	assert.Equal(t, "chat", e.Type)
+	assert.Equal(t, "(Global) Kerry: hello", e.Message)
+
+	close(src.done)
+	if assert.NoError(t, conn.ReadJSON(&e)) {
+		assert.Equal(t, StateClosed, e.State)
+	}
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+}
+
+func TestGatewayEvents(t *testing.T) {
+	src := newFakeSource()
+	st, err := NewStream(src, CheckInterval(0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer st.Close()
+
+	ts := newTestGateway(t, &fakeServer{}, Token("events-token", ScopeEvents), Events("prod1", st))
+
+	testcases := []struct {
+		name      string
+		path      string
+		token     string
+		expStatus int
+	}{
+		{name: "No token", path: "/servers/prod1/events", expStatus: http.StatusUnauthorized},
+		{name: "Insufficient scope", path: "/servers/prod1/events?access_token=" + readToken, expStatus: http.StatusForbidden},
+		{name: "No stream", path: "/servers/prod2/events?access_token=events-token", expStatus: http.StatusNotFound},
+		{name: "Query token", path: "/servers/prod1/events?access_token=events-token", expStatus: http.StatusOK},
+		{name: "Header token", path: "/servers/prod1/events", token: "events-token", expStatus: http.StatusOK},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.token != "" {
+				header.Set("Authorization", "Bearer "+tc.token)
+			}
+			c := subscribeSSE(t, ts.URL+tc.path, header)
+			assert.Equal(t, tc.expStatus, c.resp.StatusCode)
+			if tc.expStatus == http.StatusOK {
+				assert.Equal(t, StateType, c.next(t).event)
+			}
+		})
+	}
+
+	// Query tokens are only accepted for event streams.
+	status, _ := do(t, ts, http.MethodGet, "/servers?access_token=events-token", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	_, err = New(map[string]Server{"prod1": &fakeServer{}}, Token("t"), Events("prod2", st))
+	assert.Equal(t, ErrUnknownServer, err)
+}
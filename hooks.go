@@ -0,0 +1,153 @@
+package battleye
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Hooks are functions called on the activity of a Client, e.g. to collect metrics, see AddHooks.
+// Nil functions are skipped. Hooks are called synchronously by the Client, so they must not block
+// nor call the Client.
+type Hooks struct {
+	// Login is called with the result of connecting and logging in to the server, which is nil
+	// on success.
+	Login func(err error)
+
+	// CommandStart is called before a command is executed, including the empty commands sent to
+	// keep the connection alive, with the context of the command. It returns the context to
+	// execute the command with, e.g. with a span, which is also the Context of the CommandInfo.
+	CommandStart func(ctx context.Context, cmd string) context.Context
+
+	// CommandDone is called when a command has been executed, including the empty commands sent
+	// to keep the connection alive.
+	CommandDone func(info CommandInfo)
+
+	// ServerMessage is called with each message received from the server, parsed into an event.
+	ServerMessage func(m Message)
+
+	// MessageDropped is called with a server message which was dropped because the Messages
+	// channel was full.
+	MessageDropped func(msg string)
+
+	// PacketError is called with the error of a packet which couldn't be parsed, e.g.
+	// ErrInvalidChecksum.
+	PacketError func(err error)
+
+	// Closed is called when the Client is closed.
+	Closed func()
+}
+
+// CommandInfo describes the execution of a command.
+type CommandInfo struct {
+	// Context is the context the command was executed with, as returned by CommandStart.
+	Context context.Context
+
+	// Command is the executed command.
+	Command string
+
+	// Seq is the sequence number the command was last sent with.
+	Seq byte
+
+	// Attempts is the number of times the command was sent, which is more than 1 if it was
+	// retried after a timeout, or 0 if it wasn't sent.
+	Attempts int
+
+	// Fragments is the number of packets the response was received in, or 0 if no response
+	// was received.
+	Fragments int
+
+	// Duration is the time taken to execute the command, including waiting for the previous
+	// command to complete.
+	Duration time.Duration
+
+	// RoundTrip is the time from the last send of the command to its response, or 0 if no
+	// response was received.
+	RoundTrip time.Duration
+
+	// Err is the error the command returned.
+	Err error
+}
+
+// Verb returns the first word of the command, or an empty string if the command is empty.
+func (i CommandInfo) Verb() string {
+	if f := strings.Fields(i.Command); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// AddHooks adds functions called on the activity of the Client. It can be used several times.
+func AddHooks(h *Hooks) Option {
+	return func(c *Client) error {
+		if h == nil {
+			return ErrNilOption
+		}
+		c.hooks = append(c.hooks, h)
+		return nil
+	}
+}
+
+// loginDone calls the Login hooks.
+func (c *Client) loginDone(err error) {
+	for _, h := range c.hooks {
+		if h.Login != nil {
+			h.Login(err)
+		}
+	}
+}
+
+// commandStart calls the CommandStart hooks and returns the context to execute cmd with.
+func (c *Client) commandStart(ctx context.Context, cmd string) context.Context {
+	for _, h := range c.hooks {
+		if h.CommandStart != nil {
+			ctx = h.CommandStart(ctx, cmd)
+		}
+	}
+	return ctx
+}
+
+// commandDone calls the CommandDone hooks.
+func (c *Client) commandDone(info CommandInfo) {
+	for _, h := range c.hooks {
+		if h.CommandDone != nil {
+			h.CommandDone(info)
+		}
+	}
+}
+
+// serverMessage calls the ServerMessage hooks.
+func (c *Client) serverMessage(m Message) {
+	for _, h := range c.hooks {
+		if h.ServerMessage != nil {
+			h.ServerMessage(m)
+		}
+	}
+}
+
+// messageDropped calls the MessageDropped hooks.
+func (c *Client) messageDropped(msg string) {
+	for _, h := range c.hooks {
+		if h.MessageDropped != nil {
+			h.MessageDropped(msg)
+		}
+	}
+}
+
+// packetError calls the PacketError hooks.
+func (c *Client) packetError(err error) {
+	for _, h := range c.hooks {
+		if h.PacketError != nil {
+			h.PacketError(err)
+		}
+	}
+}
+
+// closed calls the Closed hooks.
+func (c *Client) closed() {
+	for _, h := range c.hooks {
+		if h.Closed != nil {
+			h.Closed()
+		}
+	}
+}
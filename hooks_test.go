@@ -0,0 +1,144 @@
+package battleye
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hookKey is the context key of the value added by the CommandStart hook of recordedHooks.
+type hookKey struct{}
+
+// recordedHooks records the calls to its Hooks.
+type recordedHooks struct {
+	mu       sync.Mutex
+	logins   []error
+	commands []CommandInfo
+	messages int
+	dropped  int
+	errs     []error
+	closed   int
+}
+
+func (r *recordedHooks) hooks() *Hooks {
+	return &Hooks{
+		Login: func(err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.logins = append(r.logins, err)
+		},
+		CommandStart: func(ctx context.Context, cmd string) context.Context {
+			return context.WithValue(ctx, hookKey{}, cmd)
+		},
+		CommandDone: func(info CommandInfo) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if info.Command != "" {
+				r.commands = append(r.commands, info)
+			}
+		},
+		ServerMessage: func(m Message) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.messages++
+		},
+		MessageDropped: func(msg string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.dropped++
+		},
+		PacketError: func(err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.errs = append(r.errs, err)
+		},
+		Closed: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.closed++
+		},
+	}
+}
+
+func (r *recordedHooks) droppedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+func TestHooks(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	var failed recordedHooks
+	_, err := NewClient(s.Addr, "wrong", Timeout(testTimeout), AddHooks(failed.hooks()))
+	assert.Equal(t, ErrLoginFailed, err)
+	assert.Equal(t, []error{ErrLoginFailed}, failed.logins)
+	assert.Equal(t, 1, failed.closed)
+
+	_, err = NewClient(s.Addr, testPassword, AddHooks(nil))
+	assert.Equal(t, ErrNilOption, err)
+
+	var r recordedHooks
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout), MessageBuffer(1), AddHooks(r.hooks()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []error{nil}, r.logins)
+
+	_, err = c.Exec("players")
+	assert.NoError(t, err)
+	s.SetMultiPacketResponse("part 1* part 2* part 3")
+	_, err = c.Exec("bans")
+	assert.NoError(t, err)
+
+	// Messages are not read, so all but the first are dropped.
+	assert.Eventually(t, func() bool { return r.droppedCount() > 0 }, 2*testTimeout, 10*time.Millisecond)
+
+	// Invalid packets are reported to the next command.
+	s.clients.Range(func(_, v interface{}) bool {
+		_, err := s.pc.WriteTo([]byte("invalid packet"), v.(net.Addr))
+		return assert.NoError(t, err)
+	})
+	_, err = c.Exec("missions")
+	assert.Equal(t, ErrInvalidHeader, err)
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, c.Close())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if assert.Len(t, r.commands, 3) {
+		assert.Equal(t, "players", r.commands[0].Verb())
+		assert.Equal(t, "players", r.commands[0].Context.Value(hookKey{}))
+		assert.Equal(t, 1, r.commands[0].Attempts)
+		assert.Equal(t, 1, r.commands[0].Fragments)
+		assert.NoError(t, r.commands[0].Err)
+		assert.True(t, r.commands[0].Duration > 0)
+		assert.True(t, r.commands[0].RoundTrip > 0 && r.commands[0].RoundTrip <= r.commands[0].Duration)
+		assert.Equal(t, r.commands[0].Seq+1, r.commands[1].Seq)
+		assert.Equal(t, 3, r.commands[1].Fragments)
+		assert.Equal(t, ErrInvalidHeader, r.commands[2].Err)
+		assert.Equal(t, 0, r.commands[2].Fragments)
+		assert.Zero(t, r.commands[2].RoundTrip)
+	}
+	assert.True(t, r.messages > r.dropped)
+	assert.Equal(t, []error{ErrInvalidHeader}, r.errs)
+	assert.Equal(t, 1, r.closed)
+}
+
+func TestCommandInfoVerb(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "say", CommandInfo{Command: "say -1 hello"}.Verb())
+	assert.Equal(t, "players", CommandInfo{Command: "players"}.Verb())
+	assert.Equal(t, "", CommandInfo{}.Verb())
+}
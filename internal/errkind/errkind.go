@@ -0,0 +1,86 @@
+// Package errkind classifies the errors returned by Clients, so that the packages serving them
+// over different protocols, e.g. HTTP and gRPC, report the same errors alike.
+package errkind
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	battleye "github.com/multiplay/go-battleye"
+)
+
+// Kind is the class of an error.
+type Kind int
+
+// Kinds of errors.
+const (
+	// Unknown is the Kind of errors which are not classified.
+	Unknown Kind = iota
+
+	// Invalid is the Kind of errors caused by invalid arguments.
+	Invalid
+
+	// NotFound is the Kind of errors caused by a player which doesn't exist.
+	NotFound
+
+	// Rejected is the Kind of errors caused by the server not carrying out a valid command.
+	Rejected
+
+	// CommandFailed is the Kind of *battleye.CommandError, the failure replies of the server.
+	CommandFailed
+
+	// BadResponse is the Kind of errors caused by a response which can't be parsed.
+	BadResponse
+
+	// LoginFailed is the Kind of errors caused by the server refusing the password.
+	LoginFailed
+
+	// Timeout is the Kind of errors caused by the server not responding in time.
+	Timeout
+
+	// Canceled is the Kind of errors caused by the command being cancelled.
+	Canceled
+
+	// Closed is the Kind of errors caused by the Client being closed.
+	Closed
+)
+
+var (
+	// kinds are the Kinds of errors.
+	kinds = map[error]Kind{
+		battleye.ErrInvalidPlayerID:    Invalid,
+		battleye.ErrInvalidGUID:        Invalid,
+		battleye.ErrInvalidBanDuration: Invalid,
+		battleye.ErrEmptyMessage:       Invalid,
+		battleye.ErrMessageTooLong:     Invalid,
+		battleye.ErrInvalidCommand:     Invalid,
+		battleye.ErrCommandTooLong:     Invalid,
+		battleye.ErrInvalidMission:     Invalid,
+		battleye.ErrInvalidPing:        Invalid,
+		battleye.ErrNotConfirmed:       Invalid,
+		battleye.ErrPlayerNotFound:     NotFound,
+		battleye.ErrKickFailed:         Rejected,
+		battleye.ErrUnexpectedResponse: BadResponse,
+		battleye.ErrLoginFailed:        LoginFailed,
+		battleye.ErrTimeout:            Timeout,
+		context.DeadlineExceeded:       Timeout,
+		context.Canceled:               Canceled,
+		net.ErrClosed:                  Closed,
+	}
+)
+
+// Of returns the Kind of err and the error in kinds it matches, which is nil if there is none,
+// as for *battleye.CommandError.
+func Of(err error) (Kind, error) {
+	var ce *battleye.CommandError
+	if errors.As(err, &ce) {
+		return CommandFailed, nil
+	}
+	for e, k := range kinds {
+		if errors.Is(err, e) {
+			return k, e
+		}
+	}
+	return Unknown, nil
+}
@@ -0,0 +1,36 @@
+package errkind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		err     error
+		expKind Kind
+		expErr  error
+	}{
+		{name: "Invalid", err: battleye.ErrInvalidGUID, expKind: Invalid, expErr: battleye.ErrInvalidGUID},
+		{name: "Wrapped", err: fmt.Errorf("kick: %w", battleye.ErrPlayerNotFound), expKind: NotFound, expErr: battleye.ErrPlayerNotFound},
+		{name: "Command failed", err: &battleye.CommandError{Cmd: "loadBans", Msg: "Unknown command"}, expKind: CommandFailed},
+		{name: "Timeout", err: context.DeadlineExceeded, expKind: Timeout, expErr: context.DeadlineExceeded},
+		{name: "Unknown", err: errors.New("boom"), expKind: Unknown},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, err := Of(tc.err)
+			assert.Equal(t, tc.expKind, k)
+			assert.Equal(t, tc.expErr, err)
+		})
+	}
+}
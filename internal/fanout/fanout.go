@@ -0,0 +1,112 @@
+// Package fanout sends events to many subscribers, dropping subscribers which don't keep up
+// rather than blocking the others. It's shared by the packages streaming server messages.
+package fanout
+
+import (
+	"sync"
+)
+
+// Subscriber receives the events of a Hub which it accepts.
+type Subscriber[E any] struct {
+	accept func(E) bool
+	ch     chan E
+
+	// slow is set if the subscriber was dropped because its buffer was full.
+	slow bool
+}
+
+// C returns the channel receiving the events. It's closed when the subscriber is unsubscribed
+// or dropped, or the Hub is closed.
+func (s *Subscriber[E]) C() <-chan E {
+	return s.ch
+}
+
+// Slow returns true if s was dropped because its buffer was full. It must only be called once C
+// is closed.
+func (s *Subscriber[E]) Slow() bool {
+	return s.slow
+}
+
+// Hub sends events to its subscribers.
+//
+// Hub embeds the mutex guarding its subscribers, so that users can keep their own state, e.g. a
+// history of the events, consistent with them by holding it while calling the Locked methods.
+type Hub[E any] struct {
+	sync.Mutex
+
+	bufSize int
+	subs    map[*Subscriber[E]]struct{}
+	closed  bool
+}
+
+// New returns a new Hub buffering bufSize events for each subscriber.
+func New[E any](bufSize int) *Hub[E] {
+	return &Hub[E]{bufSize: bufSize, subs: make(map[*Subscriber[E]]struct{})}
+}
+
+// Subscribe returns a new subscriber of the events accept returns true for. If h is closed the
+// channel of the subscriber is closed.
+func (h *Hub[E]) Subscribe(accept func(E) bool) *Subscriber[E] {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.SubscribeLocked(accept)
+}
+
+// SubscribeLocked is Subscribe for callers holding the lock of h.
+func (h *Hub[E]) SubscribeLocked(accept func(E) bool) *Subscriber[E] {
+	sub := &Subscriber[E]{accept: accept, ch: make(chan E, h.bufSize)}
+	if h.closed {
+		close(sub.ch)
+	} else {
+		h.subs[sub] = struct{}{}
+	}
+	return sub
+}
+
+// Unsubscribe removes sub if it's still subscribed.
+func (h *Hub[E]) Unsubscribe(sub *Subscriber[E]) {
+	h.Lock()
+	defer h.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish sends e to the subscribers accepting it. Subscribers whose buffer is full are dropped.
+func (h *Hub[E]) Publish(e E) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PublishLocked(e)
+}
+
+// PublishLocked is Publish for callers holding the lock of h.
+func (h *Hub[E]) PublishLocked(e E) {
+	for sub := range h.subs {
+		if !sub.accept(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.slow = true
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close unsubscribes every subscriber and closes the channel of new ones.
+func (h *Hub[E]) Close() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
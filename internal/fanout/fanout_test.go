@@ -0,0 +1,58 @@
+package fanout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	t.Parallel()
+
+	h := New[int](2)
+	all := h.Subscribe(func(int) bool { return true })
+	even := h.Subscribe(func(e int) bool { return e%2 == 0 })
+	gone := h.Subscribe(func(int) bool { return true })
+	h.Unsubscribe(gone)
+	h.Unsubscribe(gone)
+
+	h.Publish(1)
+	h.Publish(2)
+	assert.Equal(t, 1, <-all.C())
+	assert.Equal(t, 2, <-all.C())
+	assert.Equal(t, 2, <-even.C())
+	_, ok := <-gone.C()
+	assert.False(t, ok)
+	assert.False(t, gone.Slow())
+
+	// A subscriber whose buffer is full is dropped without affecting the others.
+	for i := 3; i <= 6; i++ {
+		h.Publish(i)
+	}
+	assert.Equal(t, []int{3, 4}, drain(all))
+	assert.True(t, all.Slow())
+	assert.Equal(t, []int{4, 6}, drain(even))
+	assert.False(t, even.Slow())
+
+	h.Close()
+	_, ok = <-even.C()
+	assert.False(t, ok)
+	_, ok = <-h.Subscribe(func(int) bool { return true }).C()
+	assert.False(t, ok)
+}
+
+// drain returns the events received by sub until its channel is closed or empty.
+func drain(sub *Subscriber[int]) []int {
+	var events []int
+	for {
+		select {
+		case e, ok := <-sub.C():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
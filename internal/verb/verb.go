@@ -0,0 +1,35 @@
+// Package verb bounds the verbs of commands to the ones of known BattlEye RCON commands, so that
+// they can be used where the number of distinct values must be small, e.g. metric labels and
+// span names.
+package verb
+
+import (
+	"strings"
+)
+
+const (
+	// Other is the verb of commands which are not known.
+	Other = "other"
+)
+
+var (
+	// known are the verbs of the BattlEye RCON commands, in lower case.
+	known = map[string]bool{
+		"players": true, "admins": true, "bans": true, "missions": true, "version": true,
+		"update": true, "say": true,
+		"kick": true, "ban": true, "addban": true, "removeban": true, "loadbans": true,
+		"writebans": true, "loadscripts": true, "loadevents": true, "maxping": true,
+		"logout": true, "exit": true, "#lock": true, "#unlock": true, "#mission": true,
+		"#missions": true, "#restart": true, "#reassign": true, "#shutdown": true,
+		"#restartserver": true, "#init": true, "#exec": true, "#beserver": true, "#debug": true,
+	}
+)
+
+// Bounded returns verb in lower case if it's the verb of a known command, or Other.
+func Bounded(verb string) string {
+	verb = strings.ToLower(verb)
+	if known[verb] {
+		return verb
+	}
+	return Other
+}
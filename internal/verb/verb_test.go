@@ -0,0 +1,29 @@
+package verb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBounded(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		verb string
+		exp  string
+	}{
+		{verb: "players", exp: "players"},
+		{verb: "loadBans", exp: "loadbans"},
+		{verb: "#restart", exp: "#restart"},
+		{verb: "#restartServer", exp: "#restartserver"},
+		{verb: "version", exp: "version"},
+		{verb: "update", exp: "update"},
+		{verb: "", exp: Other},
+		{verb: "d41d8cd98f00b204e9800998ecf8427e", exp: Other},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.exp, Bounded(tc.verb), tc.verb)
+	}
+}
@@ -0,0 +1,118 @@
+package battleye
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxMessageLength is the maximum length of a message in bytes which the server displays
+	// without truncating it.
+	maxMessageLength = 128
+
+	// everyone is the player ID which addresses every player on the server.
+	everyone = -1
+)
+
+// Broadcast sends msg to every player on the server.
+// Control characters are removed from msg. Multi-line messages and messages longer than what the
+// server can display are sent as multiple messages.
+func (c *Client) Broadcast(msg string) error {
+	return c.BroadcastContext(context.Background(), msg)
+}
+
+// BroadcastContext sends msg to every player on the server the same way as Broadcast, executing
+// the commands the same way as ExecContext.
+func (c *Client) BroadcastContext(ctx context.Context, msg string) error {
+	return c.say(ctx, everyone, msg)
+}
+
+// Whisper sends msg to the player identified by playerID.
+// msg is handled the same way as in Broadcast.
+func (c *Client) Whisper(playerID int, msg string) error {
+	return c.WhisperContext(context.Background(), playerID, msg)
+}
+
+// WhisperContext sends msg to the player identified by playerID the same way as Whisper,
+// executing the commands the same way as ExecContext.
+func (c *Client) WhisperContext(ctx context.Context, playerID int, msg string) error {
+	if playerID < 0 {
+		return ErrInvalidPlayerID
+	}
+	return c.say(ctx, playerID, msg)
+}
+
+// say sends msg to the player identified by id split into as many say commands as needed.
+func (c *Client) say(ctx context.Context, id int, msg string) error {
+	parts := splitMessage(msg, maxMessageLength)
+	if len(parts) == 0 {
+		return ErrEmptyMessage
+	}
+
+	for _, p := range parts {
+		if _, err := c.ExecCommandContext(ctx, NewCommand("say").Int(id).Text(p)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sanitize replaces control characters in s with spaces and collapses consecutive whitespace.
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// splitMessage splits msg into lines and wraps each line so that no part is longer than max bytes.
+// Empty lines are dropped.
+func splitMessage(msg string, max int) []string {
+	var parts []string
+	for _, line := range strings.FieldsFunc(msg, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		parts = append(parts, wrap(sanitize(line), max)...)
+	}
+	return parts
+}
+
+// wrap splits line at word boundaries into parts no longer than max bytes.
+// Words longer than max are split at a rune boundary.
+func wrap(line string, max int) []string {
+	var parts []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, w := range strings.Fields(line) {
+		for len(w) > max {
+			flush()
+			i := max
+			for i > 0 && !utf8.RuneStart(w[i]) {
+				i--
+			}
+			parts = append(parts, w[:i])
+			w = w[i:]
+		}
+
+		if cur.Len() > 0 && cur.Len()+1+len(w) > max {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(w)
+	}
+	flush()
+
+	return parts
+}
@@ -0,0 +1,104 @@
+package battleye
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMessage(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		msg  string
+		max  int
+		exp  []string
+	}{
+		{
+			name: "Empty",
+			msg:  " \n\t",
+			max:  10,
+		},
+		{
+			name: "Short message",
+			msg:  "hello world",
+			max:  20,
+			exp:  []string{"hello world"},
+		},
+		{
+			name: "Control characters",
+			msg:  "hello\x00\tworld\x07",
+			max:  20,
+			exp:  []string{"hello world"},
+		},
+		{
+			name: "Multiple lines",
+			msg:  "hello\r\n\nworld",
+			max:  20,
+			exp:  []string{"hello", "world"},
+		},
+		{
+			name: "Wrapped at word boundary",
+			msg:  "the quick brown fox jumps",
+			max:  10,
+			exp:  []string{"the quick", "brown fox", "jumps"},
+		},
+		{
+			name: "Long word",
+			msg:  "a abcdefghijkl b",
+			max:  5,
+			exp:  []string{"a", "abcde", "fghij", "kl b"},
+		},
+		{
+			name: "Multi-byte runes are not split",
+			msg:  "ééé",
+			max:  5,
+			exp:  []string{"éé", "é"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, splitMessage(tc.msg, tc.max))
+		})
+	}
+}
+
+func TestClientSay(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	assert.Equal(t, ErrEmptyMessage, c.Broadcast("\n"))
+	assert.Equal(t, ErrInvalidPlayerID, c.Whisper(-1, "hello"))
+
+	long := strings.Repeat("word ", maxMessageLength/5+1)
+	if !assert.NoError(t, c.Broadcast("hello\n"+long)) {
+		return
+	}
+	if !assert.NoError(t, c.Whisper(3, "hi")) {
+		return
+	}
+
+	cmds := s.Commands()
+	if !assert.Len(t, cmds, 4) {
+		return
+	}
+	assert.Equal(t, "say -1 hello", cmds[0])
+	assert.True(t, strings.HasPrefix(cmds[1], "say -1 word"))
+	assert.True(t, strings.HasPrefix(cmds[2], "say -1 word"))
+	assert.Equal(t, "say 3 hi", cmds[3])
+}
@@ -0,0 +1,326 @@
+// Package metrics collects Prometheus metrics of BattlEye RCON Clients.
+//
+// A Collector gathers the metrics of a server from the Hooks of its Client and from polling the
+// players command when scraped:
+//
+//	m, err := metrics.NewCollector("prod1")
+//	if err != nil {
+//		// Handle error.
+//	}
+//	prometheus.MustRegister(m)
+//
+//	c, err := battleye.NewClient(addr, pwd, battleye.AddHooks(m.Hooks()))
+//	if err != nil {
+//		// Handle error.
+//	}
+//	m.SetClient(c)
+//
+// Metrics are labelled with the server name, so the Collectors of several servers can be
+// registered together. The Hooks can be added to each new Client when reconnecting, as the
+// Collector outlives them.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/internal/verb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// namespace is the prefix of the metric names.
+	namespace = "battleye"
+
+	// defaultPlayersTimeout is the default maximum duration of polling the players when scraped.
+	defaultPlayersTimeout = 5 * time.Second
+
+	// keepAliveVerb is the verb label of the empty commands sent to keep the connection alive.
+	keepAliveVerb = "keepalive"
+)
+
+var (
+	// ErrNilOption is returned by NewCollector if an Option is nil.
+	ErrNilOption = errors.New("metrics: nil option")
+
+	// ErrInvalidServer is returned by NewCollector if the server name is empty.
+	ErrInvalidServer = errors.New("metrics: invalid server")
+
+	// ErrInvalidTimeout is returned if PlayersTimeout Option is used with a non-positive timeout.
+	ErrInvalidTimeout = errors.New("metrics: invalid timeout")
+)
+
+// Client is the interface of the Client whose players a Collector polls, which
+// *battleye.Client implements.
+type Client interface {
+	PlayersContext(ctx context.Context) ([]battleye.Player, error)
+}
+
+var _ Client = (*battleye.Client)(nil)
+
+// Collector is a prometheus.Collector of the metrics of a server.
+type Collector struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	client Client
+	up     bool
+
+	loginFailures prometheus.Counter
+	commands      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	timeouts      prometheus.Counter
+	retries       prometheus.Counter
+	keepAlive     prometheus.Gauge
+	multiPacket   prometheus.Counter
+	dropped       prometheus.Counter
+	packetErrors  *prometheus.CounterVec
+
+	upDesc      *prometheus.Desc
+	playersDesc *prometheus.Desc
+	pingDesc    *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// Option is a Collector configuration Option type.
+type Option func(c *Collector) error
+
+// PlayersTimeout sets the maximum duration of polling the players when scraped, after which the
+// player metrics are left out.
+func PlayersTimeout(timeout time.Duration) Option {
+	return func(c *Collector) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// NewCollector returns a Collector of the metrics of the server called server.
+func NewCollector(server string, options ...Option) (*Collector, error) {
+	if server == "" {
+		return nil, ErrInvalidServer
+	}
+
+	labels := prometheus.Labels{"server": server}
+	c := &Collector{
+		timeout: defaultPlayersTimeout,
+		loginFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "login_failures_total",
+			Help:        "Number of failed attempts to connect and log in to the server.",
+			ConstLabels: labels,
+		}),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "commands_total",
+			Help:        "Number of executed commands by verb and result.",
+			ConstLabels: labels,
+		}, []string{"verb", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "command_duration_seconds",
+			Help:        "Duration of executing commands by verb.",
+			ConstLabels: labels,
+			Buckets:     []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 45},
+		}, []string{"verb"}),
+		timeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "command_timeouts_total",
+			Help:        "Number of commands which failed because the server didn't respond.",
+			ConstLabels: labels,
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "command_retries_total",
+			Help:        "Number of times commands were resent after a timeout.",
+			ConstLabels: labels,
+		}),
+		keepAlive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "keepalive_rtt_seconds",
+			Help:        "Round-trip time of the last keep-alive command, from its last send to its response.",
+			ConstLabels: labels,
+		}),
+		multiPacket: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "multi_packet_responses_total",
+			Help:        "Number of command responses received in several packets.",
+			ConstLabels: labels,
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "messages_dropped_total",
+			Help:        "Number of server messages dropped because they were not read in time.",
+			ConstLabels: labels,
+		}),
+		packetErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "packet_errors_total",
+			Help:        "Number of invalid packets received by reason, e.g. invalid_checksum.",
+			ConstLabels: labels,
+		}, []string{"reason"}),
+		upDesc: prometheus.NewDesc(namespace+"_up",
+			"Whether the server is connected and responding to commands.", nil, labels),
+		playersDesc: prometheus.NewDesc(namespace+"_players",
+			"Number of players connected to the server.", nil, labels),
+		pingDesc: prometheus.NewDesc(namespace+"_players_average_ping_seconds",
+			"Average ping of the players connected to the server whose ping is known.", nil, labels),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Hooks returns the Hooks updating the metrics of c, to be added to the Clients of the server
+// with battleye.AddHooks.
+func (c *Collector) Hooks() *battleye.Hooks {
+	return &battleye.Hooks{
+		Login:          c.login,
+		CommandDone:    c.commandDone,
+		MessageDropped: func(string) { c.dropped.Inc() },
+		PacketError:    c.packetError,
+		Closed:         func() { c.setUp(false) },
+	}
+}
+
+// SetClient sets the Client whose players are polled when scraped, or stops polling if cl is nil.
+func (c *Collector) SetClient(cl Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = cl
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.collectors(func(m prometheus.Collector) { m.Describe(ch) })
+	ch <- c.upDesc
+	ch <- c.playersDesc
+	ch <- c.pingDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.collectors(func(m prometheus.Collector) { m.Collect(ch) })
+
+	c.mu.Lock()
+	cl, up := c.client, c.up
+	c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, boolValue(up))
+
+	if cl == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	players, err := cl.PlayersContext(ctx)
+	if err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.playersDesc, prometheus.GaugeValue, float64(len(players)))
+	var sum, n int
+	for _, p := range players {
+		if p.Ping >= 0 {
+			sum += p.Ping
+			n++
+		}
+	}
+	if n > 0 {
+		avg := time.Duration(sum) * time.Millisecond / time.Duration(n)
+		ch <- prometheus.MustNewConstMetric(c.pingDesc, prometheus.GaugeValue, avg.Seconds())
+	}
+}
+
+// collectors calls f with each of the metrics updated by the Hooks.
+func (c *Collector) collectors(f func(m prometheus.Collector)) {
+	for _, m := range []prometheus.Collector{
+		c.loginFailures, c.commands, c.duration, c.timeouts, c.retries, c.keepAlive,
+		c.multiPacket, c.dropped, c.packetErrors,
+	} {
+		f(m)
+	}
+}
+
+// login updates the metrics with the result of logging in.
+func (c *Collector) login(err error) {
+	if err != nil {
+		c.loginFailures.Inc()
+	}
+	c.setUp(err == nil)
+}
+
+// commandDone updates the metrics with an executed command.
+func (c *Collector) commandDone(info battleye.CommandInfo) {
+	verb := verbLabel(info.Verb())
+	result := "ok"
+	switch {
+	case info.Err == nil:
+		c.setUp(true)
+	case errors.Is(info.Err, battleye.ErrTimeout):
+		result = "timeout"
+		c.timeouts.Inc()
+		c.setUp(false)
+	default:
+		result = "error"
+	}
+
+	c.commands.WithLabelValues(verb, result).Inc()
+	c.duration.WithLabelValues(verb).Observe(info.Duration.Seconds())
+	if info.Attempts > 1 {
+		c.retries.Add(float64(info.Attempts - 1))
+	}
+	if info.Fragments > 1 {
+		c.multiPacket.Inc()
+	}
+	if verb == keepAliveVerb && info.Err == nil {
+		c.keepAlive.Set(info.RoundTrip.Seconds())
+	}
+}
+
+// packetError updates the metrics with an invalid packet.
+func (c *Collector) packetError(err error) {
+	reason := err.Error()
+	if i := strings.Index(reason, ": "); i != -1 {
+		reason = reason[i+2:]
+	}
+	c.packetErrors.WithLabelValues(strings.ReplaceAll(reason, " ", "_")).Inc()
+}
+
+// setUp sets whether the server is connected.
+func (c *Collector) setUp(up bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.up = up
+}
+
+// verbLabel returns the verb label of a command verb, which is bounded to the known verbs.
+func verbLabel(v string) string {
+	if v == "" {
+		return keepAliveVerb
+	}
+	return verb.Bounded(v)
+}
+
+// boolValue returns 1 if b is set and 0 otherwise.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
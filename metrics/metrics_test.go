@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient is a Client returning fixed players.
+type fakeClient struct {
+	players []battleye.Player
+	err     error
+}
+
+func (c *fakeClient) PlayersContext(ctx context.Context) ([]battleye.Player, error) {
+	return c.players, c.err
+}
+
+func TestNewCollector(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		server string
+		opts   []Option
+		expErr error
+	}{
+		{name: "Empty server", expErr: ErrInvalidServer},
+		{name: "Nil option", server: "prod1", opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Invalid timeout", server: "prod1", opts: []Option{PlayersTimeout(0)}, expErr: ErrInvalidTimeout},
+		{name: "Valid", server: "prod1", opts: []Option{PlayersTimeout(time.Second)}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewCollector(tc.server, tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			problems, err := testutil.CollectAndLint(c)
+			assert.NoError(t, err)
+			assert.Empty(t, problems)
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c, err := NewCollector("prod1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := c.Hooks()
+
+	h.Login(battleye.ErrLoginFailed)
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP battleye_login_failures_total Number of failed attempts to connect and log in to the server.
+# TYPE battleye_login_failures_total counter
+battleye_login_failures_total{server="prod1"} 1
+# HELP battleye_up Whether the server is connected and responding to commands.
+# TYPE battleye_up gauge
+battleye_up{server="prod1"} 0
+`), "battleye_login_failures_total", "battleye_up"))
+
+	h.Login(nil)
+	h.CommandDone(battleye.CommandInfo{Command: "players", Attempts: 1, Fragments: 1, Duration: 20 * time.Millisecond})
+	h.CommandDone(battleye.CommandInfo{Command: "Bans", Attempts: 2, Fragments: 3, Duration: 2 * time.Second})
+	h.CommandDone(battleye.CommandInfo{Command: "", Attempts: 1, Fragments: 1, Duration: 40 * time.Millisecond, RoundTrip: 15 * time.Millisecond})
+	h.CommandDone(battleye.CommandInfo{Command: "custom 1", Attempts: 1, Err: errors.New("write failed")})
+	h.MessageDropped("Player #1 Kerry disconnected")
+	h.PacketError(battleye.ErrInvalidChecksum)
+	h.PacketError(battleye.ErrInvalidChecksum)
+	c.SetClient(&fakeClient{players: []battleye.Player{{Ping: 30}, {Ping: 50}, {Ping: -1}}})
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP battleye_commands_total Number of executed commands by verb and result.
+# TYPE battleye_commands_total counter
+battleye_commands_total{result="error",server="prod1",verb="other"} 1
+battleye_commands_total{result="ok",server="prod1",verb="bans"} 1
+battleye_commands_total{result="ok",server="prod1",verb="keepalive"} 1
+battleye_commands_total{result="ok",server="prod1",verb="players"} 1
+# HELP battleye_command_retries_total Number of times commands were resent after a timeout.
+# TYPE battleye_command_retries_total counter
+battleye_command_retries_total{server="prod1"} 1
+# HELP battleye_keepalive_rtt_seconds Round-trip time of the last keep-alive command, from its last send to its response.
+# TYPE battleye_keepalive_rtt_seconds gauge
+battleye_keepalive_rtt_seconds{server="prod1"} 0.015
+# HELP battleye_messages_dropped_total Number of server messages dropped because they were not read in time.
+# TYPE battleye_messages_dropped_total counter
+battleye_messages_dropped_total{server="prod1"} 1
+# HELP battleye_multi_packet_responses_total Number of command responses received in several packets.
+# TYPE battleye_multi_packet_responses_total counter
+battleye_multi_packet_responses_total{server="prod1"} 1
+# HELP battleye_packet_errors_total Number of invalid packets received by reason, e.g. invalid_checksum.
+# TYPE battleye_packet_errors_total counter
+battleye_packet_errors_total{reason="invalid_checksum",server="prod1"} 2
+# HELP battleye_players Number of players connected to the server.
+# TYPE battleye_players gauge
+battleye_players{server="prod1"} 3
+# HELP battleye_players_average_ping_seconds Average ping of the players connected to the server whose ping is known.
+# TYPE battleye_players_average_ping_seconds gauge
+battleye_players_average_ping_seconds{server="prod1"} 0.04
+# HELP battleye_up Whether the server is connected and responding to commands.
+# TYPE battleye_up gauge
+battleye_up{server="prod1"} 1
+`), "battleye_commands_total", "battleye_command_retries_total", "battleye_keepalive_rtt_seconds",
+		"battleye_messages_dropped_total", "battleye_multi_packet_responses_total", "battleye_packet_errors_total",
+		"battleye_players", "battleye_players_average_ping_seconds", "battleye_up"))
+	assert.Equal(t, 4, testutil.CollectAndCount(c, "battleye_command_duration_seconds"))
+
+	// The server stops responding.
+	h.CommandDone(battleye.CommandInfo{Command: "players", Attempts: 5, Err: battleye.ErrTimeout})
+	c.SetClient(&fakeClient{err: battleye.ErrTimeout})
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP battleye_command_timeouts_total Number of commands which failed because the server didn't respond.
+# TYPE battleye_command_timeouts_total counter
+battleye_command_timeouts_total{server="prod1"} 1
+# HELP battleye_command_retries_total Number of times commands were resent after a timeout.
+# TYPE battleye_command_retries_total counter
+battleye_command_retries_total{server="prod1"} 5
+# HELP battleye_up Whether the server is connected and responding to commands.
+# TYPE battleye_up gauge
+battleye_up{server="prod1"} 0
+`), "battleye_command_timeouts_total", "battleye_command_retries_total", "battleye_up", "battleye_players"))
+
+	h.Login(nil)
+	h.Closed()
+	c.SetClient(nil)
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP battleye_up Whether the server is connected and responding to commands.
+# TYPE battleye_up gauge
+battleye_up{server="prod1"} 0
+`), "battleye_up", "battleye_players"))
+}
+
+func TestCollectorsRegisterTogether(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewPedanticRegistry()
+	for _, name := range []string{"prod1", "prod2"} {
+		c, err := NewCollector(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, reg.Register(c))
+	}
+	_, err := reg.Gather()
+	assert.NoError(t, err)
+}
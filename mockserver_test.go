@@ -39,8 +39,12 @@ type server struct {
 	srvMsgAckCounter int64
 	clients          sync.Map
 	seq              byte
+	responses        sync.Map
+	cmdsLock         sync.Mutex
+	cmds             []string
 	multiRespCh      chan string
 	duplicateCh      chan struct{}
+	srvMsgCh         chan string
 }
 
 // newServer returns a server or nil if an error occurred.
@@ -59,6 +63,7 @@ func newServer(t *testing.T, pwd string) *server {
 
 		multiRespCh: make(chan string, 1),
 		duplicateCh: make(chan struct{}, 1),
+		srvMsgCh:    make(chan string, 10),
 	}
 
 	return s
@@ -84,6 +89,28 @@ func (s *server) SetMultiPacketResponse(message string) {
 	s.multiRespCh <- message
 }
 
+// SetResponse sets the response message the server replies with to cmd.
+func (s *server) SetResponse(cmd, message string) {
+	s.SetResponseFunc(cmd, func() string { return message })
+}
+
+// SetResponseFunc sets a function which returns the response message the server replies with to cmd.
+func (s *server) SetResponseFunc(cmd string, f func() string) {
+	s.responses.Store(cmd, f)
+}
+
+// Commands returns the non keep-alive commands the server received in order.
+func (s *server) Commands() []string {
+	s.cmdsLock.Lock()
+	defer s.cmdsLock.Unlock()
+	return append([]string(nil), s.cmds...)
+}
+
+// SendServerMessage sets the message of the next server message broadcast to the clients.
+func (s *server) SendServerMessage(message string) {
+	s.srvMsgCh <- message
+}
+
 func (s *server) SetDuplicatedResponse() {
 	s.duplicateCh <- struct{}{}
 }
@@ -150,7 +177,12 @@ func (s *server) messenger() {
 		case <-s.done:
 			return
 		case <-t.C:
-			p := createServerMessage(s.seq)
+			message := fmt.Sprintf("%v %v", testServerMessage, s.seq)
+			select {
+			case message = <-s.srvMsgCh:
+			default:
+			}
+			p := createServerMessage(message, s.seq)
 			s.clients.Range(func(k, v interface{}) bool {
 				if addr, ok := v.(net.Addr); ok {
 					_, err := s.pc.WriteTo(p, addr)
@@ -184,10 +216,10 @@ func (s *server) handleMessage(b []byte, addr net.Addr) error {
 
 // handleLoginMessage checks the password in the message and sends a login success/failed message accordingly.
 func (s *server) handleLoginMessage(b []byte, addr net.Addr) error {
-	p := &packet{payloadType: loginType, message: string(0x00)}
+	p := &packet{payloadType: loginType, message: "\x00"}
 	pwd := string(b[8:])
 	if pwd == s.pwd {
-		p.message = string(0x01)
+		p.message = "\x01"
 	}
 	return s.sendPacket(p, addr)
 }
@@ -202,6 +234,10 @@ func (s *server) handleCommandMessage(b []byte, addr net.Addr) error {
 		return s.sendPacket(p, addr)
 	}
 
+	s.cmdsLock.Lock()
+	s.cmds = append(s.cmds, string(b[9:]))
+	s.cmdsLock.Unlock()
+
 	select {
 	case msg := <-s.multiRespCh:
 		parts := strings.Split(msg, "*")
@@ -219,6 +255,9 @@ func (s *server) handleCommandMessage(b []byte, addr net.Addr) error {
 		return nil
 	default:
 		p := &packet{payloadType: commandType, sequenceNumber: seq, message: "Response to: " + string(b[9:])}
+		if v, ok := s.responses.Load(string(b[9:])); ok {
+			p.message = v.(func() string)()
+		}
 		if err := s.sendPacket(p, addr); err != nil {
 			return err
 		}
@@ -257,8 +296,7 @@ func createMultiResponse(message string, seq, max, current byte) []byte {
 }
 
 // createServerMessage creates a server message packet.
-func createServerMessage(seq byte) []byte {
-	message := fmt.Sprintf("%v %v", testServerMessage, seq)
+func createServerMessage(message string, seq byte) []byte {
 	payload := append([]byte{0xff, byte(serverMessageType), seq}, []byte(message)...)
 	header := []byte{0x42, 0x45, 0, 0, 0, 0}
 	binary.LittleEndian.PutUint32(header[2:6], crc32.ChecksumIEEE(payload))
@@ -0,0 +1,648 @@
+// Package notify posts selected server messages of a BattlEye RCON Client to HTTP endpoints, e.g.
+// to alert moderators of kicks, bans and admin logins in a chat service.
+//
+// A Notifier subscribes to the messages of a Client and queues a payload for each endpoint whose
+// filter accepts the message. Each endpoint is delivered to in order by its own goroutine, which
+// retries failed deliveries with exponential backoff and is rate limited:
+//
+//	tmpl, err := notify.NewTemplate(`{"content": {{json .Message}}}`)
+//	if err != nil {
+//		// Handle error.
+//	}
+//	n, err := notify.NewNotifier(c,
+//		notify.Server("prod1"),
+//		notify.AddEndpoint(&notify.Endpoint{
+//			Name:     "discord",
+//			URL:      "https://discord.com/api/webhooks/...",
+//			Types:    []string{"player_kicked", "admin_logged_in"},
+//			Template: tmpl,
+//			Rate:     0.5,
+//		}),
+//		notify.QueueDir("/var/lib/battleye/notify"),
+//	)
+//	if err != nil {
+//		// Handle error.
+//	}
+//	defer n.Close()
+//
+// Deliveries are queued in memory unless QueueDir is set, in which case they are also written to
+// disk before being attempted, so that deliveries pending during an outage of an endpoint are
+// not lost when the process restarts. Deliveries are at least once: receivers can deduplicate
+// them by their X-Battleye-Delivery header.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultQueueSize is the default maximum number of deliveries queued for each endpoint.
+	defaultQueueSize = 10000
+
+	// intakeSize is the number of messages waiting to be queued, so that queueing, which may
+	// write to disk, doesn't hold up the Client.
+	intakeSize = 1000
+
+	// defaultMinBackoff is the default delay before retrying a failed delivery.
+	defaultMinBackoff = time.Second
+
+	// defaultMaxBackoff is the default maximum delay before retrying a failed delivery.
+	defaultMaxBackoff = 5 * time.Minute
+
+	// defaultRequestTimeout is the default timeout of the requests to the endpoints.
+	defaultRequestTimeout = 30 * time.Second
+
+	// defaultContentType is the default content type of the payloads.
+	defaultContentType = "application/json"
+
+	// userAgent is the User-Agent header of the requests.
+	userAgent = "go-battleye-notify"
+)
+
+// BannedType is the type selecting the kicks caused by bans in the Types of an Endpoint, which
+// are player_kicked messages.
+const BannedType = "player_banned"
+
+// Request headers in addition to Content-Type and User-Agent.
+const (
+	// DeliveryHeader is the header identifying a delivery, which is the same when it's retried.
+	DeliveryHeader = "X-Battleye-Delivery"
+
+	// EventHeader is the header of the type of the server message, as returned by event.TypeOf.
+	EventHeader = "X-Battleye-Event"
+
+	// SignatureHeader is the header of the HMAC-SHA256 signature of the body, as sha256=<hex>, if
+	// the endpoint has a Secret.
+	SignatureHeader = "X-Battleye-Signature"
+)
+
+var (
+	// ErrNilOption is returned by NewNotifier if an Option is nil.
+	ErrNilOption = errors.New("notify: nil option")
+
+	// ErrNoEndpoints is returned by NewNotifier if no AddEndpoint Option is given.
+	ErrNoEndpoints = errors.New("notify: no endpoints")
+
+	// ErrInvalidEndpoint is returned by AddEndpoint if the endpoint is nil, its Name is empty,
+	// not unique or not usable as a directory name, or its Rate or Burst is negative.
+	ErrInvalidEndpoint = errors.New("notify: invalid endpoint")
+
+	// ErrInvalidURL is returned by AddEndpoint if the URL of the endpoint is not http or https.
+	ErrInvalidURL = errors.New("notify: invalid url")
+
+	// ErrInvalidQueueSize is returned if QueueSize Option is used with a size less than 1.
+	ErrInvalidQueueSize = errors.New("notify: invalid queue size")
+
+	// ErrInvalidBackoff is returned if Backoff Option is used with a non-positive minimum or a
+	// maximum less than the minimum.
+	ErrInvalidBackoff = errors.New("notify: invalid backoff")
+
+	// ErrInvalidTimeout is returned if RequestTimeout Option is used with a non-positive timeout.
+	ErrInvalidTimeout = errors.New("notify: invalid timeout")
+
+	// ErrQueueFull is reported if a payload is dropped because the queue of an endpoint is full,
+	// or a message is dropped because too many are waiting to be queued.
+	ErrQueueFull = errors.New("notify: queue full")
+)
+
+// Source is the interface of the Client a Notifier is fed from, which *battleye.Client implements.
+type Source = battleye.Source
+
+// Payload is the data the Template of an Endpoint is executed with, which is sent as JSON if the
+// Endpoint has no Template.
+type Payload struct {
+	// Server is the name of the server, see Server.
+	Server string `json:"server,omitempty"`
+
+	// Time is the time the message was received.
+	Time time.Time `json:"time"`
+
+	// Type is the type of the message as returned by event.TypeOf, e.g. player_kicked.
+	Type string `json:"type"`
+
+	// Message is the server message.
+	Message string `json:"message"`
+
+	// Player is the name of the player the message is about, if any.
+	Player string `json:"player,omitempty"`
+
+	// Event is the message parsed into an event, e.g. *event.PlayerKicked.
+	Event event.Event `json:"-"`
+}
+
+// NewTemplate returns a payload template parsed from text, which can use the json function to
+// encode values as JSON, e.g. {{json .Message}}.
+func NewTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+}
+
+// toJSON returns v encoded as JSON.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Endpoint is an HTTP endpoint server messages are posted to.
+type Endpoint struct {
+	// Name identifies the endpoint in errors and is the name of its queue directory.
+	Name string
+
+	// URL is the http or https URL the payloads are posted to.
+	URL string
+
+	// Types are the types of the messages to send, as returned by event.TypeOf, e.g. chat,
+	// player_kicked or admin_logged_in, or BannedType. If empty, messages of every type are sent.
+	Types []string
+
+	// Match selects the messages to send with a regular expression, if set.
+	Match *regexp.Regexp
+
+	// Template renders the payload from a Payload, see NewTemplate. If nil, the Payload is sent
+	// as JSON.
+	Template *template.Template
+
+	// ContentType is the Content-Type of the payloads, by default application/json.
+	ContentType string
+
+	// Header are additional headers of the requests, e.g. Authorization.
+	Header http.Header
+
+	// Secret is the key the body is signed with, if set, see SignatureHeader.
+	Secret string
+
+	// Rate is the maximum number of requests per second, or unlimited if 0.
+	Rate float64
+
+	// Burst is the number of requests which can be sent at once before Rate applies, by
+	// default 1.
+	Burst int
+}
+
+// accept returns true if the endpoint sends p.
+func (e *Endpoint) accept(p *Payload) bool {
+	if len(e.Types) > 0 {
+		found := false
+		for _, t := range e.Types {
+			if t == p.Type || (t == BannedType && isBan(p.Event)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return e.Match == nil || e.Match.MatchString(p.Message)
+}
+
+// isBan returns true if e is a kick caused by a ban.
+func isBan(e event.Event) bool {
+	k, ok := e.(*event.PlayerKicked)
+	return ok && k.KickReason().Type == event.BanKick
+}
+
+// render returns the body of the request sending p.
+func (e *Endpoint) render(p *Payload) ([]byte, error) {
+	if e.Template == nil {
+		return json.Marshal(p)
+	}
+	var buf bytes.Buffer
+	if err := e.Template.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the value of the SignatureHeader of body signed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint: errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliveryError is the error of a failed delivery to an endpoint.
+type DeliveryError struct {
+	// Endpoint is the name of the endpoint.
+	Endpoint string
+
+	// Attempts is the number of times the delivery failed, including this one.
+	Attempts int
+
+	// StatusCode is the status code of the response, or 0 if no response was received.
+	StatusCode int
+
+	// Retry is true if the delivery will be retried. Deliveries which get a 4xx status code other
+	// than 408 Request Timeout and 429 Too Many Requests are dropped.
+	Retry bool
+
+	// Err is the cause of the failure.
+	Err error
+}
+
+// Error implements error.
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("notify: %v: delivery attempt %v failed: %v", e.Endpoint, e.Attempts, e.Err)
+}
+
+// Unwrap returns the cause of the failure.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// endpoint is an Endpoint with its delivery state.
+type endpoint struct {
+	*Endpoint
+	queue   *queue
+	limiter *rate.Limiter
+}
+
+// Notifier posts the server messages of a Client to Endpoints.
+type Notifier struct {
+	src        Source
+	server     string
+	endpoints  []*Endpoint
+	dir        string
+	queueSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	client     *http.Client
+	timeout    time.Duration
+	onError    func(err error)
+
+	queues  []*endpoint
+	intake  chan battleye.Message
+	handler battleye.HandlerID
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Option is a Notifier configuration Option type.
+type Option func(n *Notifier) error
+
+// AddEndpoint adds an endpoint messages are posted to. It can be used several times.
+func AddEndpoint(e *Endpoint) Option {
+	return func(n *Notifier) error {
+		if e == nil || e.Name == "" || e.Name == "." || e.Name == ".." ||
+			strings.ContainsAny(e.Name, `/\`) || e.Rate < 0 || e.Burst < 0 {
+			return ErrInvalidEndpoint
+		}
+		for _, o := range n.endpoints {
+			if o.Name == e.Name {
+				return ErrInvalidEndpoint
+			}
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidURL
+		}
+		n.endpoints = append(n.endpoints, e)
+		return nil
+	}
+}
+
+// Server sets the server name of the Payloads.
+func Server(name string) Option {
+	return func(n *Notifier) error {
+		n.server = name
+		return nil
+	}
+}
+
+// QueueDir sets the directory the deliveries are queued in, in a subdirectory for each endpoint.
+// Deliveries left in it are sent when a Notifier is created.
+func QueueDir(dir string) Option {
+	return func(n *Notifier) error {
+		n.dir = dir
+		return nil
+	}
+}
+
+// QueueSize sets the maximum number of deliveries queued for each endpoint, after which new
+// payloads are dropped and ErrQueueFull is reported.
+func QueueSize(size int) Option {
+	return func(n *Notifier) error {
+		if size < 1 {
+			return ErrInvalidQueueSize
+		}
+		n.queueSize = size
+		return nil
+	}
+}
+
+// Backoff sets the delay before retrying a failed delivery, which doubles with each failure of
+// the endpoint from minDelay up to maxDelay.
+func Backoff(minDelay, maxDelay time.Duration) Option {
+	return func(n *Notifier) error {
+		if minDelay <= 0 || maxDelay < minDelay {
+			return ErrInvalidBackoff
+		}
+		n.minBackoff, n.maxBackoff = minDelay, maxDelay
+		return nil
+	}
+}
+
+// RequestTimeout sets the timeout of the requests to the endpoints.
+func RequestTimeout(timeout time.Duration) Option {
+	return func(n *Notifier) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		n.timeout = timeout
+		return nil
+	}
+}
+
+// HTTPClient sets the client the requests are sent with. Its Timeout is overridden by
+// RequestTimeout if both are used, and defaults to 30 seconds if not set.
+func HTTPClient(c *http.Client) Option {
+	return func(n *Notifier) error {
+		if c == nil {
+			return ErrNilOption
+		}
+		n.client = c
+		return nil
+	}
+}
+
+// ErrorHandler sets the function called with the errors of the Notifier, e.g. a *DeliveryError
+// or ErrQueueFull wrapped with the name of the endpoint. It is called from several goroutines.
+func ErrorHandler(f func(err error)) Option {
+	return func(n *Notifier) error {
+		n.onError = f
+		return nil
+	}
+}
+
+// NewNotifier returns a new Notifier posting the messages of src to the endpoints. The Notifier
+// subscribes to the messages of src, which can be shared with other consumers.
+func NewNotifier(src Source, options ...Option) (*Notifier, error) {
+	n := &Notifier{
+		src:        src,
+		queueSize:  defaultQueueSize,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(n); err != nil {
+			return nil, err
+		}
+	}
+	if len(n.endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	client := &http.Client{}
+	if n.client != nil {
+		*client = *n.client
+	}
+	if n.timeout > 0 {
+		client.Timeout = n.timeout
+	} else if client.Timeout == 0 {
+		client.Timeout = defaultRequestTimeout
+	}
+	n.client = client
+
+	for _, e := range n.endpoints {
+		dir := ""
+		if n.dir != "" {
+			dir = filepath.Join(n.dir, e.Name)
+		}
+		q, err := openQueue(dir, n.queueSize)
+		if err != nil {
+			return nil, err
+		}
+		limit, burst := rate.Inf, e.Burst
+		if e.Rate > 0 {
+			limit = rate.Limit(e.Rate)
+		}
+		if burst == 0 {
+			burst = 1
+		}
+		n.queues = append(n.queues, &endpoint{Endpoint: e, queue: q, limiter: rate.NewLimiter(limit, burst)})
+	}
+
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	n.intake = make(chan battleye.Message, intakeSize)
+	n.handler = src.OnMessage(n.receive)
+	n.wg.Add(1)
+	go n.run()
+	for _, e := range n.queues {
+		n.wg.Add(1)
+		go n.deliver(e)
+	}
+
+	return n, nil
+}
+
+// Close stops n. Deliveries which have not been sent are lost unless QueueDir is set.
+func (n *Notifier) Close() {
+	n.src.Unregister(n.handler)
+	n.cancel()
+	n.wg.Wait()
+}
+
+// Pending returns the number of deliveries queued for the endpoint called name.
+func (n *Notifier) Pending(name string) int {
+	for _, e := range n.queues {
+		if e.Name == name {
+			return e.queue.len()
+		}
+	}
+	return 0
+}
+
+// error reports err to the ErrorHandler.
+func (n *Notifier) error(err error) {
+	if n.onError != nil {
+		n.onError(err)
+	}
+}
+
+// receive passes m to the run goroutine. If too many messages are waiting, m is dropped rather
+// than holding up src.
+func (n *Notifier) receive(m battleye.Message) {
+	select {
+	case n.intake <- m:
+	default:
+		n.error(fmt.Errorf("%w: %v", ErrQueueFull, m.Event.Raw()))
+	}
+}
+
+// run is a goroutine which queues the received server messages until n or src is closed.
+func (n *Notifier) run() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case m := <-n.intake:
+			n.notify(m)
+		case <-n.src.Done():
+			// Queue the messages received before src was closed.
+			for {
+				select {
+				case m := <-n.intake:
+					n.notify(m)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// notify queues m for the endpoints which accept it.
+func (n *Notifier) notify(m battleye.Message) {
+	e := m.Event
+	p := &Payload{Server: n.server, Time: m.Time, Type: event.TypeOf(e), Message: e.Raw(), Player: event.PlayerName(e), Event: e}
+	for _, ep := range n.queues {
+		if !ep.accept(p) {
+			continue
+		}
+		body, err := ep.render(p)
+		if err != nil {
+			n.error(fmt.Errorf("notify: %v: %v", ep.Name, err))
+			continue
+		}
+		if err := ep.queue.push(p.Type, body); err != nil {
+			n.error(fmt.Errorf("%w: %v", err, ep.Name))
+		}
+	}
+}
+
+// deliver is a goroutine which sends the deliveries queued for e in order until n is closed.
+func (n *Notifier) deliver(e *endpoint) {
+	defer n.wg.Done()
+
+	for {
+		d, ok := e.queue.peek()
+		if !ok {
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-e.queue.ready:
+			}
+			continue
+		}
+
+		if err := e.limiter.Wait(n.ctx); err != nil {
+			return
+		}
+		status, err := n.post(e, d)
+		if n.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if err := e.queue.remove(d); err != nil {
+				n.error(fmt.Errorf("notify: %v: %v", e.Name, err))
+			}
+			continue
+		}
+
+		if err := e.queue.failed(d); err != nil {
+			n.error(fmt.Errorf("notify: %v: %v", e.Name, err))
+		}
+		derr := &DeliveryError{Endpoint: e.Name, Attempts: d.Attempts, StatusCode: status, Retry: retryable(status), Err: err}
+		n.error(derr)
+		if !derr.Retry {
+			if err := e.queue.remove(d); err != nil {
+				n.error(fmt.Errorf("notify: %v: %v", e.Name, err))
+			}
+			continue
+		}
+
+		t := time.NewTimer(n.backoff(d.Attempts))
+		select {
+		case <-n.ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the delay before retrying a delivery which failed attempts times, which
+// doubles with each attempt, also across restarts.
+func (n *Notifier) backoff(attempts int) time.Duration {
+	delay := n.minBackoff
+	for i := 1; i < attempts && delay < n.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > n.maxBackoff {
+		delay = n.maxBackoff
+	}
+	return delay
+}
+
+// retryable returns true if a delivery which got a response with status, or no response if 0,
+// should be retried.
+func retryable(status int) bool {
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	case status >= 400 && status < 500:
+		return false
+	}
+	return true
+}
+
+// post sends d to e and returns the status code of the response.
+func (n *Notifier) post(e *endpoint, d *delivery) (int, error) {
+	req, err := http.NewRequestWithContext(n.ctx, http.MethodPost, e.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range e.Header {
+		req.Header[k] = v
+	}
+	contentType := e.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(EventHeader, d.Type)
+	if e.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(e.Secret, d.Body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()                                // nolint: errcheck
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // nolint: errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
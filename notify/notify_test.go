@@ -0,0 +1,450 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	kickMsg  = "Player #1 Survivor (f3c2b1a0e9d8c7b6a5f4e3d2c1b0a9f8) has been kicked by BattlEye: Admin Kick (AFK)"
+	banMsg   = "Player #2 Bandit (0a1b2c3d4e5f60718293a4b5c6d7e8f9) has been kicked by BattlEye: Admin Ban (Cheating)"
+	adminMsg = "RCon admin #0 (127.0.0.1:2304) logged in"
+	chatMsg  = "(Global) Kerry: is anyone cheating?"
+	joinMsg  = "Player #3 Kerry (127.0.0.1:2304) connected"
+)
+
+// fakeSource is a Source whose messages are sent by the test.
+type fakeSource struct {
+	done chan struct{}
+
+	mu       sync.Mutex
+	handlers map[battleye.HandlerID]func(battleye.Message)
+	nextID   battleye.HandlerID
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{done: make(chan struct{}), handlers: make(map[battleye.HandlerID]func(battleye.Message))}
+}
+
+func (s *fakeSource) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handlers[s.nextID] = f
+	return s.nextID
+}
+
+func (s *fakeSource) Unregister(id battleye.HandlerID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.handlers[id]
+	delete(s.handlers, id)
+	return ok
+}
+
+func (s *fakeSource) Done() <-chan struct{} {
+	return s.done
+}
+
+// send calls the registered handlers with msg.
+func (s *fakeSource) send(msg string) {
+	s.mu.Lock()
+	handlers := make([]func(battleye.Message), 0, len(s.handlers))
+	for _, f := range s.handlers {
+		handlers = append(handlers, f)
+	}
+	s.mu.Unlock()
+
+	m := battleye.Message{Time: time.Now(), Event: event.Parse(msg)}
+	for _, f := range handlers {
+		f(m)
+	}
+}
+
+// request is a request received by a receiver.
+type request struct {
+	path   string
+	header http.Header
+	body   string
+}
+
+// receiver is an HTTP server recording the requests it receives, which responds with the status
+// codes in statuses in turn, and then with 200 OK.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, request{path: req.URL.Path, header: req.Header, body: string(b)})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+// received returns the requests received for path.
+func (r *receiver) received(path string) []request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var reqs []request
+	for _, req := range r.requests {
+		if req.path == path {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// errorRecorder records the errors reported to an ErrorHandler.
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestNewNotifier(t *testing.T) {
+	t.Parallel()
+
+	valid := &Endpoint{Name: "valid", URL: "https://example.com/hook"}
+	testcases := []struct {
+		name   string
+		opts   []Option
+		expErr error
+	}{
+		{name: "Nil option", opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "No endpoints", expErr: ErrNoEndpoints},
+		{name: "Nil endpoint", opts: []Option{AddEndpoint(nil)}, expErr: ErrInvalidEndpoint},
+		{name: "Empty name", opts: []Option{AddEndpoint(&Endpoint{URL: "https://example.com"})}, expErr: ErrInvalidEndpoint},
+		{name: "Path name", opts: []Option{AddEndpoint(&Endpoint{Name: "a/b", URL: "https://example.com"})}, expErr: ErrInvalidEndpoint},
+		{name: "Dot name", opts: []Option{AddEndpoint(&Endpoint{Name: "..", URL: "https://example.com"})}, expErr: ErrInvalidEndpoint},
+		{name: "Duplicate name", opts: []Option{AddEndpoint(valid), AddEndpoint(valid)}, expErr: ErrInvalidEndpoint},
+		{name: "Negative rate", opts: []Option{AddEndpoint(&Endpoint{Name: "a", URL: "https://example.com", Rate: -1})}, expErr: ErrInvalidEndpoint},
+		{name: "Invalid URL", opts: []Option{AddEndpoint(&Endpoint{Name: "a", URL: "ftp://example.com"})}, expErr: ErrInvalidURL},
+		{name: "No host", opts: []Option{AddEndpoint(&Endpoint{Name: "a", URL: "http:///hook"})}, expErr: ErrInvalidURL},
+		{name: "Invalid queue size", opts: []Option{AddEndpoint(valid), QueueSize(0)}, expErr: ErrInvalidQueueSize},
+		{name: "Invalid backoff", opts: []Option{AddEndpoint(valid), Backoff(time.Second, time.Millisecond)}, expErr: ErrInvalidBackoff},
+		{name: "Invalid timeout", opts: []Option{AddEndpoint(valid), RequestTimeout(0)}, expErr: ErrInvalidTimeout},
+		{name: "Nil HTTP client", opts: []Option{AddEndpoint(valid), HTTPClient(nil)}, expErr: ErrNilOption},
+		{name: "Valid", opts: []Option{
+			AddEndpoint(valid),
+			Server("prod1"),
+			QueueSize(10),
+			Backoff(time.Millisecond, time.Second),
+			RequestTimeout(time.Second),
+			HTTPClient(&http.Client{}),
+			QueueDir(t.TempDir()),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := NewNotifier(newFakeSource(), tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, time.Second, n.client.Timeout)
+				n.Close()
+			}
+		})
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	r := newReceiver()
+	defer r.Close()
+
+	tmpl, err := NewTemplate(`{"text": {{json (printf "%v: %v" .Server .Message)}}}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var errs errorRecorder
+	src := newFakeSource()
+	n, err := NewNotifier(src,
+		Server("prod1"),
+		AddEndpoint(&Endpoint{
+			Name:     "moderation",
+			URL:      r.URL + "/moderation",
+			Types:    []string{"player_kicked", "admin_logged_in"},
+			Template: tmpl,
+			Header:   http.Header{"Authorization": {"Bearer token"}},
+			Secret:   "secret",
+		}),
+		AddEndpoint(&Endpoint{
+			Name:  "cheating",
+			URL:   r.URL + "/cheating",
+			Types: []string{"chat"},
+			Match: regexp.MustCompile(`(?i)cheat`),
+		}),
+		AddEndpoint(&Endpoint{Name: "bans", URL: r.URL + "/bans", Types: []string{BannedType}}),
+		ErrorHandler(errs.handle),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer n.Close()
+
+	for _, msg := range []string{joinMsg, kickMsg, "(Global) Kerry: hello", chatMsg, adminMsg, banMsg} {
+		src.send(msg)
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(r.received("/moderation")) == 3 && len(r.received("/cheating")) == 1 && len(r.received("/bans")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	moderation := r.received("/moderation")
+	if assert.Len(t, moderation, 3) {
+		req := moderation[0]
+		assert.JSONEq(t, `{"text": "prod1: `+kickMsg+`"}`, req.body)
+		assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.header.Get("Authorization"))
+		assert.Equal(t, "player_kicked", req.header.Get(EventHeader))
+		assert.Equal(t, Sign("secret", []byte(req.body)), req.header.Get(SignatureHeader))
+		assert.NotEmpty(t, req.header.Get(DeliveryHeader))
+		assert.Equal(t, "admin_logged_in", moderation[1].header.Get(EventHeader))
+		assert.Equal(t, "player_kicked", moderation[2].header.Get(EventHeader))
+	}
+
+	cheating := r.received("/cheating")
+	if assert.Len(t, cheating, 1) {
+		var p Payload
+		assert.NoError(t, json.Unmarshal([]byte(cheating[0].body), &p))
+		assert.Equal(t, "prod1", p.Server)
+		assert.Equal(t, "chat", p.Type)
+		assert.Equal(t, chatMsg, p.Message)
+		assert.Equal(t, "Kerry", p.Player)
+		assert.False(t, p.Time.IsZero())
+		assert.Empty(t, cheating[0].header.Get(SignatureHeader))
+	}
+
+	bans := r.received("/bans")
+	if assert.Len(t, bans, 1) {
+		assert.Contains(t, bans[0].body, "Bandit")
+	}
+	assert.Empty(t, errs.errors())
+}
+
+func TestNotifierRetry(t *testing.T) {
+	r := newReceiver(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK, http.StatusBadRequest)
+	defer r.Close()
+
+	var errs errorRecorder
+	src := newFakeSource()
+	n, err := NewNotifier(src,
+		AddEndpoint(&Endpoint{Name: "hook", URL: r.URL}),
+		Backoff(10*time.Millisecond, 20*time.Millisecond),
+		ErrorHandler(errs.handle),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer n.Close()
+
+	src.send(kickMsg)
+	src.send(joinMsg)
+	src.send(adminMsg)
+	assert.Eventually(t, func() bool { return len(r.received("/")) == 5 }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return n.Pending("hook") == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	reqs := r.received("/")
+	if assert.Len(t, reqs, 5) {
+		// The kick is retried with the same delivery ID until it succeeds, and the join which gets
+		// 400 Bad Request is dropped.
+		id := reqs[0].header.Get(DeliveryHeader)
+		for i, exp := range []string{"player_kicked", "player_kicked", "player_kicked", "player_connected", "admin_logged_in"} {
+			assert.Equal(t, exp, reqs[i].header.Get(EventHeader))
+		}
+		assert.Equal(t, id, reqs[1].header.Get(DeliveryHeader))
+		assert.Equal(t, id, reqs[2].header.Get(DeliveryHeader))
+		assert.NotEqual(t, id, reqs[3].header.Get(DeliveryHeader))
+	}
+
+	reported := errs.errors()
+	if assert.Len(t, reported, 3) {
+		var exp []*DeliveryError
+		for _, err := range reported {
+			var derr *DeliveryError
+			if assert.True(t, errors.As(err, &derr), err) {
+				exp = append(exp, derr)
+			}
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, exp[0].StatusCode)
+		assert.Equal(t, 1, exp[0].Attempts)
+		assert.True(t, exp[0].Retry)
+		assert.Equal(t, http.StatusTooManyRequests, exp[1].StatusCode)
+		assert.Equal(t, 2, exp[1].Attempts)
+		assert.Equal(t, http.StatusBadRequest, exp[2].StatusCode)
+		assert.False(t, exp[2].Retry)
+		assert.EqualError(t, exp[0], "notify: hook: delivery attempt 1 failed: unexpected status 503 Service Unavailable")
+	}
+}
+
+func TestNotifierQueueDir(t *testing.T) {
+	dir := t.TempDir()
+	down := newReceiver(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	defer down.Close()
+
+	src := newFakeSource()
+	n, err := NewNotifier(src,
+		AddEndpoint(&Endpoint{Name: "hook", URL: down.URL}),
+		QueueDir(dir),
+		Backoff(time.Hour, time.Hour),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	src.send(kickMsg)
+	src.send(adminMsg)
+	assert.Eventually(t, func() bool { return len(down.received("/")) == 1 && n.Pending("hook") == 2 }, 5*time.Second, 10*time.Millisecond)
+	n.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "hook", "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// The pending deliveries are sent by the next Notifier when the endpoint is back.
+	up := newReceiver()
+	defer up.Close()
+	n, err = NewNotifier(newFakeSource(), AddEndpoint(&Endpoint{Name: "hook", URL: up.URL}), QueueDir(dir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer n.Close()
+
+	assert.Eventually(t, func() bool { return len(up.received("/")) == 2 && n.Pending("hook") == 0 }, 5*time.Second, 10*time.Millisecond)
+	reqs := up.received("/")
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, down.received("/")[0].header.Get(DeliveryHeader), reqs[0].header.Get(DeliveryHeader))
+		assert.Equal(t, "player_kicked", reqs[0].header.Get(EventHeader))
+		assert.Equal(t, "admin_logged_in", reqs[1].header.Get(EventHeader))
+	}
+	files, err = filepath.Glob(filepath.Join(dir, "hook", "*.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	r := newReceiver()
+	defer r.Close()
+
+	src := newFakeSource()
+	n, err := NewNotifier(src, AddEndpoint(&Endpoint{Name: "hook", URL: r.URL, Rate: 20}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer n.Close()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		src.send(kickMsg)
+	}
+	assert.Eventually(t, func() bool { return len(r.received("/")) == 3 }, 5*time.Second, 5*time.Millisecond)
+	assert.True(t, time.Since(start) >= 90*time.Millisecond, time.Since(start))
+}
+
+func TestNotifierQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	var errs errorRecorder
+	src := newFakeSource()
+	n, err := NewNotifier(src, AddEndpoint(&Endpoint{Name: "hook", URL: ts.URL}), QueueSize(1), ErrorHandler(errs.handle))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer n.Close()
+
+	src.send(kickMsg)
+	src.send(adminMsg)
+	assert.Eventually(t, func() bool { return len(errs.errors()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	err = errs.errors()[0]
+	assert.True(t, errors.Is(err, ErrQueueFull))
+	assert.EqualError(t, err, "notify: queue full: hook")
+	assert.Equal(t, 1, n.Pending("hook"))
+}
+
+func TestQueueOpen(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	q, err := openQueue(dir, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, q.push("chat", []byte("first")))
+	assert.NoError(t, q.push("chat", []byte("second")))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000001.tmp"), []byte("partial"), 0o600))
+
+	q, err = openQueue(dir, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	d, ok := q.peek()
+	if assert.True(t, ok) {
+		assert.Equal(t, "first", string(d.Body))
+		assert.NoError(t, q.remove(d))
+	}
+	assert.Equal(t, 1, q.len())
+	_, err = os.Stat(filepath.Join(dir, "00000000000000000001.tmp"))
+	assert.True(t, os.IsNotExist(err))
+
+	d, ok = q.peek()
+	if assert.True(t, ok) {
+		assert.NoError(t, q.failed(d))
+		assert.NoError(t, q.failed(d))
+	}
+	q, err = openQueue(dir, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	d, ok = q.peek()
+	if assert.True(t, ok) {
+		assert.Equal(t, "second", string(d.Body))
+		assert.Equal(t, 2, d.Attempts)
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000002.json"), []byte("{"), 0o600))
+	_, err = openQueue(dir, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// queueFileExt is the extension of the files of queued deliveries.
+	queueFileExt = ".json"
+
+	// queueTempExt is the extension of the files of deliveries being queued.
+	queueTempExt = ".tmp"
+)
+
+// delivery is a payload queued for an endpoint.
+type delivery struct {
+	// ID identifies the delivery. IDs increase in the order deliveries are queued.
+	ID int64 `json:"id"`
+
+	// Type is the type of the server message.
+	Type string `json:"type"`
+
+	// Body is the rendered payload.
+	Body []byte `json:"body"`
+
+	// Attempts is the number of failed attempts of sending the delivery, which is written to the
+	// file of the delivery after each attempt.
+	Attempts int `json:"attempts"`
+}
+
+// queue is a FIFO of the deliveries of an endpoint, which are kept in a directory if dir is set so
+// that they survive restarts. Deliveries are pushed by a single goroutine.
+type queue struct {
+	dir  string
+	size int
+
+	mu     sync.Mutex
+	items  []*delivery
+	lastID int64
+
+	// ready is signalled when a delivery is pushed.
+	ready chan struct{}
+}
+
+// openQueue returns a queue of at most size deliveries, loading the deliveries in dir if dir is
+// not empty.
+func openQueue(dir string, size int) (*queue, error) {
+	q := &queue{dir: dir, size: size, ready: make(chan struct{}, 1)}
+	if dir == "" {
+		return q, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case strings.HasSuffix(name, queueTempExt):
+			// Left over by a crash while queueing, so it was never acknowledged.
+			os.Remove(filepath.Join(dir, name)) // nolint: errcheck
+		case strings.HasSuffix(name, queueFileExt):
+			d, err := readDelivery(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			q.items = append(q.items, d)
+		}
+	}
+	sort.Slice(q.items, func(i, j int) bool { return q.items[i].ID < q.items[j].ID })
+	if n := len(q.items); n > 0 {
+		q.lastID = q.items[n-1].ID
+		q.ready <- struct{}{}
+	}
+
+	return q, nil
+}
+
+// readDelivery reads the delivery in the file at path.
+func readDelivery(path string) (*delivery, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d := &delivery{}
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("notify: invalid queue file %v: %v", path, err)
+	}
+	return d, nil
+}
+
+// path returns the path of the file of d.
+func (q *queue) path(d *delivery) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%v", d.ID, queueFileExt))
+}
+
+// push queues a delivery of body, which is written to disk before push returns. The file is
+// written without holding mu, so that it doesn't hold up the delivering goroutine.
+func (q *queue) push(typ string, body []byte) error {
+	q.mu.Lock()
+	full := len(q.items) >= q.size
+	// IDs are based on the time so that they are unique across restarts.
+	id := time.Now().UnixNano()
+	if id <= q.lastID {
+		id = q.lastID + 1
+	}
+	q.mu.Unlock()
+	if full {
+		return ErrQueueFull
+	}
+
+	d := &delivery{ID: id, Type: typ, Body: body}
+	if q.dir != "" {
+		if err := q.write(d); err != nil {
+			return err
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.lastID = id
+	q.items = append(q.items, d)
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// write writes d to its file, syncing it before it's renamed into place so that it's either
+// complete or absent after a crash, and syncing the directory so that the rename is durable.
+func (q *queue) write(d *delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	path := q.path(d)
+	tmp := strings.TrimSuffix(path, queueFileExt) + queueTempExt
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp) // nolint: errcheck
+		return err
+	}
+	return syncDir(q.dir)
+}
+
+// syncDir syncs the directory at path, which makes the changes of its entries durable.
+func syncDir(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// peek returns the oldest delivery, which stays queued until removed.
+func (q *queue) peek() (*delivery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	return q.items[0], true
+}
+
+// remove removes d, the oldest delivery, from the queue.
+func (q *queue) remove(d *delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 || q.items[0] != d {
+		return nil
+	}
+	q.items[0] = nil
+	q.items = q.items[1:]
+	if q.dir == "" {
+		return nil
+	}
+	if err := os.Remove(q.path(d)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// failed records a failed attempt of sending d, rewriting its file so that the attempts survive
+// restarts.
+func (q *queue) failed(d *delivery) error {
+	q.mu.Lock()
+	d.Attempts++
+	q.mu.Unlock()
+
+	if q.dir == "" {
+		return nil
+	}
+	return q.write(d)
+}
+
+// len returns the number of queued deliveries.
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
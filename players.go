@@ -0,0 +1,193 @@
+package battleye
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// lobbySuffix is appended to the name of players who are in the lobby.
+	lobbySuffix = " (Lobby)"
+)
+
+var (
+	// kickConfirmAttempts is the number of times Kick checks whether the player has left.
+	kickConfirmAttempts = 3
+
+	// kickConfirmInterval is the interval between the checks of Kick.
+	kickConfirmInterval = 500 * time.Millisecond
+
+	// playerRegexp matches a line of the players command response.
+	playerRegexp = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(-?\d+|-)\s+(\S+)\s+(.*)$`)
+)
+
+// Player represents a player connected to the game server.
+type Player struct {
+	// ID is the player number assigned by the server.
+	ID int `json:"id"`
+
+	// IP is the address the player is connected from.
+	IP net.IP `json:"ip"`
+
+	// Port is the port the player is connected from.
+	Port int `json:"port"`
+
+	// Ping is the latency of the player in milliseconds or -1 if it is not known yet.
+	Ping int `json:"ping"`
+
+	// GUID is the BattlEye GUID of the player or empty if it is not known yet.
+	GUID string `json:"guid,omitempty"`
+
+	// Verified is true if the server verified the GUID of the player.
+	Verified bool `json:"verified"`
+
+	// Name is the in-game name of the player.
+	Name string `json:"name"`
+
+	// Lobby is true if the player is in the lobby.
+	Lobby bool `json:"lobby"`
+}
+
+// Players returns the players currently connected to the game server.
+func (c *Client) Players() ([]Player, error) {
+	return c.PlayersContext(context.Background())
+}
+
+// PlayersContext returns the players currently connected to the game server, executing the
+// command the same way as ExecContext.
+func (c *Client) PlayersContext(ctx context.Context) ([]Player, error) {
+	resp, err := c.ExecContext(ctx, "players")
+	if err != nil {
+		return nil, err
+	}
+	return parsePlayers(resp)
+}
+
+// parsePlayers parses the response of the players command.
+//
+// The response is in the form:
+//
+//	Players on server:
+//	[#] [IP Address]:[Port] [Ping] [GUID] [Name]
+//	--------------------------------------------------
+//	0   127.0.0.1:2304        31   d41d8cd98f00b204e9800998ecf8427e(OK) Player
+//	(1 players in total)
+func parsePlayers(resp string) ([]Player, error) {
+	var players []Player
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || isTableHeader(line) || isTableFooter(line) {
+			continue
+		}
+
+		m := playerRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil, ErrUnexpectedResponse
+		}
+
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, ErrUnexpectedResponse
+		}
+
+		ip, port, err := splitAddr(m[2])
+		if err != nil {
+			return nil, err
+		}
+
+		p := Player{ID: id, IP: ip, Port: port, Ping: -1, Name: m[5]}
+		if m[3] != "-" {
+			if p.Ping, err = strconv.Atoi(m[3]); err != nil {
+				return nil, ErrUnexpectedResponse
+			}
+		}
+
+		switch guid := m[4]; {
+		case strings.HasSuffix(guid, "(OK)"):
+			p.GUID, p.Verified = strings.TrimSuffix(guid, "(OK)"), true
+		case strings.HasSuffix(guid, "(?)"):
+			p.GUID = strings.TrimSuffix(guid, "(?)")
+		case guid != "-":
+			p.GUID = guid
+		}
+
+		if strings.HasSuffix(p.Name, lobbySuffix) {
+			p.Name, p.Lobby = strings.TrimSuffix(p.Name, lobbySuffix), true
+		}
+
+		players = append(players, p)
+	}
+
+	return players, nil
+}
+
+// isTableFooter returns true if line is the total count the server appends to list responses.
+func isTableFooter(line string) bool {
+	return strings.HasPrefix(line, "(") && strings.HasSuffix(line, "in total)")
+}
+
+// Kick kicks the player identified by playerID from the server, showing reason to the player.
+// Control characters are removed from reason. Kick confirms the player has left the server and
+// returns ErrKickFailed otherwise.
+func (c *Client) Kick(playerID int, reason string) error {
+	return c.KickContext(context.Background(), playerID, reason)
+}
+
+// KickContext kicks the player identified by playerID the same way as Kick, executing the
+// commands the same way as ExecContext.
+func (c *Client) KickContext(ctx context.Context, playerID int, reason string) error {
+	if playerID < 0 {
+		return ErrInvalidPlayerID
+	}
+
+	reason = sanitize(reason)
+	if len(reason) > maxMessageLength {
+		return ErrMessageTooLong
+	}
+
+	found, err := c.hasPlayer(ctx, playerID)
+	if err != nil {
+		return err
+	} else if !found {
+		return ErrPlayerNotFound
+	}
+
+	if _, err := c.ExecCommandContext(ctx, NewCommand("kick").Int(playerID).Text(reason)); err != nil {
+		return err
+	}
+
+	for i := 0; i < kickConfirmAttempts; i++ {
+		t := time.NewTimer(kickConfirmInterval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		if found, err = c.hasPlayer(ctx, playerID); err != nil {
+			return err
+		} else if !found {
+			return nil
+		}
+	}
+
+	return ErrKickFailed
+}
+
+// hasPlayer returns true if the player identified by id is connected to the server.
+func (c *Client) hasPlayer(ctx context.Context, id int) (bool, error) {
+	players, err := c.PlayersContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range players {
+		if p.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,104 @@
+package battleye
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPlayersResponse = `Players on server:
+[#] [IP Address]:[Port] [Ping] [GUID] [Name]
+--------------------------------------------------
+0   192.168.1.2:2304      31   d41d8cd98f00b204e9800998ecf8427e(OK) John Doe
+1   10.0.0.3:2316         -1   0cc175b9c0f1b6a831c399e269772661(?)  Jane (Lobby)
+2   10.0.0.4:2304         -    -  Connecting
+(3 players in total)`
+
+func TestParsePlayers(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		resp   string
+		exp    []Player
+		expErr error
+	}{
+		{
+			name: "No players",
+			resp: "Players on server:\n[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n--------------------------------------------------\n(0 players in total)",
+		},
+		{
+			name: "Multiple players",
+			resp: testPlayersResponse,
+			exp: []Player{
+				{ID: 0, IP: net.ParseIP("192.168.1.2"), Port: 2304, Ping: 31, GUID: "d41d8cd98f00b204e9800998ecf8427e", Verified: true, Name: "John Doe"},
+				{ID: 1, IP: net.ParseIP("10.0.0.3"), Port: 2316, Ping: -1, GUID: "0cc175b9c0f1b6a831c399e269772661", Name: "Jane", Lobby: true},
+				{ID: 2, IP: net.ParseIP("10.0.0.4"), Port: 2304, Ping: -1, Name: "Connecting"},
+			},
+		},
+		{
+			name:   "Invalid line",
+			resp:   "Players on server:\n0 garbage",
+			expErr: ErrUnexpectedResponse,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			players, err := parsePlayers(tc.resp)
+			if tc.expErr != nil {
+				assert.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.exp, players)
+		})
+	}
+}
+
+func TestClientKick(t *testing.T) {
+	old := kickConfirmInterval
+	kickConfirmInterval = 10 * time.Millisecond
+	defer func() { kickConfirmInterval = old }()
+
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	var kicked int32
+	s.SetResponseFunc("players", func() string {
+		if atomic.LoadInt32(&kicked) == 1 {
+			return "Players on server:\n(0 players in total)"
+		}
+		return testPlayersResponse
+	})
+	s.SetResponseFunc("kick 0 Team killing", func() string {
+		atomic.StoreInt32(&kicked, 1)
+		return ""
+	})
+	s.SetResponse("kick 1", "")
+
+	assert.Equal(t, ErrInvalidPlayerID, c.Kick(-1, ""))
+	assert.Equal(t, ErrMessageTooLong, c.Kick(0, strings.Repeat("x", maxMessageLength+1)))
+	assert.Equal(t, ErrPlayerNotFound, c.Kick(5, ""))
+	assert.Equal(t, ErrKickFailed, c.Kick(1, ""))
+	assert.NoError(t, c.Kick(0, "Team\nkilling"))
+	assert.Contains(t, s.Commands(), "kick 0 Team killing")
+}
@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+
+	battleye "github.com/multiplay/go-battleye"
+)
+
+// BattlEye payload types.
+const (
+	loginType byte = iota
+	commandType
+	serverMessageType
+)
+
+// loginResponse bytes.
+const (
+	loginFailed byte = iota
+	loginSuccess
+)
+
+const (
+	// headerSize is the size in bytes of the header of a packet, including the 0xff ending it.
+	headerSize = 7
+
+	// fragmentSize is the maximum size in bytes of the response in a command response packet,
+	// which keeps packets within a typical MTU.
+	fragmentSize = 1400
+
+	// maxFragments is the maximum number of packets of a command response.
+	maxFragments = 255
+)
+
+// request is a packet sent by a downstream client.
+type request struct {
+	typ  byte
+	seq  byte
+	body string
+}
+
+// parseRequest parses raw data sent by a downstream client.
+func parseRequest(raw []byte) (*request, error) {
+	if len(raw) < headerSize+1 {
+		return nil, battleye.ErrInvalidPacketSize
+	}
+
+	if !bytes.Equal(raw[0:2], []byte{0x42, 0x45}) {
+		return nil, battleye.ErrInvalidHeader
+	}
+
+	if raw[6] != 0xff {
+		return nil, battleye.ErrInvalidEndOfHeader
+	}
+
+	if crc32.ChecksumIEEE(raw[6:]) != binary.LittleEndian.Uint32(raw[2:6]) {
+		return nil, battleye.ErrInvalidChecksum
+	}
+
+	r := &request{typ: raw[7]}
+	switch r.typ {
+	case loginType:
+		r.body = string(raw[8:])
+	case commandType, serverMessageType:
+		if len(raw) < headerSize+2 {
+			return nil, battleye.ErrInvalidPacketSize
+		}
+		r.seq, r.body = raw[8], string(raw[9:])
+	default:
+		return nil, battleye.ErrUnknownPacketType
+	}
+	return r, nil
+}
+
+// encode returns a packet with payload, which excludes the 0xff ending the header.
+func encode(payload []byte) []byte {
+	payload = append([]byte{0xff}, payload...)
+	b := []byte{0x42, 0x45, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(b[2:6], crc32.ChecksumIEEE(payload))
+	return append(b, payload...)
+}
+
+// loginPacket returns the response to a login packet.
+func loginPacket(ok bool) []byte {
+	if ok {
+		return encode([]byte{loginType, loginSuccess})
+	}
+	return encode([]byte{loginType, loginFailed})
+}
+
+// responsePackets returns the packets of resp, the response to the command sent with seq. Responses
+// larger than a packet are fragmented, and truncated to maxFragments packets.
+func responsePackets(seq byte, resp string) [][]byte {
+	if len(resp) <= fragmentSize {
+		return [][]byte{encode(append([]byte{commandType, seq}, resp...))}
+	}
+
+	n := (len(resp) + fragmentSize - 1) / fragmentSize
+	if n > maxFragments {
+		n = maxFragments
+	}
+	pkts := make([][]byte, n)
+	for i := range pkts {
+		part := resp[i*fragmentSize:]
+		if len(part) > fragmentSize {
+			part = part[:fragmentSize]
+		}
+		pkts[i] = encode(append([]byte{commandType, seq, 0x00, byte(n), byte(i)}, part...))
+	}
+	return pkts
+}
+
+// messagePacket returns the packet of the server message msg with seq.
+func messagePacket(seq byte, msg string) []byte {
+	return encode(append([]byte{serverMessageType, seq}, msg...))
+}
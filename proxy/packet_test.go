@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequest(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		raw    []byte
+		expReq *request
+		expErr error
+	}{
+		{name: "Invalid packet size", raw: []byte{0x42, 0x45, 0, 0, 0, 0, 0xff}, expErr: battleye.ErrInvalidPacketSize},
+		{name: "Invalid header", raw: []byte{0x47, 0x47, 0, 0, 0, 0, 0xff, 0}, expErr: battleye.ErrInvalidHeader},
+		{name: "Invalid end of header", raw: []byte{0x42, 0x45, 0, 0, 0, 0, 0, 0}, expErr: battleye.ErrInvalidEndOfHeader},
+		{name: "Invalid checksum", raw: []byte{0x42, 0x45, 0, 0, 0, 0, 0xff, 0}, expErr: battleye.ErrInvalidChecksum},
+		{name: "Unknown packet type", raw: encode([]byte{0x05, 0}), expErr: battleye.ErrUnknownPacketType},
+		{name: "Command without sequence number", raw: encode([]byte{commandType}), expErr: battleye.ErrInvalidPacketSize},
+		{name: "Login", raw: encode(append([]byte{loginType}, "secret"...)), expReq: &request{typ: loginType, body: "secret"}},
+		{name: "Command", raw: encode(append([]byte{commandType, 3}, "players"...)), expReq: &request{typ: commandType, seq: 3, body: "players"}},
+		{name: "Keep-alive", raw: encode([]byte{commandType, 4}), expReq: &request{typ: commandType, seq: 4}},
+		{name: "Acknowledge", raw: encode([]byte{serverMessageType, 5}), expReq: &request{typ: serverMessageType, seq: 5}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := parseRequest(tc.raw)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expReq, r)
+		})
+	}
+}
+
+func TestResponsePackets(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		resp     string
+		expParts int
+	}{
+		{name: "Empty", expParts: 1},
+		{name: "Single", resp: strings.Repeat("a", fragmentSize), expParts: 1},
+		{name: "Fragmented", resp: strings.Repeat("a", fragmentSize*2+1), expParts: 3},
+		{name: "Truncated", resp: strings.Repeat("a", fragmentSize*(maxFragments+1)), expParts: maxFragments},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkts := responsePackets(7, tc.resp)
+			if !assert.Len(t, pkts, tc.expParts) {
+				return
+			}
+
+			var resp string
+			for i, p := range pkts {
+				assert.True(t, len(p) <= bufferSize)
+				assert.Equal(t, []byte("BE"), p[:2])
+				assert.Equal(t, binary.LittleEndian.Uint32(p[2:6]), crc32.ChecksumIEEE(p[6:]))
+				assert.Equal(t, []byte{0xff, commandType, 7}, p[6:9])
+				if tc.expParts == 1 {
+					resp = string(p[9:])
+					continue
+				}
+				assert.Equal(t, []byte{0x00, byte(tc.expParts), byte(i)}, p[9:12])
+				resp += string(p[12:])
+			}
+			if tc.name != "Truncated" {
+				assert.Equal(t, tc.resp, resp)
+			}
+		})
+	}
+}
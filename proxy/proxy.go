@@ -0,0 +1,607 @@
+// Package proxy multiplexes many RCON sessions over a single BattlEye RCON Client.
+//
+// BattlEye limits the number of RCON admins connected to a server. A Proxy holds a single
+// upstream Client and speaks the server side of the protocol to any number of downstream
+// clients, e.g. the tools of each admin, which log in with the password of the Proxy rather than
+// the one of the server:
+//
+//	c, err := battleye.NewClient("127.0.0.1:2301", "server password")
+//	if err != nil {
+//		// Handle error.
+//	}
+//	p, err := proxy.New(c, "proxy password")
+//	if err != nil {
+//		// Handle error.
+//	}
+//	defer p.Close()
+//
+//	go p.ListenAndServe(":2302")
+//
+// Commands of the downstream sessions are executed one at a time by the upstream Client, whose
+// sequence numbers are its own, and the responses are sent back with the sequence number the
+// downstream session used. Server messages are sent to every session with sequence numbers of the
+// session, and resent until the session acknowledges them.
+//
+// Keep-alive packets, i.e. empty commands, are answered by the Proxy rather than forwarded, as the
+// upstream Client keeps its own connection alive.
+package proxy
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+)
+
+const (
+	// defaultMaxSessions is the default maximum number of downstream sessions.
+	defaultMaxSessions = 32
+
+	// defaultSessionTimeout is the default duration without packets after which a downstream
+	// session is dropped, which is the one of BattlEye servers.
+	defaultSessionTimeout = 45 * time.Second
+
+	// defaultResendInterval is the default interval of resending unacknowledged server messages.
+	defaultResendInterval = 5 * time.Second
+
+	// defaultMaxResends is the default number of times a server message is resent before the
+	// session is dropped.
+	defaultMaxResends = 5
+
+	// bufferSize is the size of the read buffer based on MTU.
+	bufferSize = 1500
+)
+
+var (
+	// ErrNilOption is returned by New if an Option is nil.
+	ErrNilOption = errors.New("proxy: nil option")
+
+	// ErrNilUpstream is returned by New if the upstream is nil.
+	ErrNilUpstream = errors.New("proxy: nil upstream")
+
+	// ErrEmptyPassword is returned by New if the password is empty.
+	ErrEmptyPassword = errors.New("proxy: empty password")
+
+	// ErrInvalidMaxSessions is returned if MaxSessions Option is used with a value less than 1.
+	ErrInvalidMaxSessions = errors.New("proxy: invalid max sessions")
+
+	// ErrInvalidSessionTimeout is returned if SessionTimeout Option is used with a non-positive
+	// timeout.
+	ErrInvalidSessionTimeout = errors.New("proxy: invalid session timeout")
+
+	// ErrInvalidResendInterval is returned if ResendInterval Option is used with a non-positive
+	// interval.
+	ErrInvalidResendInterval = errors.New("proxy: invalid resend interval")
+
+	// ErrInvalidMaxResends is returned if MaxResends Option is used with a negative value.
+	ErrInvalidMaxResends = errors.New("proxy: invalid max resends")
+
+	// ErrServing is returned by Serve if the Proxy is already serving.
+	ErrServing = errors.New("proxy: already serving")
+
+	// ErrClosed is returned by Serve and ListenAndServe after Close.
+	ErrClosed = errors.New("proxy: closed")
+
+	// ErrLoginFailed is the error of a SessionError reported when a login uses a wrong password.
+	ErrLoginFailed = errors.New("proxy: login failed")
+
+	// ErrTooManySessions is the error of a SessionError reported when a login is refused because
+	// of MaxSessions.
+	ErrTooManySessions = errors.New("proxy: too many sessions")
+
+	// ErrSessionTimeout is the error of a SessionError reported when a session is dropped as no
+	// packets were received from it for the session timeout.
+	ErrSessionTimeout = errors.New("proxy: session timed out")
+
+	// ErrNotAcknowledged is the error of a SessionError reported when a session is dropped as it
+	// didn't acknowledge a server message.
+	ErrNotAcknowledged = errors.New("proxy: message not acknowledged")
+)
+
+// Upstream is the interface of the Client a Proxy holds, which *battleye.Client implements.
+type Upstream interface {
+	battleye.Source
+	ExecContext(ctx context.Context, cmd string) (string, error)
+}
+
+var _ Upstream = (*battleye.Client)(nil)
+
+// Session is a downstream session of a Proxy.
+type Session struct {
+	// Addr is the address of the downstream client.
+	Addr net.Addr
+
+	// Since is when the session logged in.
+	Since time.Time
+
+	// Pending is the number of server messages not acknowledged yet.
+	Pending int
+}
+
+// SessionError is reported to the ErrorHandler for errors of a downstream session.
+type SessionError struct {
+	// Addr is the address of the downstream client.
+	Addr net.Addr
+
+	// Err is the error, e.g. ErrSessionTimeout or the error of a command.
+	Err error
+}
+
+// Error implements error.
+func (e *SessionError) Error() string {
+	return fmt.Sprintf("proxy: session %v: %v", e.Addr, e.Err)
+}
+
+// Unwrap returns the error of the session.
+func (e *SessionError) Unwrap() error {
+	return e.Err
+}
+
+// message is a server message sent to a session and not acknowledged yet.
+type message struct {
+	msg      string
+	sent     time.Time
+	attempts int
+}
+
+// reply is the response to a command of a session, kept to answer retransmissions of the command.
+type reply struct {
+	seq  byte
+	cmd  string
+	resp string
+	at   time.Time
+}
+
+// session is the state of a downstream session.
+type session struct {
+	addr  net.Addr
+	since time.Time
+	seen  time.Time
+
+	// seq is the sequence number of the next server message.
+	seq byte
+
+	// pending are the server messages not acknowledged yet by sequence number.
+	pending map[byte]*message
+
+	// running are the sequence numbers of the commands being executed.
+	running map[byte]bool
+
+	// cmdSeq is the sequence number of the last command received.
+	cmdSeq byte
+
+	// reply is the response to the command with cmdSeq, which is dropped once a command with
+	// another sequence number is received, as sequence numbers are reused after wrapping around.
+	reply *reply
+}
+
+// Proxy serves the server side of the BattlEye RCON protocol to many downstream sessions, backed
+// by an Upstream.
+type Proxy struct {
+	up             Upstream
+	password       []byte
+	maxSessions    int
+	sessionTimeout time.Duration
+	resendInterval time.Duration
+	maxResends     int
+	onError        func(err error)
+	handler        battleye.HandlerID
+
+	mu       sync.Mutex
+	pc       net.PacketConn
+	sessions map[string]*session
+	closed   bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Option is a Proxy configuration Option type.
+type Option func(p *Proxy) error
+
+// MaxSessions sets the maximum number of downstream sessions, beyond which logins fail.
+func MaxSessions(n int) Option {
+	return func(p *Proxy) error {
+		if n < 1 {
+			return ErrInvalidMaxSessions
+		}
+		p.maxSessions = n
+		return nil
+	}
+}
+
+// SessionTimeout sets the duration without packets, including keep-alives, after which a
+// downstream session is dropped.
+func SessionTimeout(timeout time.Duration) Option {
+	return func(p *Proxy) error {
+		if timeout <= 0 {
+			return ErrInvalidSessionTimeout
+		}
+		p.sessionTimeout = timeout
+		return nil
+	}
+}
+
+// ResendInterval sets the interval of resending the server messages not acknowledged by a
+// downstream session, which is also the interval of checking the session timeout.
+func ResendInterval(interval time.Duration) Option {
+	return func(p *Proxy) error {
+		if interval <= 0 {
+			return ErrInvalidResendInterval
+		}
+		p.resendInterval = interval
+		return nil
+	}
+}
+
+// MaxResends sets the number of times a server message is resent to a downstream session before
+// the session is dropped.
+func MaxResends(n int) Option {
+	return func(p *Proxy) error {
+		if n < 0 {
+			return ErrInvalidMaxResends
+		}
+		p.maxResends = n
+		return nil
+	}
+}
+
+// ErrorHandler sets the function called with the errors of the Proxy, e.g. a *SessionError for a
+// failed login or a dropped session. It is called from several goroutines.
+func ErrorHandler(f func(err error)) Option {
+	return func(p *Proxy) error {
+		p.onError = f
+		return nil
+	}
+}
+
+// New returns a new Proxy of up for downstream sessions logging in with password. The Proxy
+// subscribes to the messages of up, which can be shared with other consumers.
+func New(up Upstream, password string, options ...Option) (*Proxy, error) {
+	if up == nil {
+		return nil, ErrNilUpstream
+	}
+	if password == "" {
+		return nil, ErrEmptyPassword
+	}
+
+	p := &Proxy{
+		up:             up,
+		password:       []byte(password),
+		maxSessions:    defaultMaxSessions,
+		sessionTimeout: defaultSessionTimeout,
+		resendInterval: defaultResendInterval,
+		maxResends:     defaultMaxResends,
+		sessions:       make(map[string]*session),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.handler = up.OnMessage(p.receive)
+	p.wg.Add(1)
+	go p.housekeep()
+
+	return p, nil
+}
+
+// ListenAndServe listens on the UDP address addr and serves downstream sessions until the Proxy
+// is closed, in which case ErrClosed is returned.
+func (p *Proxy) ListenAndServe(addr string) error {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	return p.Serve(pc)
+}
+
+// Serve serves downstream sessions on pc until the Proxy is closed, in which case ErrClosed is
+// returned. Close closes pc.
+func (p *Proxy) Serve(pc net.PacketConn) error {
+	p.mu.Lock()
+	switch {
+	case p.closed:
+		p.mu.Unlock()
+		return ErrClosed
+	case p.pc != nil:
+		p.mu.Unlock()
+		return ErrServing
+	}
+	p.pc = pc
+	p.mu.Unlock()
+
+	b := make([]byte, bufferSize)
+	for {
+		n, addr, err := pc.ReadFrom(b)
+		if err != nil {
+			p.mu.Lock()
+			closed := p.closed
+			p.mu.Unlock()
+			if closed {
+				return ErrClosed
+			}
+			return err
+		}
+
+		r, err := parseRequest(b[:n])
+		if err != nil {
+			p.error(&SessionError{Addr: addr, Err: err})
+			continue
+		}
+		p.handle(addr, r)
+	}
+}
+
+// Close drops the downstream sessions, closing the connection being served, and cancels the
+// commands being executed. It doesn't close the upstream Client.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.sessions = make(map[string]*session)
+	pc := p.pc
+	p.mu.Unlock()
+
+	p.up.Unregister(p.handler)
+	p.cancel()
+	var err error
+	if pc != nil {
+		err = pc.Close()
+	}
+	p.wg.Wait()
+	return err
+}
+
+// Sessions returns the downstream sessions ordered by address.
+func (p *Proxy) Sessions() []Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sessions := make([]Session, 0, len(p.sessions))
+	for _, s := range p.sessions {
+		sessions = append(sessions, Session{Addr: s.addr, Since: s.since, Pending: len(s.pending)})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Addr.String() < sessions[j].Addr.String()
+	})
+	return sessions
+}
+
+// error reports err to the ErrorHandler.
+func (p *Proxy) error(err error) {
+	if p.onError != nil {
+		p.onError(err)
+	}
+}
+
+// write writes pkt to addr. Write errors are left to the session timeout.
+func (p *Proxy) write(addr net.Addr, pkt []byte) {
+	p.pc.WriteTo(pkt, addr) // nolint: errcheck
+}
+
+// handle handles the request r of the downstream client at addr.
+func (p *Proxy) handle(addr net.Addr, r *request) {
+	switch r.typ {
+	case loginType:
+		p.login(addr, r.body)
+	case commandType:
+		p.command(addr, r.seq, r.body)
+	case serverMessageType:
+		p.acknowledge(addr, r.seq)
+	}
+}
+
+// login starts a session for addr if password is the one of the Proxy. A session already started
+// by addr is replaced.
+func (p *Proxy) login(addr net.Addr, password string) {
+	if subtle.ConstantTimeCompare([]byte(password), p.password) != 1 {
+		p.mu.Lock()
+		p.write(addr, loginPacket(false))
+		p.mu.Unlock()
+		p.error(&SessionError{Addr: addr, Err: ErrLoginFailed})
+		return
+	}
+
+	p.mu.Lock()
+	key := addr.String()
+	if _, ok := p.sessions[key]; !ok && len(p.sessions) >= p.maxSessions {
+		p.write(addr, loginPacket(false))
+		p.mu.Unlock()
+		p.error(&SessionError{Addr: addr, Err: ErrTooManySessions})
+		return
+	}
+
+	now := time.Now()
+	p.sessions[key] = &session{
+		addr:    addr,
+		since:   now,
+		seen:    now,
+		pending: make(map[byte]*message),
+		running: make(map[byte]bool),
+	}
+	// The response is written before releasing the lock, so that it precedes the server messages.
+	p.write(addr, loginPacket(true))
+	p.mu.Unlock()
+}
+
+// command executes cmd, sent by addr with seq, and sends the response back to addr. Commands of
+// unknown sessions are ignored, as BattlEye servers do.
+func (p *Proxy) command(addr net.Addr, seq byte, cmd string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[addr.String()]
+	if !ok || p.closed {
+		return
+	}
+	s.seen = time.Now()
+
+	// Clients retransmit commands which weren't answered in time with the same sequence number,
+	// which mustn't execute them again. A command with another sequence number drops the reply,
+	// so that the sequence number is executed again when it's reused after wrapping around.
+	s.cmdSeq = seq
+	if r := s.reply; r != nil && r.seq != seq {
+		s.reply = nil
+	}
+
+	if cmd == "" {
+		p.respond(s, seq, "")
+		return
+	}
+
+	if s.running[seq] {
+		return
+	}
+	if r := s.reply; r != nil && r.cmd == cmd && time.Since(r.at) < p.sessionTimeout {
+		p.respond(s, seq, r.resp)
+		return
+	}
+
+	s.running[seq] = true
+	p.wg.Add(1)
+	go p.exec(s, seq, cmd)
+}
+
+// exec is a goroutine which executes cmd, sent by s with seq, on the upstream.
+func (p *Proxy) exec(s *session, seq byte, cmd string) {
+	defer p.wg.Done()
+
+	resp, err := p.up.ExecContext(p.ctx, cmd)
+
+	p.mu.Lock()
+	delete(s.running, seq)
+	if err == nil && p.sessions[s.addr.String()] == s {
+		if s.cmdSeq == seq {
+			s.reply = &reply{seq: seq, cmd: cmd, resp: resp, at: time.Now()}
+		}
+		p.respond(s, seq, resp)
+	}
+	p.mu.Unlock()
+
+	// Commands cancelled by Close aren't errors.
+	if err != nil && p.ctx.Err() == nil {
+		p.error(&SessionError{Addr: s.addr, Err: err})
+	}
+}
+
+// respond sends resp, the response to the command sent with seq, to s. p.mu must be held.
+func (p *Proxy) respond(s *session, seq byte, resp string) {
+	for _, pkt := range responsePackets(seq, resp) {
+		p.write(s.addr, pkt)
+	}
+}
+
+// acknowledge marks the server message with seq as received by addr.
+func (p *Proxy) acknowledge(addr net.Addr, seq byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s, ok := p.sessions[addr.String()]; ok {
+		s.seen = time.Now()
+		delete(s.pending, seq)
+	}
+}
+
+// drop removes s and returns the error to report. p.mu must be held.
+func (p *Proxy) drop(s *session, err error) error {
+	delete(p.sessions, s.addr.String())
+	return &SessionError{Addr: s.addr, Err: err}
+}
+
+// receive sends the server message m of the upstream to every session.
+func (p *Proxy) receive(m battleye.Message) {
+	p.broadcast(m.Event.Raw())
+}
+
+// broadcast sends msg to every session. A session which hasn't acknowledged the message with the
+// next sequence number yet is dropped, as it's too far behind.
+func (p *Proxy) broadcast(msg string) {
+	var errs []error
+	p.mu.Lock()
+	now := time.Now()
+	for _, s := range p.sessions {
+		if _, ok := s.pending[s.seq]; ok {
+			errs = append(errs, p.drop(s, ErrNotAcknowledged))
+			continue
+		}
+		s.pending[s.seq] = &message{msg: msg, sent: now, attempts: 1}
+		p.write(s.addr, messagePacket(s.seq, msg))
+		s.seq++
+	}
+	p.mu.Unlock()
+
+	for _, err := range errs {
+		p.error(err)
+	}
+}
+
+// housekeep is a goroutine which resends unacknowledged server messages and drops the sessions
+// which timed out until the Proxy is closed.
+func (p *Proxy) housekeep() {
+	defer p.wg.Done()
+
+	t := time.NewTicker(p.resendInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-t.C:
+			for _, err := range p.check() {
+				p.error(err)
+			}
+		}
+	}
+}
+
+// check resends the server messages which weren't acknowledged within the resend interval, drops
+// the sessions which timed out or didn't acknowledge a message after the resends, and returns the
+// errors to report.
+func (p *Proxy) check() []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	now := time.Now()
+	for _, s := range p.sessions {
+		if now.Sub(s.seen) > p.sessionTimeout {
+			errs = append(errs, p.drop(s, ErrSessionTimeout))
+			continue
+		}
+
+		if s.reply != nil && now.Sub(s.reply.at) >= p.sessionTimeout {
+			s.reply = nil
+		}
+
+		for seq, m := range s.pending {
+			if now.Sub(m.sent) < p.resendInterval {
+				continue
+			}
+			if m.attempts > p.maxResends {
+				errs = append(errs, p.drop(s, ErrNotAcknowledged))
+				break
+			}
+			m.attempts++
+			m.sent = now
+			p.write(s.addr, messagePacket(seq, m.msg))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,391 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPassword = "secret"
+
+// fakeUpstream is an Upstream whose messages are sent by the test. It responds to slow once
+// released, and to other commands immediately.
+type fakeUpstream struct {
+	release chan struct{}
+	done    chan struct{}
+
+	mu       sync.Mutex
+	cmds     []string
+	handlers map[battleye.HandlerID]func(battleye.Message)
+	nextID   battleye.HandlerID
+}
+
+func newFakeUpstream() *fakeUpstream {
+	return &fakeUpstream{
+		release:  make(chan struct{}),
+		done:     make(chan struct{}),
+		handlers: make(map[battleye.HandlerID]func(battleye.Message)),
+	}
+}
+
+func (u *fakeUpstream) ExecContext(ctx context.Context, cmd string) (string, error) {
+	u.mu.Lock()
+	u.cmds = append(u.cmds, cmd)
+	u.mu.Unlock()
+
+	switch cmd {
+	case "slow":
+		select {
+		case <-u.release:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	case "long":
+		return strings.Repeat("0123456789", 500), nil
+	}
+	return "response to " + cmd, nil
+}
+
+func (u *fakeUpstream) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.nextID++
+	u.handlers[u.nextID] = f
+	return u.nextID
+}
+
+func (u *fakeUpstream) Unregister(id battleye.HandlerID) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	_, ok := u.handlers[id]
+	delete(u.handlers, id)
+	return ok
+}
+
+func (u *fakeUpstream) Done() <-chan struct{} {
+	return u.done
+}
+
+// send calls the registered handlers with msg.
+func (u *fakeUpstream) send(msg string) {
+	u.mu.Lock()
+	handlers := make([]func(battleye.Message), 0, len(u.handlers))
+	for _, f := range u.handlers {
+		handlers = append(handlers, f)
+	}
+	u.mu.Unlock()
+
+	m := battleye.Message{Time: time.Now(), Event: event.Parse(msg)}
+	for _, f := range handlers {
+		f(m)
+	}
+}
+
+// executed returns the commands executed by u.
+func (u *fakeUpstream) executed() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.cmds...)
+}
+
+// errorRecorder records the errors reported to an ErrorHandler.
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+// has returns true if a *SessionError of addr, or of any address if addr is empty, with target
+// has been reported.
+func (r *errorRecorder) has(addr string, target error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, err := range r.errs {
+		var serr *SessionError
+		if errors.As(err, &serr) && (addr == "" || serr.Addr.String() == addr) && errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// startProxy returns a Proxy of up serving on a local address, which is returned too, and a
+// channel receiving the error returned by Serve.
+func startProxy(t *testing.T, up Upstream, options ...Option) (*Proxy, string, chan error) {
+	p, err := New(up, testPassword, options...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	served := make(chan error, 1)
+	go func() { served <- p.Serve(pc) }()
+	t.Cleanup(func() { p.Close() }) // nolint: errcheck
+	return p, pc.LocalAddr().String(), served
+}
+
+// rawClient is a downstream client speaking the protocol packet by packet.
+type rawClient struct {
+	t    *testing.T
+	conn net.Conn
+}
+
+func newRawClient(t *testing.T, addr string) *rawClient {
+	conn, err := net.Dial("udp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() }) // nolint: errcheck
+	return &rawClient{t: t, conn: conn}
+}
+
+// Addr returns the local address of c.
+func (c *rawClient) Addr() string {
+	return c.conn.LocalAddr().String()
+}
+
+// send sends a packet with payload.
+func (c *rawClient) send(payload ...byte) {
+	_, err := c.conn.Write(encode(payload))
+	assert.NoError(c.t, err)
+}
+
+// read returns the payload of the next packet, or nil if none is received within timeout.
+func (c *rawClient) read(timeout time.Duration) []byte {
+	assert.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(timeout)))
+	b := make([]byte, bufferSize)
+	n, err := c.conn.Read(b)
+	if err != nil {
+		return nil
+	}
+	assert.Equal(c.t, binary.LittleEndian.Uint32(b[2:6]), crc32.ChecksumIEEE(b[6:n]))
+	return b[headerSize:n]
+}
+
+// login logs c in with password and returns true if it succeeded.
+func (c *rawClient) login(password string) bool {
+	c.send(append([]byte{loginType}, password...)...)
+	return assert.ObjectsAreEqual([]byte{loginType, loginSuccess}, c.read(time.Second))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	up := newFakeUpstream()
+	testcases := []struct {
+		name     string
+		up       Upstream
+		password string
+		opts     []Option
+		expErr   error
+	}{
+		{name: "Nil upstream", password: testPassword, expErr: ErrNilUpstream},
+		{name: "Empty password", up: up, expErr: ErrEmptyPassword},
+		{name: "Nil option", up: up, password: testPassword, opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Invalid max sessions", up: up, password: testPassword, opts: []Option{MaxSessions(0)}, expErr: ErrInvalidMaxSessions},
+		{name: "Invalid session timeout", up: up, password: testPassword, opts: []Option{SessionTimeout(0)}, expErr: ErrInvalidSessionTimeout},
+		{name: "Invalid resend interval", up: up, password: testPassword, opts: []Option{ResendInterval(0)}, expErr: ErrInvalidResendInterval},
+		{name: "Invalid max resends", up: up, password: testPassword, opts: []Option{MaxResends(-1)}, expErr: ErrInvalidMaxResends},
+		{name: "Valid", up: up, password: testPassword, opts: []Option{
+			MaxSessions(2),
+			SessionTimeout(time.Second),
+			ResendInterval(time.Second),
+			MaxResends(0),
+			ErrorHandler(func(error) {}),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New(tc.up, tc.password, tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.NoError(t, p.Close())
+				assert.NoError(t, p.Close())
+				assert.Equal(t, ErrClosed, p.ListenAndServe("127.0.0.1:0"))
+			}
+		})
+	}
+}
+
+func TestProxy(t *testing.T) {
+	up := newFakeUpstream()
+	var reported errorRecorder
+	p, addr, served := startProxy(t, up, ErrorHandler(reported.handle))
+
+	_, err := battleye.NewClient(addr, "wrong", battleye.Timeout(time.Second))
+	assert.Equal(t, battleye.ErrLoginFailed, err)
+
+	var clients []*battleye.Client
+	for i := 0; i < 2; i++ {
+		c, err := battleye.NewClient(addr, testPassword, battleye.Timeout(time.Second))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer c.Close() // nolint: errcheck
+		clients = append(clients, c)
+	}
+	assert.Len(t, p.Sessions(), 2)
+
+	// The responses are routed back to the session which sent the command.
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func(c *battleye.Client, cmd string) {
+				defer wg.Done()
+				resp, err := c.Exec(cmd)
+				assert.NoError(t, err)
+				assert.Equal(t, "response to "+cmd, resp)
+			}(c, fmt.Sprintf("command %v.%v", i, j))
+		}
+	}
+	wg.Wait()
+	assert.Len(t, up.executed(), 10)
+
+	resp, err := clients[0].Exec("long")
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("0123456789", 500), resp)
+
+	// Server messages are sent to every session, which acknowledges them.
+	up.send("RCon admin #1 (127.0.0.1:2304) logged in")
+	for _, c := range clients {
+		select {
+		case msg := <-c.Messages():
+			assert.Equal(t, "RCon admin #1 (127.0.0.1:2304) logged in", msg)
+		case <-time.After(time.Second):
+			assert.Fail(t, "message not received")
+		}
+	}
+	assert.Eventually(t, func() bool {
+		for _, s := range p.Sessions() {
+			if s.Pending != 0 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, p.Close())
+	assert.Equal(t, ErrClosed, <-served)
+	assert.Empty(t, p.Sessions())
+	assert.True(t, reported.has("", ErrLoginFailed))
+}
+
+func TestProxyCommands(t *testing.T) {
+	up := newFakeUpstream()
+	_, addr, _ := startProxy(t, up)
+
+	// Commands of clients which haven't logged in are ignored.
+	anon := newRawClient(t, addr)
+	anon.send(append([]byte{commandType, 0}, "players"...)...)
+	assert.Nil(t, anon.read(50*time.Millisecond))
+
+	c := newRawClient(t, addr)
+	if !assert.True(t, c.login(testPassword)) {
+		return
+	}
+
+	// Keep-alives are answered by the Proxy.
+	c.send(commandType, 0)
+	assert.Equal(t, []byte{commandType, 0}, c.read(time.Second))
+
+	// A command retransmitted while executing isn't executed again.
+	c.send(append([]byte{commandType, 1}, "slow"...)...)
+	c.send(append([]byte{commandType, 1}, "slow"...)...)
+	assert.Nil(t, c.read(50*time.Millisecond))
+	close(up.release)
+	assert.Equal(t, append([]byte{commandType, 1}, "response to slow"...), c.read(time.Second))
+	assert.Nil(t, c.read(50*time.Millisecond))
+
+	// A command retransmitted after the response was lost is answered again.
+	c.send(append([]byte{commandType, 1}, "slow"...)...)
+	assert.Equal(t, append([]byte{commandType, 1}, "response to slow"...), c.read(time.Second))
+	assert.Equal(t, []string{"slow"}, up.executed())
+}
+
+func TestProxySequenceWraparound(t *testing.T) {
+	up := newFakeUpstream()
+	_, addr, _ := startProxy(t, up)
+
+	c := newRawClient(t, addr)
+	if !assert.True(t, c.login(testPassword)) {
+		return
+	}
+
+	// Sequence numbers are reused after wrapping around, and then execute the command again
+	// rather than getting the response of the last time.
+	n := 256 + 10
+	for i := 0; i < n; i++ {
+		seq := byte(i)
+		c.send(append([]byte{commandType, seq}, "players"...)...)
+		if !assert.Equal(t, append([]byte{commandType, seq}, "response to players"...), c.read(time.Second), i) {
+			return
+		}
+	}
+	assert.Len(t, up.executed(), n)
+
+	// A retransmission of the last command is still answered from the reply.
+	c.send(append([]byte{commandType, byte(n - 1)}, "players"...)...)
+	assert.Equal(t, append([]byte{commandType, byte(n - 1)}, "response to players"...), c.read(time.Second))
+	assert.Len(t, up.executed(), n)
+}
+
+func TestProxySessions(t *testing.T) {
+	up := newFakeUpstream()
+	var reported errorRecorder
+	p, addr, _ := startProxy(t, up,
+		MaxSessions(2),
+		ResendInterval(20*time.Millisecond),
+		MaxResends(2),
+		SessionTimeout(time.Second),
+		ErrorHandler(reported.handle),
+	)
+
+	acking, lagging, refused := newRawClient(t, addr), newRawClient(t, addr), newRawClient(t, addr)
+	assert.True(t, acking.login(testPassword))
+	assert.True(t, lagging.login(testPassword))
+	assert.False(t, refused.login(testPassword))
+	assert.True(t, reported.has(refused.Addr(), ErrTooManySessions))
+
+	up.send("(Global) Kerry: hello")
+	msg := append([]byte{serverMessageType, 0}, "(Global) Kerry: hello"...)
+	assert.Equal(t, msg, acking.read(time.Second))
+	acking.send(serverMessageType, 0)
+
+	// Unacknowledged messages are resent with the same sequence number, until the session is
+	// dropped.
+	assert.Equal(t, msg, lagging.read(time.Second))
+	assert.Equal(t, msg, lagging.read(time.Second))
+	assert.Eventually(t, func() bool { return len(p.Sessions()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.True(t, reported.has(lagging.Addr(), ErrNotAcknowledged))
+	if sessions := p.Sessions(); assert.Len(t, sessions, 1) {
+		assert.Equal(t, acking.Addr(), sessions[0].Addr.String())
+		assert.Equal(t, 0, sessions[0].Pending)
+	}
+
+	// Sessions which send no packets time out.
+	assert.Eventually(t, func() bool { return len(p.Sessions()) == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, reported.has(acking.Addr(), ErrSessionTimeout))
+}
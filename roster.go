@@ -0,0 +1,396 @@
+package battleye
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/multiplay/go-battleye/event"
+)
+
+const (
+	// defaultPollInterval is the default interval of refreshing a Roster using the players command.
+	defaultPollInterval = 30 * time.Second
+)
+
+// RosterDiff is the difference between two player lists.
+type RosterDiff struct {
+	// Joined are the players which are only in the new list.
+	Joined []Player
+
+	// Left are the players which are only in the old list.
+	Left []Player
+}
+
+// Empty returns true if there is no difference.
+func (d RosterDiff) Empty() bool {
+	return len(d.Joined) == 0 && len(d.Left) == 0
+}
+
+// DiffPlayers returns the players which joined and left between the old and new player lists.
+// Players are matched by ID and name, so a player number reused by another player is reported
+// as a leave and a join.
+func DiffPlayers(old, new []Player) RosterDiff {
+	var d RosterDiff
+	oldByID := make(map[int]Player, len(old))
+	for _, p := range old {
+		oldByID[p.ID] = p
+	}
+	newByID := make(map[int]Player, len(new))
+	for _, p := range new {
+		newByID[p.ID] = p
+		if o, ok := oldByID[p.ID]; !ok || !samePlayer(o, p) {
+			d.Joined = append(d.Joined, p)
+		}
+	}
+	for _, p := range old {
+		if n, ok := newByID[p.ID]; !ok || !samePlayer(p, n) {
+			d.Left = append(d.Left, p)
+		}
+	}
+	return d
+}
+
+// samePlayer returns true if a and b are the same player.
+func samePlayer(a, b Player) bool {
+	return a.ID == b.ID && a.Name == b.Name
+}
+
+// poll is the result of a players command.
+type poll struct {
+	started time.Time
+	players []Player
+	applied chan struct{}
+}
+
+// Roster keeps an in-memory list of the players on the server of a Client.
+//
+// The list is updated by the player events received from the server and is repaired by
+// periodically polling the players command, so joins and leaves are noticed even if server
+// messages were lost.
+type Roster struct {
+	client   *Client
+	d        *Dispatcher
+	interval time.Duration
+	events   chan event.Event
+	polls    chan poll
+	done     *done
+	wg       sync.WaitGroup
+
+	mu      sync.RWMutex
+	players map[int]Player
+	touched map[int]time.Time
+
+	handlersLock sync.Mutex
+	onJoin       []func(Player)
+	onLeave      []func(Player)
+}
+
+// RosterOption is a Roster configuration Option type.
+type RosterOption func(r *Roster) error
+
+// PollInterval sets the interval of refreshing a Roster using the players command.
+func PollInterval(interval time.Duration) RosterOption {
+	return func(r *Roster) error {
+		if interval <= 0 {
+			return ErrInvalidPollInterval
+		}
+		r.interval = interval
+		return nil
+	}
+}
+
+// NewRoster returns a new Roster of the players on the server of c.
+// The Roster is populated before NewRoster returns. Until it's closed, the names of its players
+// are used to parse the chat messages received by c, so that names containing ": " are
+// attributed correctly.
+func NewRoster(c *Client, options ...RosterOption) (*Roster, error) {
+	r := &Roster{
+		client:   c,
+		interval: defaultPollInterval,
+		events:   make(chan event.Event),
+		polls:    make(chan poll),
+		done:     newDone(),
+		players:  make(map[int]Player),
+		touched:  make(map[int]time.Time),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	d, err := NewDispatcher(c)
+	if err != nil {
+		return nil, err
+	}
+	r.d = d
+	d.On(isPlayerEvent, r.queue)
+
+	r.wg.Add(2)
+	go r.run()
+	go r.poller()
+
+	if err := r.Refresh(); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	c.rosterLock.Lock()
+	c.roster = r
+	c.rosterLock.Unlock()
+
+	return r, nil
+}
+
+// Close stops updating r.
+func (r *Roster) Close() {
+	c := r.client
+	c.rosterLock.Lock()
+	if c.roster == r {
+		c.roster = nil
+	}
+	c.rosterLock.Unlock()
+
+	r.done.Done()
+	r.d.Close()
+	r.wg.Wait()
+}
+
+// Players returns a snapshot of the players on the server ordered by ID.
+func (r *Roster) Players() []Player {
+	r.mu.RLock()
+	players := make([]Player, 0, len(r.players))
+	for _, p := range r.players {
+		players = append(players, p)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
+
+// names returns the names of the players on the server.
+func (r *Roster) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.players))
+	for _, p := range r.players {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Player returns the player identified by id.
+func (r *Roster) Player(id int) (Player, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.players[id]
+	return p, ok
+}
+
+// OnJoin registers f to be called when a player joins the server.
+// Handlers are called one at a time and must not block.
+func (r *Roster) OnJoin(f func(Player)) {
+	r.handlersLock.Lock()
+	defer r.handlersLock.Unlock()
+
+	r.onJoin = append(r.onJoin, f)
+}
+
+// OnLeave registers f to be called when a player leaves the server.
+// Handlers are called one at a time and must not block.
+func (r *Roster) OnLeave(f func(Player)) {
+	r.handlersLock.Lock()
+	defer r.handlersLock.Unlock()
+
+	r.onLeave = append(r.onLeave, f)
+}
+
+// Refresh updates r using the players command.
+func (r *Roster) Refresh() error {
+	p := poll{started: time.Now(), applied: make(chan struct{})}
+
+	var err error
+	if p.players, err = r.client.Players(); err != nil {
+		return err
+	}
+
+	select {
+	case <-r.done.C():
+		return nil
+	case r.polls <- p:
+	}
+	<-p.applied
+
+	return nil
+}
+
+// queue passes e to the run goroutine.
+func (r *Roster) queue(e event.Event) {
+	select {
+	case <-r.done.C():
+	case r.events <- e:
+	}
+}
+
+// poller is a goroutine which periodically refreshes r.
+func (r *Roster) poller() {
+	defer r.wg.Done()
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.done.C():
+			return
+		case <-t.C:
+			// Errors are not fatal, the next poll will retry.
+			r.Refresh() // nolint: errcheck
+		}
+	}
+}
+
+// run is a goroutine which applies events and polls to r one at a time.
+func (r *Roster) run() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-r.done.C():
+			return
+		case e := <-r.events:
+			r.notify(r.applyEvent(e))
+		case p := <-r.polls:
+			r.notify(r.applyPoll(p))
+			close(p.applied)
+		}
+	}
+}
+
+// applyEvent updates the players according to e.
+func (r *Roster) applyEvent(e event.Event) RosterDiff {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var d RosterDiff
+	var id int
+	switch e := e.(type) {
+	case *event.PlayerConnected:
+		id = e.ID
+		p := Player{ID: e.ID, Name: e.Name, IP: e.IP, Port: e.Port, Ping: -1}
+		if o, ok := r.players[id]; ok {
+			if samePlayer(o, p) {
+				break
+			}
+			d.Left = append(d.Left, o)
+		}
+		r.players[id] = p
+		d.Joined = append(d.Joined, p)
+	case *event.PlayerGUID:
+		id = e.ID
+		if p, ok := r.players[id]; ok && p.Name == e.Name {
+			p.GUID = e.GUID
+			r.players[id] = p
+		}
+	case *event.PlayerVerified:
+		id = e.ID
+		if p, ok := r.players[id]; ok && p.Name == e.Name {
+			p.GUID, p.Verified = e.GUID, true
+			r.players[id] = p
+		}
+	case *event.PlayerDisconnected:
+		id = e.ID
+		d.Left = r.remove(id)
+	case *event.PlayerKicked:
+		id = e.ID
+		d.Left = r.remove(id)
+	}
+	r.touched[id] = time.Now()
+
+	return d
+}
+
+// remove removes the player identified by id and returns it.
+// mu must be held.
+func (r *Roster) remove(id int) []Player {
+	p, ok := r.players[id]
+	if !ok {
+		return nil
+	}
+	delete(r.players, id)
+	return []Player{p}
+}
+
+// applyPoll replaces the players with the result of p, except those changed by events since p
+// started as the result may not reflect them yet.
+func (r *Roster) applyPoll(p poll) RosterDiff {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh := make(map[int]bool)
+	for id, t := range r.touched {
+		if t.After(p.started) {
+			fresh[id] = true
+		} else {
+			delete(r.touched, id)
+		}
+	}
+
+	old := make([]Player, 0, len(r.players))
+	for id, pl := range r.players {
+		if !fresh[id] {
+			old = append(old, pl)
+			delete(r.players, id)
+		}
+	}
+
+	var polled []Player
+	for _, pl := range p.players {
+		if fresh[pl.ID] {
+			continue
+		}
+		polled = append(polled, pl)
+		r.players[pl.ID] = pl
+	}
+
+	return DiffPlayers(old, polled)
+}
+
+// notify calls the handlers for the players in d.
+func (r *Roster) notify(d RosterDiff) {
+	if d.Empty() {
+		return
+	}
+
+	r.handlersLock.Lock()
+	defer r.handlersLock.Unlock()
+
+	for _, p := range d.Left {
+		for _, f := range r.onLeave {
+			f(p)
+		}
+	}
+	for _, p := range d.Joined {
+		for _, f := range r.onJoin {
+			f(p)
+		}
+	}
+}
+
+// isPlayerEvent returns true if e changes the player list.
+func isPlayerEvent(e event.Event) bool {
+	switch e.(type) {
+	case *event.PlayerConnected, *event.PlayerGUID, *event.PlayerVerified, *event.PlayerDisconnected, *event.PlayerKicked:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,200 @@
+package battleye
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPlayers(t *testing.T) {
+	t.Parallel()
+
+	kerry := Player{ID: 1, Name: "Kerry"}
+	miller := Player{ID: 2, Name: "Miller"}
+	reused := Player{ID: 2, Name: "Cooper"}
+
+	testcases := []struct {
+		name string
+		old  []Player
+		new  []Player
+		exp  RosterDiff
+	}{
+		{
+			name: "No change",
+			old:  []Player{kerry, miller},
+			new:  []Player{miller, kerry},
+		},
+		{
+			name: "Join and leave",
+			old:  []Player{kerry},
+			new:  []Player{miller},
+			exp:  RosterDiff{Joined: []Player{miller}, Left: []Player{kerry}},
+		},
+		{
+			name: "Reused ID",
+			old:  []Player{kerry, miller},
+			new:  []Player{kerry, reused},
+			exp:  RosterDiff{Joined: []Player{reused}, Left: []Player{miller}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := DiffPlayers(tc.old, tc.new)
+			assert.Equal(t, tc.exp, d)
+			assert.Equal(t, tc.exp.Empty(), d.Empty())
+		})
+	}
+}
+
+func TestRosterApply(t *testing.T) {
+	t.Parallel()
+
+	r := &Roster{players: make(map[int]Player), touched: make(map[int]time.Time)}
+
+	started := time.Now()
+	d := r.applyEvent(event.Parse("Player #1 Kerry (10.0.0.2:2304) connected"))
+	assert.Equal(t, []Player{{ID: 1, Name: "Kerry", IP: net.ParseIP("10.0.0.2"), Port: 2304, Ping: -1}}, d.Joined)
+
+	r.applyEvent(event.Parse("Verified GUID (d41d8cd98f00b204e9800998ecf8427e) of player #1 Kerry"))
+	p, ok := r.players[1]
+	assert.True(t, ok)
+	assert.True(t, p.Verified)
+	assert.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", p.GUID)
+
+	// A poll which started before the connect event does not remove the player.
+	d = r.applyPoll(poll{started: started, players: []Player{{ID: 2, Name: "Miller"}}})
+	assert.Equal(t, RosterDiff{Joined: []Player{{ID: 2, Name: "Miller"}}}, d)
+	assert.Len(t, r.players, 2)
+
+	// A later poll repairs missed disconnects.
+	d = r.applyPoll(poll{started: time.Now(), players: []Player{{ID: 2, Name: "Miller", Ping: 30}}})
+	assert.Equal(t, []Player{p}, d.Left)
+	assert.Empty(t, d.Joined)
+	assert.Equal(t, 30, r.players[2].Ping)
+
+	d = r.applyEvent(event.Parse("Player #2 Miller (-) has been kicked by BattlEye: Admin Kick"))
+	assert.Equal(t, []Player{{ID: 2, Name: "Miller", Ping: 30}}, d.Left)
+	assert.Empty(t, r.players)
+}
+
+func TestClientRoster(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SetResponse("players", testPlayersResponse)
+	r, err := NewRoster(c, PollInterval(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	assert.Len(t, r.Players(), 3)
+
+	var mu sync.Mutex
+	var joined, left []string
+	r.OnJoin(func(p Player) {
+		mu.Lock()
+		defer mu.Unlock()
+		joined = append(joined, p.Name)
+	})
+	r.OnLeave(func(p Player) {
+		mu.Lock()
+		defer mu.Unlock()
+		left = append(left, p.Name)
+	})
+
+	s.SendServerMessage("Player #3 Kerry (10.0.0.5:2304) connected")
+	assert.Eventually(t, func() bool {
+		_, ok := r.Player(3)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	// The disconnect of player #0 was never received, polling repairs it.
+	s.SetResponse("players", "Players on server:\n1   10.0.0.3:2316  -1  -  Jane (Lobby)\n2   10.0.0.4:2304  -  -  Connecting\n3   10.0.0.5:2304  12  -  Kerry")
+	if !assert.NoError(t, r.Refresh()) {
+		return
+	}
+
+	players := r.Players()
+	if !assert.Len(t, players, 3) {
+		return
+	}
+	assert.Equal(t, 1, players[0].ID)
+	assert.Equal(t, 12, players[2].Ping)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Kerry"}, joined)
+	assert.Equal(t, []string{"John Doe"}, left)
+
+	assert.Equal(t, ErrInvalidPollInterval, PollInterval(0)(r))
+}
+
+func TestClientRosterNames(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	chats := make(chan *event.Chat, 2)
+	c.OnMessage(func(m Message) {
+		if e, ok := m.Event.(*event.Chat); ok {
+			chats <- e
+		}
+	})
+	chat := func() *event.Chat {
+		select {
+		case e := <-chats:
+			return e
+		case <-time.After(time.Second):
+			assert.Fail(t, "chat not received")
+			return &event.Chat{}
+		}
+	}
+
+	// Without a roster the name ends at the first ": ".
+	s.SendServerMessage("(Global) Dr: Who: hello")
+	assert.Equal(t, "Dr", chat().Name)
+
+	s.SetResponse("players", "Players on server:\n1   10.0.0.3:2316  -1  -  Dr: Who")
+	r, err := NewRoster(c, PollInterval(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The client parses chat using the names of the players on the roster.
+	s.SendServerMessage("(Global) Dr: Who: hello")
+	e := chat()
+	assert.Equal(t, "Dr: Who", e.Name)
+	assert.Equal(t, "hello", e.Text)
+
+	r.Close()
+	s.SendServerMessage("(Global) Dr: Who: hello")
+	assert.Equal(t, "Dr", chat().Name)
+}
@@ -0,0 +1,1245 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: battleye.proto
+
+// Package battleye.v1 administers BattlEye RCON servers.
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ListServersRequest is the request of ListServers.
+type ListServersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServersRequest) Reset() {
+	*x = ListServersRequest{}
+	mi := &file_battleye_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServersRequest) ProtoMessage() {}
+
+func (x *ListServersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServersRequest.ProtoReflect.Descriptor instead.
+func (*ListServersRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{0}
+}
+
+// ListServersResponse is the response of ListServers.
+type ListServersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sorted names of the servers.
+	Servers       []string `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServersResponse) Reset() {
+	*x = ListServersResponse{}
+	mi := &file_battleye_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServersResponse) ProtoMessage() {}
+
+func (x *ListServersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServersResponse.ProtoReflect.Descriptor instead.
+func (*ListServersResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListServersResponse) GetServers() []string {
+	if x != nil {
+		return x.Servers
+	}
+	return nil
+}
+
+// ExecRequest is the request of Exec.
+type ExecRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Command to execute.
+	Command       string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_battleye_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExecRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *ExecRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+// ExecResponse is the response of Exec.
+type ExecResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Response of the server.
+	Response      string `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
+	mi := &file_battleye_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResponse) ProtoMessage() {}
+
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExecResponse) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+// ListPlayersRequest is the request of ListPlayers.
+type ListPlayersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server        string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlayersRequest) Reset() {
+	*x = ListPlayersRequest{}
+	mi := &file_battleye_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlayersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlayersRequest) ProtoMessage() {}
+
+func (x *ListPlayersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlayersRequest.ProtoReflect.Descriptor instead.
+func (*ListPlayersRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPlayersRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+// ListPlayersResponse is the response of ListPlayers.
+type ListPlayersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Connected players.
+	Players       []*Player `protobuf:"bytes,1,rep,name=players,proto3" json:"players,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlayersResponse) Reset() {
+	*x = ListPlayersResponse{}
+	mi := &file_battleye_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlayersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlayersResponse) ProtoMessage() {}
+
+func (x *ListPlayersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlayersResponse.ProtoReflect.Descriptor instead.
+func (*ListPlayersResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPlayersResponse) GetPlayers() []*Player {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+// Player is a player connected to a server.
+type Player struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Player number assigned by the server.
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Address the player is connected from.
+	Ip string `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Port the player is connected from.
+	Port int32 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	// Latency in milliseconds or -1 if not known yet.
+	Ping int32 `protobuf:"varint,4,opt,name=ping,proto3" json:"ping,omitempty"`
+	// BattlEye GUID or empty if not known yet.
+	Guid string `protobuf:"bytes,5,opt,name=guid,proto3" json:"guid,omitempty"`
+	// Whether the server verified the GUID.
+	Verified bool `protobuf:"varint,6,opt,name=verified,proto3" json:"verified,omitempty"`
+	// In-game name.
+	Name string `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	// Whether the player is in the lobby.
+	Lobby         bool `protobuf:"varint,8,opt,name=lobby,proto3" json:"lobby,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Player) Reset() {
+	*x = Player{}
+	mi := &file_battleye_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Player) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Player) ProtoMessage() {}
+
+func (x *Player) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Player.ProtoReflect.Descriptor instead.
+func (*Player) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Player) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Player) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Player) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Player) GetPing() int32 {
+	if x != nil {
+		return x.Ping
+	}
+	return 0
+}
+
+func (x *Player) GetGuid() string {
+	if x != nil {
+		return x.Guid
+	}
+	return ""
+}
+
+func (x *Player) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Player) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Player) GetLobby() bool {
+	if x != nil {
+		return x.Lobby
+	}
+	return false
+}
+
+// ListBansRequest is the request of ListBans.
+type ListBansRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server        string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBansRequest) Reset() {
+	*x = ListBansRequest{}
+	mi := &file_battleye_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBansRequest) ProtoMessage() {}
+
+func (x *ListBansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBansRequest.ProtoReflect.Descriptor instead.
+func (*ListBansRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListBansRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+// ListBansResponse is the response of ListBans.
+type ListBansResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Entries of the ban list.
+	Bans          []*Ban `protobuf:"bytes,1,rep,name=bans,proto3" json:"bans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBansResponse) Reset() {
+	*x = ListBansResponse{}
+	mi := &file_battleye_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBansResponse) ProtoMessage() {}
+
+func (x *ListBansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBansResponse.ProtoReflect.Descriptor instead.
+func (*ListBansResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListBansResponse) GetBans() []*Ban {
+	if x != nil {
+		return x.Bans
+	}
+	return nil
+}
+
+// Ban is an entry of the ban list of a server.
+type Ban struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ban number assigned by the server.
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Banned GUID or empty if the ban is by IP.
+	Guid string `protobuf:"bytes,2,opt,name=guid,proto3" json:"guid,omitempty"`
+	// Banned address or empty if the ban is by GUID.
+	Ip string `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Remaining duration in minutes, -1 if the ban never expires or 0 if it has expired.
+	MinutesLeft int32 `protobuf:"varint,4,opt,name=minutes_left,json=minutesLeft,proto3" json:"minutes_left,omitempty"`
+	// Reason given when the ban was added.
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ban) Reset() {
+	*x = Ban{}
+	mi := &file_battleye_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ban) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ban) ProtoMessage() {}
+
+func (x *Ban) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ban.ProtoReflect.Descriptor instead.
+func (*Ban) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Ban) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Ban) GetGuid() string {
+	if x != nil {
+		return x.Guid
+	}
+	return ""
+}
+
+func (x *Ban) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Ban) GetMinutesLeft() int32 {
+	if x != nil {
+		return x.MinutesLeft
+	}
+	return 0
+}
+
+func (x *Ban) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// BanRequest is the request of Ban.
+type BanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Target of the ban.
+	//
+	// Types that are valid to be assigned to Target:
+	//
+	//	*BanRequest_PlayerId
+	//	*BanRequest_Guid
+	Target isBanRequest_Target `protobuf_oneof:"target"`
+	// Duration in minutes, or -1 to ban forever.
+	Minutes int32 `protobuf:"varint,4,opt,name=minutes,proto3" json:"minutes,omitempty"`
+	// Reason shown to the player.
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanRequest) Reset() {
+	*x = BanRequest{}
+	mi := &file_battleye_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanRequest) ProtoMessage() {}
+
+func (x *BanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanRequest.ProtoReflect.Descriptor instead.
+func (*BanRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BanRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *BanRequest) GetTarget() isBanRequest_Target {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *BanRequest) GetPlayerId() int32 {
+	if x != nil {
+		if x, ok := x.Target.(*BanRequest_PlayerId); ok {
+			return x.PlayerId
+		}
+	}
+	return 0
+}
+
+func (x *BanRequest) GetGuid() string {
+	if x != nil {
+		if x, ok := x.Target.(*BanRequest_Guid); ok {
+			return x.Guid
+		}
+	}
+	return ""
+}
+
+func (x *BanRequest) GetMinutes() int32 {
+	if x != nil {
+		return x.Minutes
+	}
+	return 0
+}
+
+func (x *BanRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type isBanRequest_Target interface {
+	isBanRequest_Target()
+}
+
+type BanRequest_PlayerId struct {
+	// Number of a connected player, who is kicked.
+	PlayerId int32 `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3,oneof"`
+}
+
+type BanRequest_Guid struct {
+	// BattlEye GUID, whether or not its player is connected.
+	Guid string `protobuf:"bytes,3,opt,name=guid,proto3,oneof"`
+}
+
+func (*BanRequest_PlayerId) isBanRequest_Target() {}
+
+func (*BanRequest_Guid) isBanRequest_Target() {}
+
+// BanResponse is the response of Ban.
+type BanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanResponse) Reset() {
+	*x = BanResponse{}
+	mi := &file_battleye_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanResponse) ProtoMessage() {}
+
+func (x *BanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanResponse.ProtoReflect.Descriptor instead.
+func (*BanResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{11}
+}
+
+// KickRequest is the request of Kick.
+type KickRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Number of the player, which is required.
+	PlayerId *int32 `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3,oneof" json:"player_id,omitempty"`
+	// Reason shown to the player.
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickRequest) Reset() {
+	*x = KickRequest{}
+	mi := &file_battleye_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickRequest) ProtoMessage() {}
+
+func (x *KickRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickRequest.ProtoReflect.Descriptor instead.
+func (*KickRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *KickRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *KickRequest) GetPlayerId() int32 {
+	if x != nil && x.PlayerId != nil {
+		return *x.PlayerId
+	}
+	return 0
+}
+
+func (x *KickRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// KickResponse is the response of Kick.
+type KickResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickResponse) Reset() {
+	*x = KickResponse{}
+	mi := &file_battleye_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickResponse) ProtoMessage() {}
+
+func (x *KickResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickResponse.ProtoReflect.Descriptor instead.
+func (*KickResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{13}
+}
+
+// SayRequest is the request of Say.
+type SayRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Number of the player, or unset to send the message to every player.
+	PlayerId *int32 `protobuf:"varint,2,opt,name=player_id,json=playerId,proto3,oneof" json:"player_id,omitempty"`
+	// Message to send.
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SayRequest) Reset() {
+	*x = SayRequest{}
+	mi := &file_battleye_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SayRequest) ProtoMessage() {}
+
+func (x *SayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SayRequest.ProtoReflect.Descriptor instead.
+func (*SayRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SayRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *SayRequest) GetPlayerId() int32 {
+	if x != nil && x.PlayerId != nil {
+		return *x.PlayerId
+	}
+	return 0
+}
+
+func (x *SayRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// SayResponse is the response of Say.
+type SayResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SayResponse) Reset() {
+	*x = SayResponse{}
+	mi := &file_battleye_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SayResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SayResponse) ProtoMessage() {}
+
+func (x *SayResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SayResponse.ProtoReflect.Descriptor instead.
+func (*SayResponse) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{15}
+}
+
+// StreamEventsRequest is the request of StreamEvents.
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Types of the events to stream, all if empty.
+	Types []string `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	// Regular expression the messages must match, if set.
+	Match         string `protobuf:"bytes,3,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_battleye_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StreamEventsRequest) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *StreamEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetMatch() string {
+	if x != nil {
+		return x.Match
+	}
+	return ""
+}
+
+// Event is a message sent by a server.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the server.
+	Server string `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	// Time the message was received.
+	Time *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Type of the event: chat, player_connected, player_guid, player_verified,
+	// player_disconnected, player_kicked, admin_logged_in or unknown.
+	Type string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	// Raw message.
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_battleye_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_battleye_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_battleye_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Event) GetServer() string {
+	if x != nil {
+		return x.Server
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_battleye_proto protoreflect.FileDescriptor
+
+const file_battleye_proto_rawDesc = "" +
+	"\n" +
+	"\x0ebattleye.proto\x12\vbattleye.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x14\n" +
+	"\x12ListServersRequest\"/\n" +
+	"\x13ListServersResponse\x12\x18\n" +
+	"\aservers\x18\x01 \x03(\tR\aservers\"?\n" +
+	"\vExecRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\"*\n" +
+	"\fExecResponse\x12\x1a\n" +
+	"\bresponse\x18\x01 \x01(\tR\bresponse\",\n" +
+	"\x12ListPlayersRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\"D\n" +
+	"\x13ListPlayersResponse\x12-\n" +
+	"\aplayers\x18\x01 \x03(\v2\x13.battleye.v1.PlayerR\aplayers\"\xaa\x01\n" +
+	"\x06Player\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x12\n" +
+	"\x04ping\x18\x04 \x01(\x05R\x04ping\x12\x12\n" +
+	"\x04guid\x18\x05 \x01(\tR\x04guid\x12\x1a\n" +
+	"\bverified\x18\x06 \x01(\bR\bverified\x12\x12\n" +
+	"\x04name\x18\a \x01(\tR\x04name\x12\x14\n" +
+	"\x05lobby\x18\b \x01(\bR\x05lobby\")\n" +
+	"\x0fListBansRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\"8\n" +
+	"\x10ListBansResponse\x12$\n" +
+	"\x04bans\x18\x01 \x03(\v2\x10.battleye.v1.BanR\x04bans\"t\n" +
+	"\x03Ban\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04guid\x18\x02 \x01(\tR\x04guid\x12\x0e\n" +
+	"\x02ip\x18\x03 \x01(\tR\x02ip\x12!\n" +
+	"\fminutes_left\x18\x04 \x01(\x05R\vminutesLeft\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"\x95\x01\n" +
+	"\n" +
+	"BanRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12\x1d\n" +
+	"\tplayer_id\x18\x02 \x01(\x05H\x00R\bplayerId\x12\x14\n" +
+	"\x04guid\x18\x03 \x01(\tH\x00R\x04guid\x12\x18\n" +
+	"\aminutes\x18\x04 \x01(\x05R\aminutes\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reasonB\b\n" +
+	"\x06target\"\r\n" +
+	"\vBanResponse\"m\n" +
+	"\vKickRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12 \n" +
+	"\tplayer_id\x18\x02 \x01(\x05H\x00R\bplayerId\x88\x01\x01\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reasonB\f\n" +
+	"\n" +
+	"_player_id\"\x0e\n" +
+	"\fKickResponse\"n\n" +
+	"\n" +
+	"SayRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12 \n" +
+	"\tplayer_id\x18\x02 \x01(\x05H\x00R\bplayerId\x88\x01\x01\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessageB\f\n" +
+	"\n" +
+	"_player_id\"\r\n" +
+	"\vSayResponse\"Y\n" +
+	"\x13StreamEventsRequest\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\x12\x14\n" +
+	"\x05match\x18\x03 \x01(\tR\x05match\"}\n" +
+	"\x05Event\x12\x16\n" +
+	"\x06server\x18\x01 \x01(\tR\x06server\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage2\xad\x04\n" +
+	"\bBattlEye\x12P\n" +
+	"\vListServers\x12\x1f.battleye.v1.ListServersRequest\x1a .battleye.v1.ListServersResponse\x12;\n" +
+	"\x04Exec\x12\x18.battleye.v1.ExecRequest\x1a\x19.battleye.v1.ExecResponse\x12P\n" +
+	"\vListPlayers\x12\x1f.battleye.v1.ListPlayersRequest\x1a .battleye.v1.ListPlayersResponse\x12G\n" +
+	"\bListBans\x12\x1c.battleye.v1.ListBansRequest\x1a\x1d.battleye.v1.ListBansResponse\x128\n" +
+	"\x03Ban\x12\x17.battleye.v1.BanRequest\x1a\x18.battleye.v1.BanResponse\x12;\n" +
+	"\x04Kick\x12\x18.battleye.v1.KickRequest\x1a\x19.battleye.v1.KickResponse\x128\n" +
+	"\x03Say\x12\x17.battleye.v1.SayRequest\x1a\x18.battleye.v1.SayResponse\x12F\n" +
+	"\fStreamEvents\x12 .battleye.v1.StreamEventsRequest\x1a\x12.battleye.v1.Event0\x01B&Z$github.com/multiplay/go-battleye/rpcb\x06proto3"
+
+var (
+	file_battleye_proto_rawDescOnce sync.Once
+	file_battleye_proto_rawDescData []byte
+)
+
+func file_battleye_proto_rawDescGZIP() []byte {
+	file_battleye_proto_rawDescOnce.Do(func() {
+		file_battleye_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_battleye_proto_rawDesc), len(file_battleye_proto_rawDesc)))
+	})
+	return file_battleye_proto_rawDescData
+}
+
+var file_battleye_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_battleye_proto_goTypes = []any{
+	(*ListServersRequest)(nil),    // 0: battleye.v1.ListServersRequest
+	(*ListServersResponse)(nil),   // 1: battleye.v1.ListServersResponse
+	(*ExecRequest)(nil),           // 2: battleye.v1.ExecRequest
+	(*ExecResponse)(nil),          // 3: battleye.v1.ExecResponse
+	(*ListPlayersRequest)(nil),    // 4: battleye.v1.ListPlayersRequest
+	(*ListPlayersResponse)(nil),   // 5: battleye.v1.ListPlayersResponse
+	(*Player)(nil),                // 6: battleye.v1.Player
+	(*ListBansRequest)(nil),       // 7: battleye.v1.ListBansRequest
+	(*ListBansResponse)(nil),      // 8: battleye.v1.ListBansResponse
+	(*Ban)(nil),                   // 9: battleye.v1.Ban
+	(*BanRequest)(nil),            // 10: battleye.v1.BanRequest
+	(*BanResponse)(nil),           // 11: battleye.v1.BanResponse
+	(*KickRequest)(nil),           // 12: battleye.v1.KickRequest
+	(*KickResponse)(nil),          // 13: battleye.v1.KickResponse
+	(*SayRequest)(nil),            // 14: battleye.v1.SayRequest
+	(*SayResponse)(nil),           // 15: battleye.v1.SayResponse
+	(*StreamEventsRequest)(nil),   // 16: battleye.v1.StreamEventsRequest
+	(*Event)(nil),                 // 17: battleye.v1.Event
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+}
+var file_battleye_proto_depIdxs = []int32{
+	6,  // 0: battleye.v1.ListPlayersResponse.players:type_name -> battleye.v1.Player
+	9,  // 1: battleye.v1.ListBansResponse.bans:type_name -> battleye.v1.Ban
+	18, // 2: battleye.v1.Event.time:type_name -> google.protobuf.Timestamp
+	0,  // 3: battleye.v1.BattlEye.ListServers:input_type -> battleye.v1.ListServersRequest
+	2,  // 4: battleye.v1.BattlEye.Exec:input_type -> battleye.v1.ExecRequest
+	4,  // 5: battleye.v1.BattlEye.ListPlayers:input_type -> battleye.v1.ListPlayersRequest
+	7,  // 6: battleye.v1.BattlEye.ListBans:input_type -> battleye.v1.ListBansRequest
+	10, // 7: battleye.v1.BattlEye.Ban:input_type -> battleye.v1.BanRequest
+	12, // 8: battleye.v1.BattlEye.Kick:input_type -> battleye.v1.KickRequest
+	14, // 9: battleye.v1.BattlEye.Say:input_type -> battleye.v1.SayRequest
+	16, // 10: battleye.v1.BattlEye.StreamEvents:input_type -> battleye.v1.StreamEventsRequest
+	1,  // 11: battleye.v1.BattlEye.ListServers:output_type -> battleye.v1.ListServersResponse
+	3,  // 12: battleye.v1.BattlEye.Exec:output_type -> battleye.v1.ExecResponse
+	5,  // 13: battleye.v1.BattlEye.ListPlayers:output_type -> battleye.v1.ListPlayersResponse
+	8,  // 14: battleye.v1.BattlEye.ListBans:output_type -> battleye.v1.ListBansResponse
+	11, // 15: battleye.v1.BattlEye.Ban:output_type -> battleye.v1.BanResponse
+	13, // 16: battleye.v1.BattlEye.Kick:output_type -> battleye.v1.KickResponse
+	15, // 17: battleye.v1.BattlEye.Say:output_type -> battleye.v1.SayResponse
+	17, // 18: battleye.v1.BattlEye.StreamEvents:output_type -> battleye.v1.Event
+	11, // [11:19] is the sub-list for method output_type
+	3,  // [3:11] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_battleye_proto_init() }
+func file_battleye_proto_init() {
+	if File_battleye_proto != nil {
+		return
+	}
+	file_battleye_proto_msgTypes[10].OneofWrappers = []any{
+		(*BanRequest_PlayerId)(nil),
+		(*BanRequest_Guid)(nil),
+	}
+	file_battleye_proto_msgTypes[12].OneofWrappers = []any{}
+	file_battleye_proto_msgTypes[14].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_battleye_proto_rawDesc), len(file_battleye_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_battleye_proto_goTypes,
+		DependencyIndexes: file_battleye_proto_depIdxs,
+		MessageInfos:      file_battleye_proto_msgTypes,
+	}.Build()
+	File_battleye_proto = out.File
+	file_battleye_proto_goTypes = nil
+	file_battleye_proto_depIdxs = nil
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/multiplay/go-battleye/internal/errkind"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNilOption is returned by NewService if an Option is nil.
+	ErrNilOption = errors.New("rpc: nil option")
+
+	// ErrNoServers is returned by NewService if there are no servers to serve.
+	ErrNoServers = errors.New("rpc: no servers")
+
+	// ErrInvalidServer is returned by NewService if a server is nil or its name is empty.
+	ErrInvalidServer = errors.New("rpc: invalid server")
+
+	// ErrUnknownServer is returned if a request names a server which is not served.
+	ErrUnknownServer = errors.New("rpc: unknown server")
+
+	// ErrInvalidBufferSize is returned if SubscriberBuffer Option is used with a size less than 1.
+	ErrInvalidBufferSize = errors.New("rpc: invalid buffer size")
+
+	// errNoEvents is returned by StreamEvents if the server has no event source.
+	errNoEvents = errors.New("rpc: no events")
+
+	// errNoBanTarget is returned by Ban if neither a player ID nor a GUID is given.
+	errNoBanTarget = errors.New("rpc: no ban target")
+
+	// errInvalidMatch is returned by StreamEvents if the match expression doesn't compile.
+	errInvalidMatch = errors.New("rpc: invalid match")
+
+	// errSlowSubscriber is returned by StreamEvents if the caller didn't keep up with the events.
+	errSlowSubscriber = errors.New("rpc: slow subscriber")
+
+	// errStreamClosed is returned by StreamEvents if the event source is closed.
+	errStreamClosed = errors.New("rpc: stream closed")
+)
+
+var (
+	// codesByKind are the gRPC status codes of the kinds of Client errors.
+	codesByKind = map[errkind.Kind]codes.Code{
+		errkind.Invalid:       codes.InvalidArgument,
+		errkind.NotFound:      codes.NotFound,
+		errkind.Rejected:      codes.Aborted,
+		errkind.CommandFailed: codes.FailedPrecondition,
+		errkind.BadResponse:   codes.Internal,
+		errkind.LoginFailed:   codes.Unavailable,
+		errkind.Timeout:       codes.DeadlineExceeded,
+		errkind.Canceled:      codes.Canceled,
+		errkind.Closed:        codes.Unavailable,
+	}
+
+	// codesByError are the gRPC status codes of the errors of the service.
+	codesByError = map[error]codes.Code{
+		ErrUnknownServer:  codes.NotFound,
+		errNoEvents:       codes.FailedPrecondition,
+		errNoBanTarget:    codes.InvalidArgument,
+		errInvalidMatch:   codes.InvalidArgument,
+		errSlowSubscriber: codes.ResourceExhausted,
+		errStreamClosed:   codes.Unavailable,
+	}
+)
+
+// Code returns the gRPC status code for err, which defaults to Unknown.
+// Commands the server reports as failed are FailedPrecondition.
+func Code(err error) codes.Code {
+	for e, c := range codesByError {
+		if errors.Is(err, e) {
+			return c
+		}
+	}
+	if k, _ := errkind.Of(err); k != errkind.Unknown {
+		return codesByKind[k]
+	}
+	return codes.Unknown
+}
+
+// toStatus returns err as a gRPC status error.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	return status.Error(Code(err), err.Error())
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"regexp"
+	"sync"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/multiplay/go-battleye/internal/fanout"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultSubscriberBuffer is the default number of events buffered for each subscriber.
+	defaultSubscriberBuffer = 100
+)
+
+// filter selects the events sent to a subscriber.
+type filter struct {
+	types map[string]bool
+	match *regexp.Regexp
+}
+
+// newFilter returns the filter of req.
+func newFilter(req *StreamEventsRequest) (*filter, error) {
+	f := &filter{}
+	if types := req.GetTypes(); len(types) > 0 {
+		f.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			f.types[t] = true
+		}
+	}
+	if m := req.GetMatch(); m != "" {
+		var err error
+		if f.match, err = regexp.Compile(m); err != nil {
+			return nil, errInvalidMatch
+		}
+	}
+	return f, nil
+}
+
+// accept returns true if e passes the filter.
+func (f *filter) accept(e *Event) bool {
+	if f.types != nil && !f.types[e.Type] {
+		return false
+	}
+	return f.match == nil || f.match.MatchString(e.Message)
+}
+
+// hub fans out the messages of a server to the StreamEvents calls.
+type hub struct {
+	*fanout.Hub[*Event]
+	name    string
+	src     battleye.Source
+	handler battleye.HandlerID
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// newHub returns a hub of the messages of src, the server called name, which is closed when
+// src is done.
+func newHub(name string, src battleye.Source, bufSize int) *hub {
+	h := &hub{Hub: fanout.New[*Event](bufSize), name: name, src: src, done: make(chan struct{})}
+	h.handler = src.OnMessage(h.handle)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		select {
+		case <-src.Done():
+		case <-h.done:
+		}
+		h.Hub.Close()
+	}()
+	return h
+}
+
+// Close unregisters h from its source and ends the StreamEvents calls.
+func (h *hub) Close() {
+	h.closeOnce.Do(func() {
+		h.src.Unregister(h.handler)
+		close(h.done)
+	})
+	h.wg.Wait()
+}
+
+// handle publishes the server message m.
+func (h *hub) handle(m battleye.Message) {
+	h.Publish(&Event{
+		Server:  h.name,
+		Time:    timestamppb.New(m.Time),
+		Type:    event.TypeOf(m.Event),
+		Message: m.Event.Raw(),
+	})
+}
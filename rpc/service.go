@@ -0,0 +1,330 @@
+// Package rpc exposes BattlEye RCON Clients as a gRPC service, so that services in any language
+// can administer servers through a single internal endpoint.
+//
+// The BattlEye service is defined in battleye.proto, from which the message types, the
+// BattlEyeClient and the BattlEyeServer interface are generated. Service implements
+// BattlEyeServer with long-lived Clients by name:
+//
+//	s, err := rpc.NewService(map[string]rpc.Server{"prod1": c}, rpc.Events("prod1", c))
+//	if err != nil {
+//		// Handle error.
+//	}
+//	defer s.Close()
+//	g := grpc.NewServer()
+//	rpc.RegisterBattlEyeServer(g, s)
+//
+// Errors are returned as gRPC statuses with a code derived from the error, see Code. The
+// service doesn't authenticate callers, which is left to interceptors or transport credentials.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative battleye.proto
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	battleye "github.com/multiplay/go-battleye"
+)
+
+// Server is the interface of the Clients wrapped by a Service, which *battleye.Client implements.
+type Server interface {
+	ExecContext(ctx context.Context, cmd string) (string, error)
+	PlayersContext(ctx context.Context) ([]battleye.Player, error)
+	BansContext(ctx context.Context) ([]battleye.Ban, error)
+	BanContext(ctx context.Context, playerID, minutes int, reason string) error
+	AddBanContext(ctx context.Context, guid string, minutes int, reason string) error
+	KickContext(ctx context.Context, playerID int, reason string) error
+	BroadcastContext(ctx context.Context, msg string) error
+	WhisperContext(ctx context.Context, playerID int, msg string) error
+}
+
+var _ Server = (*battleye.Client)(nil)
+
+// Service implements BattlEyeServer with Servers by name.
+type Service struct {
+	UnimplementedBattlEyeServer
+
+	servers map[string]Server
+	sources map[string]battleye.Source
+	hubs    map[string]*hub
+	bufSize int
+}
+
+var _ BattlEyeServer = (*Service)(nil)
+
+// Option is a Service configuration Option type.
+type Option func(s *Service) error
+
+// Events streams the messages of src, usually the Client, as the events of the server called
+// name. The Service subscribes to src, which can be shared with other consumers, until it's done.
+func Events(name string, src battleye.Source) Option {
+	return func(s *Service) error {
+		if _, ok := s.servers[name]; !ok || src == nil {
+			return ErrUnknownServer
+		}
+		s.sources[name] = src
+		return nil
+	}
+}
+
+// SubscriberBuffer sets the number of events buffered for each StreamEvents call. A call which
+// falls further behind is ended with ResourceExhausted.
+func SubscriberBuffer(size int) Option {
+	return func(s *Service) error {
+		if size < 1 {
+			return ErrInvalidBufferSize
+		}
+		s.bufSize = size
+		return nil
+	}
+}
+
+// NewService returns a Service serving servers by name.
+func NewService(servers map[string]Server, options ...Option) (*Service, error) {
+	if len(servers) == 0 {
+		return nil, ErrNoServers
+	}
+
+	s := &Service{
+		servers: make(map[string]Server, len(servers)),
+		sources: make(map[string]battleye.Source),
+		hubs:    make(map[string]*hub),
+		bufSize: defaultSubscriberBuffer,
+	}
+	for name, srv := range servers {
+		if name == "" || srv == nil {
+			return nil, ErrInvalidServer
+		}
+		s.servers[name] = srv
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, src := range s.sources {
+		s.hubs[name] = newHub(name, src, s.bufSize)
+	}
+
+	return s, nil
+}
+
+// Close unsubscribes s from the event sources and ends the StreamEvents calls. The Servers are
+// not closed.
+func (s *Service) Close() {
+	for _, h := range s.hubs {
+		h.Close()
+	}
+}
+
+// server returns the server called name.
+func (s *Service) server(name string) (Server, error) {
+	srv, ok := s.servers[name]
+	if !ok {
+		return nil, toStatus(ErrUnknownServer)
+	}
+	return srv, nil
+}
+
+// ListServers implements BattlEyeServer.
+func (s *Service) ListServers(ctx context.Context, req *ListServersRequest) (*ListServersResponse, error) {
+	names := make([]string, 0, len(s.servers))
+	for name := range s.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &ListServersResponse{Servers: names}, nil
+}
+
+// Exec implements BattlEyeServer.
+func (s *Service) Exec(ctx context.Context, req *ExecRequest) (*ExecResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+	cmd := req.GetCommand()
+	if strings.TrimSpace(cmd) == "" || strings.IndexFunc(cmd, unicode.IsControl) != -1 {
+		return nil, toStatus(battleye.ErrInvalidCommand)
+	}
+
+	resp, err := srv.ExecContext(ctx, cmd)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &ExecResponse{Response: resp}, nil
+}
+
+// ListPlayers implements BattlEyeServer.
+func (s *Service) ListPlayers(ctx context.Context, req *ListPlayersRequest) (*ListPlayersResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := srv.PlayersContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &ListPlayersResponse{Players: make([]*Player, 0, len(players))}
+	for _, p := range players {
+		resp.Players = append(resp.Players, newPlayer(p))
+	}
+	return resp, nil
+}
+
+// ListBans implements BattlEyeServer.
+func (s *Service) ListBans(ctx context.Context, req *ListBansRequest) (*ListBansResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+
+	bans, err := srv.BansContext(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &ListBansResponse{Bans: make([]*Ban, 0, len(bans))}
+	for _, b := range bans {
+		resp.Bans = append(resp.Bans, newBan(b))
+	}
+	return resp, nil
+}
+
+// Ban implements BattlEyeServer.
+func (s *Service) Ban(ctx context.Context, req *BanRequest) (*BanResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+
+	minutes := int(req.GetMinutes())
+	switch t := req.GetTarget().(type) {
+	case *BanRequest_PlayerId:
+		err = srv.BanContext(ctx, int(t.PlayerId), minutes, req.GetReason())
+	case *BanRequest_Guid:
+		err = srv.AddBanContext(ctx, t.Guid, minutes, req.GetReason())
+	default:
+		err = errNoBanTarget
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &BanResponse{}, nil
+}
+
+// Kick implements BattlEyeServer.
+func (s *Service) Kick(ctx context.Context, req *KickRequest) (*KickResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PlayerId == nil {
+		return nil, toStatus(battleye.ErrInvalidPlayerID)
+	}
+	if err := srv.KickContext(ctx, int(req.GetPlayerId()), req.GetReason()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &KickResponse{}, nil
+}
+
+// Say implements BattlEyeServer.
+func (s *Service) Say(ctx context.Context, req *SayRequest) (*SayResponse, error) {
+	srv, err := s.server(req.GetServer())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PlayerId == nil {
+		err = srv.BroadcastContext(ctx, req.GetMessage())
+	} else {
+		err = srv.WhisperContext(ctx, int(req.GetPlayerId()), req.GetMessage())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &SayResponse{}, nil
+}
+
+// StreamEvents implements BattlEyeServer.
+// The response headers are sent once subscribed, so callers can wait for them to not miss
+// events caused by their own commands.
+func (s *Service) StreamEvents(req *StreamEventsRequest, stream BattlEye_StreamEventsServer) error {
+	if _, err := s.server(req.GetServer()); err != nil {
+		return err
+	}
+	h, ok := s.hubs[req.GetServer()]
+	if !ok {
+		return toStatus(errNoEvents)
+	}
+	filter, err := newFilter(req)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	sub := h.Subscribe(filter.accept)
+	defer h.Unsubscribe(sub)
+
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return toStatus(ctx.Err())
+		case e, ok := <-sub.C():
+			switch {
+			case ok:
+				if err := stream.Send(e); err != nil {
+					return err
+				}
+			case sub.Slow():
+				return toStatus(errSlowSubscriber)
+			default:
+				return toStatus(errStreamClosed)
+			}
+		}
+	}
+}
+
+// newPlayer returns p as a Player message.
+func newPlayer(p battleye.Player) *Player {
+	var ip string
+	if p.IP != nil {
+		ip = p.IP.String()
+	}
+	return &Player{
+		Id:       int32(p.ID),
+		Ip:       ip,
+		Port:     int32(p.Port),
+		Ping:     int32(p.Ping),
+		Guid:     p.GUID,
+		Verified: p.Verified,
+		Name:     p.Name,
+		Lobby:    p.Lobby,
+	}
+}
+
+// newBan returns b as a Ban message.
+func newBan(b battleye.Ban) *Ban {
+	var ip string
+	if b.IP != nil {
+		ip = b.IP.String()
+	}
+	return &Ban{
+		Id:          int32(b.ID),
+		Guid:        b.GUID,
+		Ip:          ip,
+		MinutesLeft: int32(b.MinutesLeft),
+		Reason:      b.Reason,
+	}
+}
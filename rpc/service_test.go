@@ -0,0 +1,458 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const testTimeout = 2 * time.Second
+
+// fakeServer is a Server recording the calls made to it.
+type fakeServer struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (s *fakeServer) call(call string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+	return s.err
+}
+
+func (s *fakeServer) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// fakeSource is a battleye.Source fed by the test.
+type fakeSource struct {
+	done chan struct{}
+
+	mu       sync.Mutex
+	handlers map[battleye.HandlerID]func(battleye.Message)
+	nextID   battleye.HandlerID
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{done: make(chan struct{}), handlers: make(map[battleye.HandlerID]func(battleye.Message))}
+}
+
+func (s *fakeSource) OnMessage(f func(battleye.Message)) battleye.HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handlers[s.nextID] = f
+	return s.nextID
+}
+
+func (s *fakeSource) Unregister(id battleye.HandlerID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.handlers[id]
+	delete(s.handlers, id)
+	return ok
+}
+
+func (s *fakeSource) Done() <-chan struct{} {
+	return s.done
+}
+
+// registered returns the number of handlers registered with s.
+func (s *fakeSource) registered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.handlers)
+}
+
+// send calls the registered handlers with msg.
+func (s *fakeSource) send(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := battleye.Message{Time: time.Now(), Event: event.Parse(msg)}
+	for _, f := range s.handlers {
+		f(m)
+	}
+}
+
+func (s *fakeServer) ExecContext(ctx context.Context, cmd string) (string, error) {
+	if err := s.call("exec " + cmd); err != nil {
+		return "", err
+	}
+	return "Response to: " + cmd, nil
+}
+
+func (s *fakeServer) PlayersContext(ctx context.Context) ([]battleye.Player, error) {
+	if err := s.call("players"); err != nil {
+		return nil, err
+	}
+	return []battleye.Player{{ID: 3, IP: net.ParseIP("10.0.0.2"), Port: 2304, Ping: 42, Name: "Kerry"}}, nil
+}
+
+func (s *fakeServer) BansContext(ctx context.Context) ([]battleye.Ban, error) {
+	if err := s.call("bans"); err != nil {
+		return nil, err
+	}
+	return []battleye.Ban{
+		{ID: 0, GUID: "d41d8cd98f00b204e9800998ecf8427e", MinutesLeft: battleye.PermanentBan, Reason: "Cheating"},
+		{ID: 1, IP: net.ParseIP("10.0.0.3"), MinutesLeft: 30},
+	}, nil
+}
+
+func (s *fakeServer) BanContext(ctx context.Context, playerID, minutes int, reason string) error {
+	return s.call("ban " + strconv.Itoa(playerID) + " " + strconv.Itoa(minutes) + " " + reason)
+}
+
+func (s *fakeServer) AddBanContext(ctx context.Context, guid string, minutes int, reason string) error {
+	return s.call("addBan " + guid + " " + strconv.Itoa(minutes) + " " + reason)
+}
+
+func (s *fakeServer) KickContext(ctx context.Context, playerID int, reason string) error {
+	return s.call("kick " + strconv.Itoa(playerID) + " " + reason)
+}
+
+func (s *fakeServer) BroadcastContext(ctx context.Context, msg string) error {
+	return s.call("broadcast " + msg)
+}
+
+func (s *fakeServer) WhisperContext(ctx context.Context, playerID int, msg string) error {
+	return s.call("whisper " + strconv.Itoa(playerID) + " " + msg)
+}
+
+// newTestClient serves a Service of servers over an in-process listener and returns a client
+// connected to it.
+func newTestClient(t *testing.T, servers map[string]Server, opts ...Option) BattlEyeClient {
+	s, err := NewService(servers, opts...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(s.Close)
+
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	RegisterBattlEyeServer(g, s)
+	go g.Serve(lis) // nolint: errcheck
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() }) // nolint: errcheck
+	return NewBattlEyeClient(conn)
+}
+
+func TestNewService(t *testing.T) {
+	t.Parallel()
+
+	srv := &fakeServer{}
+	testcases := []struct {
+		name    string
+		servers map[string]Server
+		opts    []Option
+		expErr  error
+	}{
+		{name: "No servers", expErr: ErrNoServers},
+		{name: "Empty name", servers: map[string]Server{"": srv}, expErr: ErrInvalidServer},
+		{name: "Nil server", servers: map[string]Server{"prod1": nil}, expErr: ErrInvalidServer},
+		{name: "Nil option", servers: map[string]Server{"prod1": srv}, opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Unknown events server", servers: map[string]Server{"prod1": srv}, opts: []Option{Events("prod2", newFakeSource())}, expErr: ErrUnknownServer},
+		{name: "Nil events", servers: map[string]Server{"prod1": srv}, opts: []Option{Events("prod1", nil)}, expErr: ErrUnknownServer},
+		{name: "Invalid buffer", servers: map[string]Server{"prod1": srv}, opts: []Option{SubscriberBuffer(0)}, expErr: ErrInvalidBufferSize},
+		{name: "Valid", servers: map[string]Server{"prod1": srv}, opts: []Option{SubscriberBuffer(10)}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewService(tc.servers, tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				assert.Nil(t, s)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestServiceClose(t *testing.T) {
+	t.Parallel()
+
+	src := newFakeSource()
+	s, err := NewService(map[string]Server{"prod1": &fakeServer{}}, Events("prod1", src))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, src.registered())
+	sub := s.hubs["prod1"].Subscribe(nil)
+
+	s.Close()
+	s.Close()
+	assert.Zero(t, src.registered())
+	_, ok := <-sub.C()
+	assert.False(t, ok)
+}
+
+func TestService(t *testing.T) {
+	srv := &fakeServer{}
+	failing := &fakeServer{err: battleye.ErrTimeout}
+	c := newTestClient(t, map[string]Server{"prod1": srv, "prod2": failing})
+	ctx := context.Background()
+
+	servers, err := c.ListServers(ctx, &ListServersRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"prod1", "prod2"}, servers.GetServers())
+	}
+
+	testcases := []struct {
+		name     string
+		call     func() (proto.Message, error)
+		expResp  proto.Message
+		expCode  codes.Code
+		expCalls []string
+	}{
+		{
+			name:     "Exec",
+			call:     func() (proto.Message, error) { return c.Exec(ctx, &ExecRequest{Server: "prod1", Command: "missions"}) },
+			expResp:  &ExecResponse{Response: "Response to: missions"},
+			expCalls: []string{"exec missions"},
+		},
+		{
+			name: "Exec invalid command",
+			call: func() (proto.Message, error) {
+				return c.Exec(ctx, &ExecRequest{Server: "prod1", Command: "say -1 a\nb"})
+			},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Unknown server",
+			call:    func() (proto.Message, error) { return c.Exec(ctx, &ExecRequest{Server: "prod3", Command: "missions"}) },
+			expCode: codes.NotFound,
+		},
+		{
+			name:     "Server error",
+			call:     func() (proto.Message, error) { return c.ListPlayers(ctx, &ListPlayersRequest{Server: "prod2"}) },
+			expCode:  codes.DeadlineExceeded,
+			expCalls: []string{"players"},
+		},
+		{
+			name: "ListPlayers",
+			call: func() (proto.Message, error) { return c.ListPlayers(ctx, &ListPlayersRequest{Server: "prod1"}) },
+			expResp: &ListPlayersResponse{Players: []*Player{
+				{Id: 3, Ip: "10.0.0.2", Port: 2304, Ping: 42, Name: "Kerry"},
+			}},
+			expCalls: []string{"players"},
+		},
+		{
+			name: "ListBans",
+			call: func() (proto.Message, error) { return c.ListBans(ctx, &ListBansRequest{Server: "prod1"}) },
+			expResp: &ListBansResponse{Bans: []*Ban{
+				{Id: 0, Guid: "d41d8cd98f00b204e9800998ecf8427e", MinutesLeft: -1, Reason: "Cheating"},
+				{Id: 1, Ip: "10.0.0.3", MinutesLeft: 30},
+			}},
+			expCalls: []string{"bans"},
+		},
+		{
+			name: "Ban player",
+			call: func() (proto.Message, error) {
+				return c.Ban(ctx, &BanRequest{Server: "prod1", Target: &BanRequest_PlayerId{PlayerId: 3}, Minutes: 60, Reason: "Spam"})
+			},
+			expResp:  &BanResponse{},
+			expCalls: []string{"ban 3 60 Spam"},
+		},
+		{
+			name: "Ban GUID",
+			call: func() (proto.Message, error) {
+				return c.Ban(ctx, &BanRequest{Server: "prod1", Target: &BanRequest_Guid{Guid: "abc"}, Minutes: -1})
+			},
+			expResp:  &BanResponse{},
+			expCalls: []string{"addBan abc -1 "},
+		},
+		{
+			name:    "Ban without target",
+			call:    func() (proto.Message, error) { return c.Ban(ctx, &BanRequest{Server: "prod1", Minutes: 60}) },
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name: "Kick",
+			call: func() (proto.Message, error) {
+				return c.Kick(ctx, &KickRequest{Server: "prod1", PlayerId: proto.Int32(3), Reason: "AFK"})
+			},
+			expResp:  &KickResponse{},
+			expCalls: []string{"kick 3 AFK"},
+		},
+		{
+			name:    "Kick without player",
+			call:    func() (proto.Message, error) { return c.Kick(ctx, &KickRequest{Server: "prod1", Reason: "AFK"}) },
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Say broadcast",
+			call:     func() (proto.Message, error) { return c.Say(ctx, &SayRequest{Server: "prod1", Message: "Restart"}) },
+			expResp:  &SayResponse{},
+			expCalls: []string{"broadcast Restart"},
+		},
+		{
+			name: "Say whisper",
+			call: func() (proto.Message, error) {
+				return c.Say(ctx, &SayRequest{Server: "prod1", PlayerId: proto.Int32(0), Message: "Hi"})
+			},
+			expResp:  &SayResponse{},
+			expCalls: []string{"whisper 0 Hi"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv.mu.Lock()
+			srv.calls = nil
+			srv.mu.Unlock()
+			failing.mu.Lock()
+			failing.calls = nil
+			failing.mu.Unlock()
+
+			resp, err := tc.call()
+			if tc.expCode != codes.OK {
+				assert.Equal(t, tc.expCode, status.Code(err), "%v", err)
+			} else if assert.NoError(t, err) {
+				assert.True(t, proto.Equal(tc.expResp, resp), "%v", resp)
+			}
+			assert.Equal(t, tc.expCalls, append(srv.Calls(), failing.Calls()...))
+		})
+	}
+}
+
+func TestServiceStreamEvents(t *testing.T) {
+	src := newFakeSource()
+	c := newTestClient(t, map[string]Server{"prod1": &fakeServer{}, "prod2": &fakeServer{}},
+		Events("prod1", src))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	recvErr := func(req *StreamEventsRequest) error {
+		stream, err := c.StreamEvents(ctx, req)
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+	assert.Equal(t, codes.NotFound, status.Code(recvErr(&StreamEventsRequest{Server: "prod3"})))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(recvErr(&StreamEventsRequest{Server: "prod2"})))
+	assert.Equal(t, codes.InvalidArgument, status.Code(recvErr(&StreamEventsRequest{Server: "prod1", Match: "("})))
+
+	subscribe := func(req *StreamEventsRequest) BattlEye_StreamEventsClient {
+		stream, err := c.StreamEvents(ctx, req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		// Headers are sent once subscribed.
+		_, err = stream.Header()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return stream
+	}
+	all := subscribe(&StreamEventsRequest{Server: "prod1"})
+	chat := subscribe(&StreamEventsRequest{Server: "prod1", Types: []string{"chat"}, Match: "Kerry"})
+
+	src.send("Player #1 Kerry (10.0.0.2:2304) connected")
+	src.send("(Global) Miller: hi")
+	src.send("(Global) Kerry: hello")
+
+	e, err := chat.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod1", e.GetServer())
+		assert.Equal(t, "chat", e.GetType())
+		assert.Equal(t, "(Global) Kerry: hello", e.GetMessage())
+		assert.WithinDuration(t, time.Now(), e.GetTime().AsTime(), testTimeout)
+	}
+
+	var types []string
+	for i := 0; i < 3; i++ {
+		if e, err = all.Recv(); assert.NoError(t, err) {
+			types = append(types, e.GetType())
+		}
+	}
+	assert.Equal(t, []string{"player_connected", "chat", "chat"}, types)
+
+	close(src.done)
+	_, err = chat.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err), "%v", err)
+	assert.Equal(t, codes.Unavailable, status.Code(recvErr(&StreamEventsRequest{Server: "prod1"})))
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	src := newFakeSource()
+	h := newHub("prod1", src, 1)
+
+	slow := h.Subscribe((&filter{}).accept)
+	chat := h.Subscribe((&filter{types: map[string]bool{"chat": true}}).accept)
+	src.send("Player #1 Kerry (10.0.0.2:2304) connected")
+	src.send("Player #1 Kerry disconnected")
+	src.send("(Global) Kerry: hello")
+	close(src.done)
+
+	// The second message overflowed the buffer of slow, which was dropped.
+	assert.Equal(t, "player_connected", (<-slow.C()).GetType())
+	_, ok := <-slow.C()
+	assert.False(t, ok)
+	assert.True(t, slow.Slow())
+
+	assert.Equal(t, "chat", (<-chat.C()).GetType())
+	_, ok = <-chat.C()
+	assert.False(t, ok)
+	assert.False(t, chat.Slow())
+	_, ok = <-h.Subscribe((&filter{}).accept).C()
+	assert.False(t, ok)
+}
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		err     error
+		expCode codes.Code
+	}{
+		{battleye.ErrInvalidPlayerID, codes.InvalidArgument},
+		{battleye.ErrPlayerNotFound, codes.NotFound},
+		{battleye.ErrKickFailed, codes.Aborted},
+		{battleye.ErrTimeout, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{net.ErrClosed, codes.Unavailable},
+		{&battleye.CommandError{Cmd: "kick", Msg: "Invalid player"}, codes.FailedPrecondition},
+		{errors.New("other"), codes.Unknown},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.expCode, Code(tc.err))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package battleye
+
+import (
+	"net"
+	"sort"
+)
+
+// Sessions returns the RCON sessions known to be connected to the server ordered by admin number.
+// Sessions are added when the server reports an admin login and are replaced by the result of
+// Admins, which also drops the sessions which have disconnected since, as the server does not
+// report logouts.
+func (c *Client) Sessions() []Admin {
+	c.sessionsLock.Lock()
+	admins := make([]Admin, 0, len(c.sessions))
+	for _, a := range c.sessions {
+		admins = append(admins, a)
+	}
+	c.sessionsLock.Unlock()
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].ID < admins[j].ID })
+	c.markSelf(admins)
+
+	return admins
+}
+
+// trackSession adds a to the known sessions.
+func (c *Client) trackSession(a Admin) {
+	c.sessionsLock.Lock()
+	defer c.sessionsLock.Unlock()
+
+	c.addSession(a)
+}
+
+// resetSessions replaces the known sessions with admins.
+func (c *Client) resetSessions(admins []Admin) {
+	c.sessionsLock.Lock()
+	defer c.sessionsLock.Unlock()
+
+	old := c.sessions
+	c.sessions = make(map[int]Admin, len(admins))
+	for _, a := range admins {
+		if o, ok := old[a.ID]; ok && sameSession(o, a) {
+			c.sessions[a.ID] = o
+			continue
+		}
+		c.addSession(a)
+	}
+}
+
+// addSession adds a to the known sessions and alerts if it is a new untrusted session.
+// sessionsLock must be held.
+func (c *Client) addSession(a Admin) {
+	a.Self = false
+	if o, ok := c.sessions[a.ID]; ok && sameSession(o, a) {
+		return
+	}
+	c.sessions[a.ID] = a
+
+	if c.onUntrustedAdmin != nil && !c.trusted(a.IP) {
+		go c.onUntrustedAdmin(a)
+	}
+}
+
+// trusted returns true if ip is in one of the trusted networks or if none are set.
+func (c *Client) trusted(ip net.IP) bool {
+	if len(c.trustedNets) == 0 {
+		return true
+	}
+	for _, n := range c.trustedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSession returns true if a and b are the same RCON session.
+func sameSession(a, b Admin) bool {
+	return a.ID == b.ID && a.Port == b.Port && a.IP.Equal(b.IP)
+}
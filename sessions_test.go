@@ -0,0 +1,88 @@
+package battleye
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedAdminNetworks(t *testing.T) {
+	t.Parallel()
+
+	c := &Client{}
+	if !assert.NoError(t, TrustedAdminNetworks("10.0.0.0/8", "192.168.1.10", "::1")(c)) {
+		return
+	}
+	assert.True(t, c.trusted(net.ParseIP("10.1.2.3")))
+	assert.True(t, c.trusted(net.ParseIP("192.168.1.10")))
+	assert.True(t, c.trusted(net.ParseIP("::1")))
+	assert.False(t, c.trusted(net.ParseIP("192.168.1.11")))
+
+	assert.Equal(t, ErrInvalidNetwork, TrustedAdminNetworks("not a network")(c))
+	assert.True(t, (&Client{}).trusted(net.ParseIP("1.2.3.4")))
+}
+
+func TestClientSessions(t *testing.T) {
+	s := newServer(t, testPassword)
+	if s == nil {
+		return
+	}
+	s.Start()
+	defer s.Close()
+
+	alerts := make(chan Admin, 10)
+	c, err := NewClient(s.Addr, testPassword, Timeout(testTimeout),
+		TrustedAdminNetworks("127.0.0.1", "10.0.0.0/8"),
+		UntrustedAdminHandler(func(a Admin) { alerts <- a }),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, c.Close())
+	}()
+
+	s.SendServerMessage("RCon admin #1 (10.0.0.2:2306) logged in")
+	s.SendServerMessage("RCon admin #2 (203.0.113.7:2306) logged in")
+	s.SendServerMessage("RCon admin #2 (203.0.113.7:2306) logged in")
+
+	select {
+	case a := <-alerts:
+		assert.Equal(t, 2, a.ID)
+		assert.Equal(t, "203.0.113.7:2306", a.Addr())
+	case <-time.After(time.Second):
+		assert.Fail(t, "no alert for untrusted admin")
+		return
+	}
+
+	assert.Eventually(t, func() bool { return len(c.Sessions()) == 2 }, time.Second, 10*time.Millisecond)
+
+	// Admins replaces the known sessions, dropping the ones which disconnected.
+	s.SetResponse("admins", fmt.Sprintf("Connected RCon admins:\n0 %v\n2 203.0.113.7:2306\n3 198.51.100.1:2306", c.conn.LocalAddr()))
+	if _, err := c.Admins(); !assert.NoError(t, err) {
+		return
+	}
+
+	sessions := c.Sessions()
+	if !assert.Len(t, sessions, 3) {
+		return
+	}
+	assert.True(t, sessions[0].Self)
+	assert.Equal(t, 2, sessions[1].ID)
+	assert.Equal(t, 3, sessions[2].ID)
+
+	select {
+	case a := <-alerts:
+		assert.Equal(t, 3, a.ID)
+	case <-time.After(time.Second):
+		assert.Fail(t, "no alert for untrusted admin")
+	}
+	select {
+	case a := <-alerts:
+		assert.Fail(t, fmt.Sprintf("unexpected alert for admin #%v", a.ID))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
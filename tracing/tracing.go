@@ -0,0 +1,239 @@
+// Package tracing traces the commands of BattlEye RCON Clients with OpenTelemetry.
+//
+// A Tracer creates a span for each command executed by a Client, including those of the typed
+// helpers such as PlayersContext, as a child of the span in the context the command is executed
+// with, and emits the server messages as log records:
+//
+//	t, err := tracing.NewTracer(tracing.TracerProvider(tp), tracing.Server("prod1"))
+//	if err != nil {
+//		// Handle error.
+//	}
+//
+//	c, err := battleye.NewClient(addr, pwd, battleye.AddHooks(t.Hooks()))
+//	if err != nil {
+//		// Handle error.
+//	}
+//	players, err := c.PlayersContext(ctx)
+//
+// Spans are named after the verb of the command, e.g. "battleye players", or "battleye other" for
+// commands which are not known, so that their number is bounded. The full command is recorded in
+// the battleye.command attribute. The empty commands sent to keep the connection alive are not
+// traced.
+//
+// Log records are emitted with the API of go.opentelemetry.io/otel/log v0.22.0 or later.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/multiplay/go-battleye/internal/verb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName is the name of the tracer and logger of a Tracer.
+	instrumentationName = "github.com/multiplay/go-battleye/tracing"
+
+	// system is the value of the rpc.system attribute of the spans.
+	system = "battleye"
+)
+
+// Attribute keys of the spans and log records.
+const (
+	// CommandKey is the command as executed, e.g. kick 3 Team killing.
+	CommandKey = attribute.Key("battleye.command")
+
+	// VerbKey is the first word of the command, e.g. players.
+	VerbKey = attribute.Key("battleye.command.verb")
+
+	// SeqKey is the sequence number the command was last sent with.
+	SeqKey = attribute.Key("battleye.command.seq")
+
+	// AttemptsKey is the number of times the command was sent.
+	AttemptsKey = attribute.Key("battleye.command.attempts")
+
+	// FragmentsKey is the number of packets the response was received in.
+	FragmentsKey = attribute.Key("battleye.command.fragments")
+
+	// OutcomeKey is the outcome of the command, see Outcome.
+	OutcomeKey = attribute.Key("battleye.command.outcome")
+
+	// ServerKey is the name of the server, see Server.
+	ServerKey = attribute.Key("battleye.server")
+
+	// EventTypeKey is the type of a server message, as returned by event.TypeOf.
+	EventTypeKey = attribute.Key("battleye.event.type")
+)
+
+var (
+	// ErrNilOption is returned by NewTracer if an Option is nil.
+	ErrNilOption = errors.New("tracing: nil option")
+
+	// ErrNilProvider is returned if TracerProvider or LoggerProvider Option is used with a nil
+	// provider.
+	ErrNilProvider = errors.New("tracing: nil provider")
+)
+
+// spanKey is the context key of the span of a command.
+type spanKey struct{}
+
+// Tracer traces the commands and logs the server messages of a server.
+type Tracer struct {
+	tp     trace.TracerProvider
+	lp     log.LoggerProvider
+	server string
+
+	tracer trace.Tracer
+	logger log.Logger
+}
+
+// Option is a Tracer configuration Option type.
+type Option func(t *Tracer) error
+
+// TracerProvider sets the provider of the tracer creating the spans of the commands, instead of
+// the global TracerProvider.
+func TracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) error {
+		if tp == nil {
+			return ErrNilProvider
+		}
+		t.tp = tp
+		return nil
+	}
+}
+
+// LoggerProvider sets the provider of the logger emitting the server messages, instead of the
+// global LoggerProvider.
+func LoggerProvider(lp log.LoggerProvider) Option {
+	return func(t *Tracer) error {
+		if lp == nil {
+			return ErrNilProvider
+		}
+		t.lp = lp
+		return nil
+	}
+}
+
+// Server sets the name of the server, which is added to the spans and log records, so that
+// the Clients of several servers can be told apart.
+func Server(name string) Option {
+	return func(t *Tracer) error {
+		t.server = name
+		return nil
+	}
+}
+
+// NewTracer returns a new Tracer.
+func NewTracer(options ...Option) (*Tracer, error) {
+	t := &Tracer{}
+	for _, opt := range options {
+		if opt == nil {
+			return nil, ErrNilOption
+		}
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.tp == nil {
+		t.tp = otel.GetTracerProvider()
+	}
+	if t.lp == nil {
+		t.lp = global.GetLoggerProvider()
+	}
+	t.tracer = t.tp.Tracer(instrumentationName)
+	t.logger = t.lp.Logger(instrumentationName)
+
+	return t, nil
+}
+
+// Hooks returns the Hooks tracing the commands and logging the server messages, to be added to
+// the Clients of the server with battleye.AddHooks.
+func (t *Tracer) Hooks() *battleye.Hooks {
+	return &battleye.Hooks{
+		CommandStart:  t.commandStart,
+		CommandDone:   t.commandDone,
+		ServerMessage: t.serverMessage,
+	}
+}
+
+// Outcome returns the outcome of a command which returned err: ok, timeout, canceled or error.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, battleye.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// commandStart starts the span of cmd.
+func (t *Tracer) commandStart(ctx context.Context, cmd string) context.Context {
+	v := battleye.CommandInfo{Command: cmd}.Verb()
+	if v == "" {
+		return ctx
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("rpc.system", system), CommandKey.String(cmd), VerbKey.String(v)}
+	if t.server != "" {
+		attrs = append(attrs, ServerKey.String(t.server))
+	}
+	ctx, span := t.tracer.Start(ctx, "battleye "+verb.Bounded(v),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// commandDone ends the span of the command described by info.
+func (t *Tracer) commandDone(info battleye.CommandInfo) {
+	if info.Context == nil || info.Verb() == "" {
+		return
+	}
+	span, ok := info.Context.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(
+		SeqKey.Int(int(info.Seq)),
+		AttemptsKey.Int(info.Attempts),
+		FragmentsKey.Int(info.Fragments),
+		OutcomeKey.String(Outcome(info.Err)),
+	)
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+	}
+	span.End()
+}
+
+// serverMessage emits m as a log record.
+func (t *Tracer) serverMessage(m battleye.Message) {
+	ctx := context.Background()
+	if !t.logger.Enabled(ctx, log.EnabledParameters{Severity: log.SeverityInfo}) {
+		return
+	}
+
+	var r log.Record
+	r.SetTimestamp(m.Time)
+	r.SetSeverity(log.SeverityInfo)
+	r.SetBody(attribute.StringValue(m.Event.Raw()))
+	r.AddAttributes(EventTypeKey.String(event.TypeOf(m.Event)))
+	if t.server != "" {
+		r.AddAttributes(ServerKey.String(t.server))
+	}
+	t.logger.Emit(ctx, r)
+}
@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	battleye "github.com/multiplay/go-battleye"
+	"github.com/multiplay/go-battleye/event"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logRecorder is an sdklog.Processor recording the emitted log records.
+type logRecorder struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (r *logRecorder) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return true
+}
+
+func (r *logRecorder) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+
+func (r *logRecorder) Shutdown(ctx context.Context) error   { return nil }
+func (r *logRecorder) ForceFlush(ctx context.Context) error { return nil }
+
+// attributes returns the attributes of record.
+func attributes(record sdklog.Record) map[string]string {
+	attrs := make(map[string]string)
+	record.WalkAttributes(func(kv attribute.KeyValue) bool {
+		attrs[string(kv.Key)] = kv.Value.AsString()
+		return true
+	})
+	return attrs
+}
+
+// spanAttributes returns the attributes of span.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestNewTracer(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name   string
+		opts   []Option
+		expErr error
+	}{
+		{name: "Nil option", opts: []Option{nil}, expErr: ErrNilOption},
+		{name: "Nil tracer provider", opts: []Option{TracerProvider(nil)}, expErr: ErrNilProvider},
+		{name: "Nil logger provider", opts: []Option{LoggerProvider(nil)}, expErr: ErrNilProvider},
+		{name: "Default providers"},
+		{name: "Valid", opts: []Option{
+			TracerProvider(sdktrace.NewTracerProvider()),
+			LoggerProvider(sdklog.NewLoggerProvider()),
+			Server("prod1"),
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := NewTracer(tc.opts...)
+			if tc.expErr != nil {
+				assert.Equal(t, tc.expErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.NotNil(t, tr.Hooks())
+			}
+		})
+	}
+}
+
+func TestTracer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	var lr logRecorder
+	lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(&lr))
+
+	tr, err := NewTracer(TracerProvider(tp), LoggerProvider(lp), Server("prod1"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := tr.Hooks()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "panel kick")
+	cmdCtx := h.CommandStart(ctx, "players")
+	assert.Equal(t, parent.SpanContext().TraceID(), trace.SpanContextFromContext(cmdCtx).TraceID())
+	h.CommandDone(battleye.CommandInfo{Context: cmdCtx, Command: "players", Seq: 7, Attempts: 2, Fragments: 3, Duration: time.Second})
+
+	cmdCtx = h.CommandStart(ctx, "kick 1 bye")
+	h.CommandDone(battleye.CommandInfo{Context: cmdCtx, Command: "kick 1 bye", Seq: 8, Attempts: 1, Err: battleye.ErrTimeout})
+
+	// Unknown verbs don't name spans.
+	cmdCtx = h.CommandStart(ctx, "d41d8cd98f00b204e9800998ecf8427e")
+	h.CommandDone(battleye.CommandInfo{Context: cmdCtx, Command: "d41d8cd98f00b204e9800998ecf8427e", Attempts: 1})
+
+	// Keep-alives are not traced and don't end the span in their context.
+	h.CommandDone(battleye.CommandInfo{Context: h.CommandStart(ctx, ""), Attempts: 1, Fragments: 1})
+	parent.End()
+
+	spans := sr.Ended()
+	if assert.Len(t, spans, 4) {
+		players := spans[0]
+		assert.Equal(t, "battleye players", players.Name())
+		assert.Equal(t, trace.SpanKindClient, players.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), players.Parent().SpanID())
+		assert.Equal(t, map[attribute.Key]attribute.Value{
+			"rpc.system": attribute.StringValue("battleye"),
+			CommandKey:   attribute.StringValue("players"),
+			ServerKey:    attribute.StringValue("prod1"),
+			VerbKey:      attribute.StringValue("players"),
+			SeqKey:       attribute.IntValue(7),
+			AttemptsKey:  attribute.IntValue(2),
+			FragmentsKey: attribute.IntValue(3),
+			OutcomeKey:   attribute.StringValue("ok"),
+		}, spanAttributes(players))
+		assert.Equal(t, codes.Unset, players.Status().Code)
+
+		kick := spans[1]
+		assert.Equal(t, "battleye kick", kick.Name())
+		assert.Equal(t, attribute.StringValue("kick 1 bye"), spanAttributes(kick)[CommandKey])
+		assert.Equal(t, attribute.StringValue("timeout"), spanAttributes(kick)[OutcomeKey])
+		assert.Equal(t, codes.Error, kick.Status().Code)
+		if assert.Len(t, kick.Events(), 1) {
+			assert.Equal(t, "exception", kick.Events()[0].Name)
+		}
+
+		other := spans[2]
+		assert.Equal(t, "battleye other", other.Name())
+		assert.Equal(t, attribute.StringValue("d41d8cd98f00b204e9800998ecf8427e"), spanAttributes(other)[VerbKey])
+
+		assert.Equal(t, "panel kick", spans[3].Name())
+	}
+
+	received := time.Now().Add(-time.Second)
+	h.ServerMessage(battleye.Message{Time: received, Event: event.Parse("Player #3 Kerry (127.0.0.1:2304) connected")})
+	h.ServerMessage(battleye.Message{Time: received, Event: event.Parse("(Global) Kerry: hello")})
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if assert.Len(t, lr.records, 2) {
+		assert.Equal(t, "Player #3 Kerry (127.0.0.1:2304) connected", lr.records[0].Body().AsString())
+		assert.Equal(t, log.SeverityInfo, lr.records[0].Severity())
+		assert.True(t, received.Equal(lr.records[0].Timestamp()))
+		assert.Equal(t, map[string]string{
+			"battleye.event.type": "player_connected",
+			"battleye.server":     "prod1",
+		}, attributes(lr.records[0]))
+		assert.Equal(t, "chat", attributes(lr.records[1])["battleye.event.type"])
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ok", Outcome(nil))
+	assert.Equal(t, "timeout", Outcome(battleye.ErrTimeout))
+	assert.Equal(t, "timeout", Outcome(context.DeadlineExceeded))
+	assert.Equal(t, "canceled", Outcome(context.Canceled))
+	assert.Equal(t, "error", Outcome(errors.New("write failed")))
+}